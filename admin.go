@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const adminRefreshTimeout = 5 * time.Minute
+
+type adminRequest struct {
+	Token   string `json:"token"`
+	Command string `json:"command"`
+}
+
+type adminStats struct {
+	Version     string  `json:"version"`
+	Uptime      string  `json:"uptime"`
+	CacheSize   int     `json:"cache_size"`
+	CacheHits   uint64  `json:"cache_hits"`
+	CacheMisses uint64  `json:"cache_misses"`
+	HitRatio    float64 `json:"hit_ratio"`
+	Apps        int     `json:"apps"`
+	Commands    uint64  `json:"commands"`
+	APIRequests int     `json:"api_requests_today"`
+}
+
+type adminReply struct {
+	Command string      `json:"command"`
+	OK      bool        `json:"ok"`
+	Error   string      `json:"error,omitempty"`
+	Flushed int         `json:"flushed,omitempty"`
+	Stats   *adminStats `json:"stats,omitempty"`
+}
+
+type admin struct {
+	token   string
+	kv      Store
+	apiKey  string
+	client  *steamClient
+	started time.Time
+}
+
+func (a *admin) stats() *adminStats {
+	hits, misses := a.client.cache.Stats()
+	used, _ := apiBudget.Used(time.Now())
+
+	return &adminStats{
+		Version:     moduleVersion(),
+		Uptime:      shortDuration(time.Since(a.started)),
+		CacheSize:   a.client.cache.Len(),
+		CacheHits:   hits,
+		CacheMisses: misses,
+		HitRatio:    a.client.cache.HitRatio(),
+		Apps:        apps.Len(),
+		Commands:    atomic.LoadUint64(&commandsServed),
+		APIRequests: used,
+	}
+}
+
+func (a *admin) flush() (int, error) {
+	n, err := diskCache.Flush()
+	return a.client.cache.Flush() + n, err
+}
+
+func (a *admin) refreshApps() error {
+	if apps == nil {
+		return errors.New("app index is disabled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminRefreshTimeout)
+	defer cancel()
+
+	return apps.Refresh(ctx, a.kv, a.apiKey, a.client)
+}
+
+func (a *admin) handle(payload []byte) adminReply {
+	req := adminRequest{}
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return adminReply{Error: "invalid request"}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(a.token)) != 1 {
+		return adminReply{Command: req.Command, Error: "invalid token"}
+	}
+
+	reply := adminReply{Command: req.Command}
+	var err error
+
+	switch req.Command {
+	case "stats":
+		reply.Stats = a.stats()
+	case "flush":
+		reply.Flushed, err = a.flush()
+	case "refresh_apps":
+		err = a.refreshApps()
+	default:
+		return adminReply{Command: req.Command, Error: "unknown command"}
+	}
+
+	if err != nil {
+		reply.Error = err.Error()
+		return reply
+	}
+
+	reply.OK = true
+	return reply
+}
+
+func subscribeAdmin(opts *mqtt.ClientOptions, a *admin, topic string, qos byte) {
+	oldOnConnect := opts.OnConnect
+
+	opts.OnConnect = func(client mqtt.Client) {
+		if oldOnConnect != nil {
+			oldOnConnect(client)
+		}
+
+		client.Subscribe(topic, qos, func(c mqtt.Client, msg mqtt.Message) {
+			go publishAdminReply(c, a, topic, qos, msg.Payload())
+		})
+	}
+}
+
+func publishAdminReply(c mqtt.Client, a *admin, topic string, qos byte, payload []byte) {
+	reply := a.handle(payload)
+	if reply.Error != "" {
+		log.Printf("admin command %q failed: %s\n", reply.Command, reply.Error)
+	}
+
+	b, err := json.Marshal(reply)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	c.Publish(topic+"/reply", qos, false, b)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandle(t *testing.T) {
+	cases := []struct {
+		name     string
+		payload  string
+		expected adminReply
+	}{
+		{
+			name:     "Invalid json",
+			payload:  "{",
+			expected: adminReply{Error: "invalid request"},
+		},
+		{
+			name:     "Wrong token",
+			payload:  `{"token":"nope","command":"stats"}`,
+			expected: adminReply{Command: "stats", Error: "invalid token"},
+		},
+		{
+			name:     "Missing token",
+			payload:  `{"command":"stats"}`,
+			expected: adminReply{Command: "stats", Error: "invalid token"},
+		},
+		{
+			name:     "Unknown command",
+			payload:  `{"token":"secret","command":"nope"}`,
+			expected: adminReply{Command: "nope", Error: "unknown command"},
+		},
+		{
+			name:     "App index disabled",
+			payload:  `{"token":"secret","command":"refresh_apps"}`,
+			expected: adminReply{Command: "refresh_apps", Error: "app index is disabled"},
+		},
+	}
+
+	a := &admin{token: "secret", started: time.Now()}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, a.handle([]byte(tc.payload)))
+		})
+	}
+}
+
+func TestAdminFlush(t *testing.T) {
+	client := &steamClient{cache: newLRUCache(10, time.Minute)}
+
+	var err error
+	diskCache, err = newBoltCache(openTestDB(t), time.Hour)
+	assert.Nil(t, err)
+	defer func() { diskCache = nil }()
+
+	client.cache.Set("a", 1)
+	assert.Nil(t, diskCache.Set("b", 2))
+
+	a := &admin{token: "secret", client: client, started: time.Now()}
+	reply := a.handle([]byte(`{"token":"secret","command":"flush"}`))
+
+	assert.Equal(t, adminReply{Command: "flush", OK: true, Flushed: 2}, reply)
+	assert.Equal(t, 0, client.cache.Len())
+}
+
+func TestAdminStats(t *testing.T) {
+	client := &steamClient{cache: newLRUCache(10, time.Minute)}
+
+	client.cache.Set("a", 1)
+	client.cache.Get("a")
+	client.cache.Get("b")
+
+	apiBudget = &dailyBudget{}
+	defer func() { apiBudget = nil }()
+	apiBudget.take(time.Now())
+
+	served := atomic.LoadUint64(&commandsServed)
+
+	a := &admin{token: "secret", client: client, started: time.Now()}
+	reply := a.handle([]byte(`{"token":"secret","command":"stats"}`))
+
+	assert.True(t, reply.OK)
+	assert.Equal(t, &adminStats{
+		Version:     moduleVersion(),
+		Uptime:      "0s",
+		CacheSize:   1,
+		CacheHits:   1,
+		CacheMisses: 1,
+		HitRatio:    0.5,
+		Commands:    served,
+		APIRequests: 1,
+	}, reply.Stats)
+}
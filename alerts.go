@@ -0,0 +1,61 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+type errorRateAlerter struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	errors    []time.Time
+	lastAlert time.Time
+	send      func(count int, window time.Duration)
+}
+
+var errorAlerts *errorRateAlerter
+
+func newErrorRateAlerter(threshold int, window, cooldown time.Duration, send func(count int, window time.Duration)) *errorRateAlerter {
+	return &errorRateAlerter{
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+		send:      send,
+	}
+}
+
+func (ea *errorRateAlerter) record(now time.Time) (int, bool) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+
+	kept := ea.errors[:0]
+	for _, t := range ea.errors {
+		if now.Sub(t) < ea.window {
+			kept = append(kept, t)
+		}
+	}
+	ea.errors = append(kept, now)
+
+	if len(ea.errors) < ea.threshold {
+		return 0, false
+	}
+
+	if !ea.lastAlert.IsZero() && now.Sub(ea.lastAlert) < ea.cooldown {
+		return 0, false
+	}
+
+	ea.lastAlert = now
+	return len(ea.errors), true
+}
+
+func (ea *errorRateAlerter) Record(err error, now time.Time) {
+	if ea == nil || err == nil {
+		return
+	}
+
+	if count, alert := ea.record(now); alert {
+		ea.send(count, ea.window)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorRateAlerterNil(t *testing.T) {
+	var ea *errorRateAlerter
+	ea.Record(errors.New("boom"), time.Now())
+}
+
+func TestErrorRateAlerter(t *testing.T) {
+	alerts := []int{}
+	ea := newErrorRateAlerter(3, time.Minute, 10*time.Minute, func(count int, window time.Duration) {
+		alerts = append(alerts, count)
+	})
+
+	now := time.Now()
+	boom := errors.New("boom")
+
+	ea.Record(nil, now)
+	ea.Record(boom, now)
+	ea.Record(boom, now.Add(10*time.Second))
+	assert.Empty(t, alerts)
+
+	ea.Record(boom, now.Add(20*time.Second))
+	assert.Equal(t, []int{3}, alerts)
+
+	ea.Record(boom, now.Add(30*time.Second))
+	assert.Equal(t, []int{3}, alerts, "alerts are held back during the cooldown")
+
+	ea.Record(boom, now.Add(15*time.Minute))
+	ea.Record(boom, now.Add(15*time.Minute+time.Second))
+	assert.Equal(t, []int{3}, alerts, "old errors fall out of the window")
+
+	ea.Record(boom, now.Add(15*time.Minute+2*time.Second))
+	assert.Equal(t, []int{3, 3}, alerts)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gowon-irc/go-gowon"
+)
+
+var commandAliases = map[string]string{
+	"recent":      "r",
+	"nowplaying":  "np",
+	"achievement": "a",
+}
+
+func aliasArgs(subcommand, args string) string {
+	if args == "" {
+		return subcommand
+	}
+
+	return fmt.Sprintf("%s %s", subcommand, args)
+}
+
+func genAliasHandler(subcommand string, handler func(m gowon.Message) (string, error)) func(m gowon.Message) (string, error) {
+	return func(m gowon.Message) (string, error) {
+		m.Args = aliasArgs(subcommand, m.Args)
+		return handler(m)
+	}
+}
+
+func addAliases(mr *gowon.MessageRouter, handler func(m gowon.Message) (string, error)) {
+	for alias, subcommand := range commandAliases {
+		mr.AddCommand(alias, genAliasHandler(subcommand, handler))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasArgs(t *testing.T) {
+	cases := []struct {
+		name       string
+		subcommand string
+		args       string
+		expected   string
+	}{
+		{
+			name:       "No args",
+			subcommand: "r",
+			args:       "",
+			expected:   "r",
+		},
+		{
+			name:       "User",
+			subcommand: "a",
+			args:       "someone",
+			expected:   "a someone",
+		},
+		{
+			name:       "User and modifiers",
+			subcommand: "r",
+			args:       "someone 3 --verbose",
+			expected:   "r someone 3 --verbose",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, aliasArgs(tc.subcommand, tc.args))
+		})
+	}
+}
+
+func TestAddAliases(t *testing.T) {
+	mr := gowon.NewMessageRouter()
+	addAliases(mr, func(m gowon.Message) (string, error) {
+		return m.Args, nil
+	})
+
+	cases := []struct {
+		command  string
+		args     string
+		expected string
+	}{
+		{command: "recent", args: "someone", expected: "r someone"},
+		{command: "nowplaying", args: "", expected: "np"},
+		{command: "achievement", args: "someone", expected: "a someone"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.command, func(t *testing.T) {
+			out, err := mr.Route(gowon.Message{Command: tc.command, Args: tc.args})
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	apiPrefix          = "/v1/"
+	apiShutdownTimeout = 5 * time.Second
+)
+
+type apiFetchFunc func(ctx context.Context, apiKey, user string, client *steamClient, s settings) (interface{}, error)
+
+var apiCommands = map[string]apiFetchFunc{
+	"recent": func(ctx context.Context, apiKey, user string, client *steamClient, s settings) (interface{}, error) {
+		return fetchRecentGames(ctx, apiKey, user, client, s)
+	},
+	"achievement": func(ctx context.Context, apiKey, user string, client *steamClient, s settings) (interface{}, error) {
+		return fetchLastAchievement(ctx, apiKey, user, client, s)
+	},
+}
+
+var apiErrorStatuses = map[error]int{
+	ErrProfileNotFound:       http.StatusNotFound,
+	ErrInvalidSteamID:        http.StatusNotFound,
+	ErrProfilePrivate:        http.StatusForbidden,
+	ErrRateLimited:           http.StatusTooManyRequests,
+	circuitOpenErr:           http.StatusServiceUnavailable,
+	dailyBudgetErr:           http.StatusServiceUnavailable,
+	context.DeadlineExceeded: http.StatusGatewayTimeout,
+}
+
+type apiResponse struct {
+	Command string      `json:"command,omitempty"`
+	User    string      `json:"user,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+	Reply   string      `json:"reply,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, res apiResponse) {
+	writeJSON(w, status, res)
+}
+
+func apiErrorStatus(err error) int {
+	for e, status := range apiErrorStatuses {
+		if errors.Is(err, e) {
+			return status
+		}
+	}
+
+	return http.StatusBadGateway
+}
+
+func apiSettings(defaults settings, q map[string][]string) settings {
+	s := defaults
+
+	if c := firstValue(q["count"]); c != "" {
+		s = s.withModifiers([]string{"--n=" + c})
+	}
+
+	return s
+}
+
+func firstValue(vs []string) string {
+	if len(vs) == 0 {
+		return ""
+	}
+
+	return vs[0]
+}
+
+func newAPIHandler(apiKey string, client *steamClient, defaults settings, timeout time.Duration, retryBudget int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIResponse(w, http.StatusMethodNotAllowed, apiResponse{Error: "method not allowed"})
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, apiPrefix), "/")
+		if !strings.HasPrefix(r.URL.Path, apiPrefix) || len(parts) != 2 || parts[1] == "" {
+			writeAPIResponse(w, http.StatusNotFound, apiResponse{Error: "not found"})
+			return
+		}
+
+		command, user := parts[0], parts[1]
+
+		fetch, ok := apiCommands[command]
+		if !ok {
+			writeAPIResponse(w, http.StatusNotFound, apiResponse{Command: command, Error: "unknown command"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		ctx = withRetryBudget(ctx, retryBudget)
+
+		atomic.AddUint64(&commandsServed, 1)
+
+		res, err := fetch(ctx, apiKey, user, client, apiSettings(defaults, r.URL.Query()))
+		if err != nil {
+			status := apiErrorStatus(err)
+			if status == http.StatusBadGateway {
+				log.Printf("api %s failed: %s\n", command, sanitiseError(err))
+			}
+			writeAPIResponse(w, status, apiResponse{Command: command, User: user, Error: sanitiseError(err)})
+			return
+		}
+
+		writeAPIResponse(w, http.StatusOK, apiResponse{Command: command, User: user, Result: res})
+	})
+}
+
+func serveAPI(addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("http api listening on %s\n", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	return srv
+}
+
+func shutdownAPI(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println(err)
+	}
+}
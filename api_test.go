@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		endpoint string
+		fault    mocksteam.Fault
+		status   int
+		body     string
+	}{
+		{
+			name:   "Recent",
+			method: http.MethodGet,
+			path:   "/v1/recent/gaben",
+			status: http.StatusOK,
+			body:   `{"command":"recent","user":"gaben","result":{"user":"gaben","link":"https://steamcommunity.com/profiles/76561197960287930/games/?tab=recent","games":[{"appid":620,"name":"Portal 2","hours":12.566666666666666},{"appid":400,"name":"Portal","hours":2}]}}`,
+		},
+		{
+			name:   "Recent with count",
+			method: http.MethodGet,
+			path:   "/v1/recent/gaben?count=1",
+			status: http.StatusOK,
+			body:   `{"command":"recent","user":"gaben","result":{"user":"gaben","link":"https://steamcommunity.com/profiles/76561197960287930/games/?tab=recent","games":[{"appid":620,"name":"Portal 2","hours":12.566666666666666}]}}`,
+		},
+		{
+			name:   "Recent with invalid count",
+			method: http.MethodGet,
+			path:   "/v1/recent/gaben?count=x",
+			status: http.StatusOK,
+			body:   `{"command":"recent","user":"gaben","result":{"user":"gaben","link":"https://steamcommunity.com/profiles/76561197960287930/games/?tab=recent","games":[{"appid":620,"name":"Portal 2","hours":12.566666666666666},{"appid":400,"name":"Portal","hours":2}]}}`,
+		},
+		{
+			name:   "Achievement",
+			method: http.MethodGet,
+			path:   "/v1/achievement/gaben",
+			status: http.StatusOK,
+			body:   `{"command":"achievement","user":"gaben","result":{"user":"gaben","found":true,"game":"Portal 2","appid":620,"name":"Wake Up Call","description":"Survive the manual override of Aperture Science's relaxation center","rarity":87.5,"achieved":2,"total":3,"unlock_time":1638316294,"link":"{store}/app/620"}}`,
+		},
+		{
+			name:   "Unknown user",
+			method: http.MethodGet,
+			path:   "/v1/recent/nobody",
+			status: http.StatusNotFound,
+			body:   `{"command":"recent","user":"nobody","error":"ISteamUser/ResolveVanityURL/v1 for nobody: id not found"}`,
+		},
+		{
+			name:     "Private profile",
+			method:   http.MethodGet,
+			path:     "/v1/achievement/gaben",
+			endpoint: "achievements",
+			fault:    mocksteam.Private,
+			status:   http.StatusForbidden,
+			body:     `{"command":"achievement","user":"gaben","error":"ISteamUserStats/GetPlayerAchievements/v0001 for 76561197960287930: profile is not public"}`,
+		},
+		{
+			name:     "Rate limited",
+			method:   http.MethodGet,
+			path:     "/v1/recent/gaben",
+			endpoint: "recent",
+			fault:    mocksteam.RateLimited,
+			status:   http.StatusTooManyRequests,
+			body:     `{"command":"recent","user":"gaben","error":"IPlayerService/GetRecentlyPlayedGames/v1 for 76561197960287930: rate limited by Steam"}`,
+		},
+		{
+			name:     "Steam error",
+			method:   http.MethodGet,
+			path:     "/v1/recent/gaben",
+			endpoint: "recent",
+			fault:    mocksteam.ServerError,
+			status:   http.StatusBadGateway,
+			body:     `{"command":"recent","user":"gaben","error":"IPlayerService/GetRecentlyPlayedGames/v1 for 76561197960287930: Steam API error"}`,
+		},
+		{
+			name:   "Unknown command",
+			method: http.MethodGet,
+			path:   "/v1/set/gaben",
+			status: http.StatusNotFound,
+			body:   `{"command":"set","error":"unknown command"}`,
+		},
+		{
+			name:   "Missing user",
+			method: http.MethodGet,
+			path:   "/v1/recent/",
+			status: http.StatusNotFound,
+			body:   `{"error":"not found"}`,
+		},
+		{
+			name:   "Wrong prefix",
+			method: http.MethodGet,
+			path:   "/recent/gaben",
+			status: http.StatusNotFound,
+			body:   `{"error":"not found"}`,
+		},
+		{
+			name:   "Wrong method",
+			method: http.MethodPost,
+			path:   "/v1/recent/gaben",
+			status: http.StatusMethodNotAllowed,
+			body:   `{"error":"method not allowed"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject(tc.endpoint, tc.fault)
+			h := newAPIHandler("key", client, testSettings, time.Second, 0)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, strings.ReplaceAll(tc.body, "{store}", ms.URL), w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	appListPath     = "/IStoreService/GetAppList/v1/?key=%s&include_games=true&max_results=%d&last_appid=%d"
+	appListPageSize = 10000
+	appIndexTimeout = 5 * time.Minute
+)
+
+var appsBucket = []byte("apps")
+
+var romanNumerals = map[string]string{
+	"ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6", "vii": "7", "viii": "8", "ix": "9", "x": "10",
+}
+
+type appListRes struct {
+	Response struct {
+		Apps []struct {
+			AppId int
+			Name  string
+		}
+		HaveMoreResults bool `json:"have_more_results"`
+		LastAppId       int  `json:"last_appid"`
+	}
+}
+
+type indexedApp struct {
+	id   int
+	name string
+	norm string
+}
+
+type appIndex struct {
+	mu   sync.RWMutex
+	apps []indexedApp
+}
+
+var apps *appIndex
+
+func normaliseName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+
+	fields := strings.Fields(s)
+	for n, f := range fields {
+		if d, ok := romanNumerals[f]; ok {
+			fields[n] = d
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func newAppIndex(names map[int]string) *appIndex {
+	ai := &appIndex{}
+	ai.set(names)
+	return ai
+}
+
+func (ai *appIndex) set(names map[int]string) {
+	list := make([]indexedApp, 0, len(names))
+	for id, name := range names {
+		if norm := normaliseName(name); norm != "" {
+			list = append(list, indexedApp{id: id, name: name, norm: norm})
+		}
+	}
+
+	ai.mu.Lock()
+	ai.apps = list
+	ai.mu.Unlock()
+}
+
+func (ai *appIndex) Len() int {
+	if ai == nil {
+		return 0
+	}
+
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	return len(ai.apps)
+}
+
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j] + 1
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(br)]
+}
+
+func containsWords(norm string, words []string) bool {
+	padded := " " + norm + " "
+
+	for _, w := range words {
+		if !strings.Contains(padded, " "+w+" ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchScore(query string, words []string, norm string) (int, bool) {
+	switch {
+	case norm == query:
+		return 0, true
+	case strings.HasPrefix(norm, query+" "):
+		return 1, true
+	case containsWords(norm, words):
+		return 2, true
+	}
+
+	diff := len(norm) - len(query)
+	if diff < -2 || diff > 2 {
+		return 0, false
+	}
+
+	maxDistance := len(query) / 5
+	if maxDistance > 2 {
+		maxDistance = 2
+	}
+
+	if d := levenshtein(norm, query); d > 0 && d <= maxDistance {
+		return 2 + d, true
+	}
+
+	return 0, false
+}
+
+func (ai *appIndex) Name(appId int) (string, bool) {
+	if ai == nil {
+		return "", false
+	}
+
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	for _, a := range ai.apps {
+		if a.id == appId {
+			return a.name, true
+		}
+	}
+
+	return "", false
+}
+
+func (ai *appIndex) Resolve(query string) (int, string, bool) {
+	if ai == nil {
+		return 0, "", false
+	}
+
+	q := normaliseName(query)
+	if q == "" {
+		return 0, "", false
+	}
+	words := strings.Fields(q)
+
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	best, bestScore := indexedApp{}, -1
+	for _, a := range ai.apps {
+		score, ok := matchScore(q, words, a.norm)
+		if !ok {
+			continue
+		}
+
+		better := bestScore < 0 || score < bestScore ||
+			(score == bestScore && (len(a.norm) < len(best.norm) || (len(a.norm) == len(best.norm) && a.id < best.id)))
+		if better {
+			best, bestScore = a, score
+		}
+	}
+
+	return best.id, best.name, bestScore >= 0
+}
+
+func resolveGame(query string) (int, string, bool) {
+	if id, err := strconv.Atoi(strings.TrimSpace(query)); err == nil && id > 0 {
+		return id, "", true
+	}
+
+	return apps.Resolve(query)
+}
+
+func getAppList(ctx context.Context, apiKey string, client *steamClient) (map[int]string, error) {
+	names := make(map[int]string)
+	last := 0
+
+	for {
+		j, err := getJSON[appListRes](ctx, client.apiUrl(appListPath, apiKey, appListPageSize, last), "", client)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range j.Response.Apps {
+			names[a.AppId] = a.Name
+		}
+
+		if !j.Response.HaveMoreResults || j.Response.LastAppId <= last {
+			return names, nil
+		}
+
+		last = j.Response.LastAppId
+	}
+}
+
+func storeAppNames(kv Store, names map[int]string) error {
+	return kv.Update(appsBucket, func(b Bucket) error {
+		if _, err := b.Clear(); err != nil {
+			return err
+		}
+
+		for id, name := range names {
+			if err := b.Put([]byte(strconv.Itoa(id)), []byte(name)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func loadAppNames(kv Store) (map[int]string, error) {
+	names := make(map[int]string)
+
+	err := kv.View(appsBucket, func(b Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			id, err := strconv.Atoi(string(k))
+			if err != nil {
+				return err
+			}
+
+			names[id] = string(v)
+			return nil
+		})
+	})
+
+	return names, err
+}
+
+func refreshAppIndex(kv Store, apiKey string, client *steamClient, interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), appIndexTimeout)
+		err := apps.Refresh(ctx, kv, apiKey, client)
+		cancel()
+
+		if err != nil {
+			log.Printf("app index refresh failed: %s\n", err)
+		} else {
+			log.Printf("app index refreshed with %d apps\n", apps.Len())
+		}
+
+		if interval <= 0 {
+			return
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func (ai *appIndex) Refresh(ctx context.Context, kv Store, apiKey string, client *steamClient) error {
+	names, err := getAppList(ctx, apiKey, client)
+	if err != nil {
+		return err
+	}
+
+	if err := storeAppNames(kv, names); err != nil {
+		return err
+	}
+
+	ai.set(names)
+
+	return nil
+}
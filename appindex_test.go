@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormaliseName(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "Punctuation",
+			in:   "SUPERHOT: MIND CONTROL DELETE",
+			out:  "superhot mind control delete",
+		},
+		{
+			name: "Roman numerals",
+			in:   "Hades II",
+			out:  "hades 2",
+		},
+		{
+			name: "Symbols",
+			in:   "Portal™ 2",
+			out:  "portal 2",
+		},
+		{
+			name: "Empty",
+			in:   "™",
+			out:  "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, normaliseName(tc.in))
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("portal", "portal"))
+	assert.Equal(t, 1, levenshtein("portal", "portl"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}
+
+func TestAppIndexResolve(t *testing.T) {
+	ai := newAppIndex(map[int]string{
+		1145360: "Hades",
+		1145350: "Hades II",
+		2380410: "Hades II Soundtrack",
+		620:     "Portal 2",
+		400:     "Portal",
+		730:     "Counter-Strike 2",
+		1091500: "Cyberpunk 2077",
+	})
+
+	cases := []struct {
+		name  string
+		query string
+		id    int
+		ok    bool
+	}{
+		{
+			name:  "Exact",
+			query: "hades",
+			id:    1145360,
+			ok:    true,
+		},
+		{
+			name:  "Numeral",
+			query: "hades 2",
+			id:    1145350,
+			ok:    true,
+		},
+		{
+			name:  "Prefix",
+			query: "cyberpunk",
+			id:    1091500,
+			ok:    true,
+		},
+		{
+			name:  "Words",
+			query: "strike counter",
+			id:    730,
+			ok:    true,
+		},
+		{
+			name:  "Typo",
+			query: "portl 2",
+			id:    620,
+			ok:    true,
+		},
+		{
+			name:  "No match",
+			query: "minecraft",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, _, ok := ai.Resolve(tc.query)
+
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.id, id)
+		})
+	}
+}
+
+func TestAppIndexName(t *testing.T) {
+	ai := newAppIndex(map[int]string{620: "Portal 2"})
+
+	name, ok := ai.Name(620)
+	assert.True(t, ok)
+	assert.Equal(t, "Portal 2", name)
+
+	_, ok = ai.Name(400)
+	assert.False(t, ok)
+
+	_, ok = (*appIndex)(nil).Name(620)
+	assert.False(t, ok)
+}
+
+func TestResolveGame(t *testing.T) {
+	apps = newAppIndex(map[int]string{620: "Portal 2"})
+	defer func() { apps = nil }()
+
+	id, name, ok := resolveGame("portal 2")
+	assert.True(t, ok)
+	assert.Equal(t, 620, id)
+	assert.Equal(t, "Portal 2", name)
+
+	id, _, ok = resolveGame("400")
+	assert.True(t, ok)
+	assert.Equal(t, 400, id)
+}
+
+func TestAppIndexRefresh(t *testing.T) {
+	kv := openTestDB(t)
+
+	client := NewConditionalTestClient(map[string]string{
+		testClient.apiUrl(appListPath, "key", appListPageSize, 0):   `{"response":{"apps":[{"appid":400,"name":"Portal"}],"have_more_results":true,"last_appid":400}}`,
+		testClient.apiUrl(appListPath, "key", appListPageSize, 400): `{"response":{"apps":[{"appid":620,"name":"Portal 2"}]}}`,
+	})
+
+	ai := newAppIndex(nil)
+	err := ai.Refresh(context.Background(), kv, "key", client)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, ai.Len())
+
+	names, err := loadAppNames(kv)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]string{400: "Portal", 620: "Portal 2"}, names)
+}
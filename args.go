@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
+
+func tokenizeArgs(msg string) []string {
+	tokens := []string{}
+
+	var b strings.Builder
+	quoted, started := false, false
+
+	for _, r := range msg {
+		switch {
+		case isQuote(r):
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				tokens = append(tokens, b.String())
+				b.Reset()
+				started = false
+			}
+		default:
+			b.WriteRune(r)
+			started = true
+		}
+	}
+
+	if started {
+		tokens = append(tokens, b.String())
+	}
+
+	return tokens
+}
+
+func parseArgs(msg string) (command string, args, modifiers []string) {
+	fields, modifiers := splitModifiers(tokenizeArgs(msg))
+	args = []string{}
+
+	takesCount := false
+
+	for n, f := range fields {
+		if n == 0 {
+			command = f
+			sc, ok := findSubcommand(f)
+			takesCount = ok && sc.takesCount
+		} else if c, err := strconv.Atoi(f); err == nil && c <= maxCountArg && takesCount {
+			modifiers = append(modifiers, fmt.Sprintf("--n=%s", f))
+		} else {
+			args = append(args, f)
+		}
+	}
+
+	return command, args, modifiers
+}
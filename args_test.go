@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizeArgs(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  string
+		out  []string
+	}{
+		{
+			name: "Empty",
+			msg:  "",
+			out:  []string{},
+		},
+		{
+			name: "Plain words",
+			msg:  "r  bob",
+			out:  []string{"r", "bob"},
+		},
+		{
+			name: "Quoted argument",
+			msg:  `ach "The Witcher 3" bob`,
+			out:  []string{"ach", "The Witcher 3", "bob"},
+		},
+		{
+			name: "Smart quotes",
+			msg:  "ach “Portal 2”",
+			out:  []string{"ach", "Portal 2"},
+		},
+		{
+			name: "Quote inside a word",
+			msg:  `a"b c"d`,
+			out:  []string{"ab cd"},
+		},
+		{
+			name: "Empty quotes",
+			msg:  `set ""`,
+			out:  []string{"set", ""},
+		},
+		{
+			name: "Unterminated quote",
+			msg:  `ach "Half Life`,
+			out:  []string{"ach", "Half Life"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, tokenizeArgs(tc.msg))
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		name      string
+		msg       string
+		command   string
+		args      []string
+		modifiers []string
+	}{
+		{
+			name:      "Empty",
+			msg:       "",
+			args:      []string{},
+			modifiers: []string{},
+		},
+		{
+			name:      "User and count",
+			msg:       "r bob 5",
+			command:   "r",
+			args:      []string{"bob"},
+			modifiers: []string{"--n=5"},
+		},
+		{
+			name:      "Quoted game keeps its number",
+			msg:       `ach "The Witcher 3" bob -v`,
+			command:   "ach",
+			args:      []string{"The Witcher 3", "bob"},
+			modifiers: []string{"-v"},
+		},
+		{
+			name:      "Flag-style modifiers",
+			msg:       "r --n 10 --by playtime bob",
+			command:   "r",
+			args:      []string{"bob"},
+			modifiers: []string{"--n=10", "--by=playtime"},
+		},
+		{
+			name:      "Trailing multi-word name",
+			msg:       "ach bob Hollow Knight",
+			command:   "ach",
+			args:      []string{"bob", "Hollow", "Knight"},
+			modifiers: []string{},
+		},
+		{
+			name:      "Audit count",
+			msg:       "audit 20",
+			command:   "audit",
+			args:      []string{},
+			modifiers: []string{"--n=20"},
+		},
+		{
+			name:      "Game id is an argument",
+			msg:       "spy 730",
+			command:   "spy",
+			args:      []string{"730"},
+			modifiers: []string{},
+		},
+		{
+			name:      "Unquoted game name with a number",
+			msg:       "easy Hades 2",
+			command:   "easy",
+			args:      []string{"Hades", "2"},
+			modifiers: []string{},
+		},
+		{
+			name:      "Game id before a nick",
+			msg:       "hours 440 bob",
+			command:   "hours",
+			args:      []string{"440", "bob"},
+			modifiers: []string{},
+		},
+		{
+			name:      "Package id",
+			msg:       "bundle 232 -v",
+			command:   "bundle",
+			args:      []string{"232"},
+			modifiers: []string{"-v"},
+		},
+		{
+			name:      "Large numbers are arguments",
+			msg:       "set 76561197960287930",
+			command:   "set",
+			args:      []string{"76561197960287930"},
+			modifiers: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, args, modifiers := parseArgs(tc.msg)
+
+			assert.Equal(t, tc.command, command)
+			assert.Equal(t, tc.args, args)
+			assert.Equal(t, tc.modifiers, modifiers)
+		})
+	}
+}
+
+func TestParseArgsGameCommands(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  string
+		out  string
+	}{
+		{
+			name: "Spy by id",
+			msg:  "spy 620",
+			out:  "{green}Portal 2{clear} - 10,000,000 .. 20,000,000 owners - 18.3h average playtime (2.1h in the last 2 weeks) - peak of 4517 players yesterday",
+		},
+		{
+			name: "Package by id",
+			msg:  "bundle 469",
+			out:  "{green}The Orange Box{clear} - Half-Life 2, Half-Life 2: Episode One, Half-Life 2: Episode Two, Portal, Team Fortress 2 and 1 more - 19.99 GBP (45% less than 35.95 GBP separately)",
+		},
+		{
+			name: "Unquoted name ending in a number",
+			msg:  "spy portal 2",
+			out:  "{green}Portal 2{clear} - 10,000,000 .. 20,000,000 owners - 18.3h average playtime (2.1h in the last 2 weeks) - peak of 4517 players yesterday",
+		},
+	}
+
+	old := apps
+	apps = newAppIndex(map[int]string{400: "Portal", 620: "Portal 2"})
+	defer func() { apps = old }()
+
+	_, client := useMockSteam(t)
+	kv := openTestDB(t)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, args, modifiers := parseArgs(tc.msg)
+			s := testSettings.withModifiers(modifiers)
+
+			out, err := routeCommand(context.Background(), command, args, "key", kv, client, s, gowon.Message{Nick: "nick"})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const defaultAuditEntries = 10
+
+var auditBucket = []byte("audit")
+
+type auditEntry struct {
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Channel string    `json:"channel,omitempty"`
+	Action  string    `json:"action"`
+	Nick    string    `json:"nick"`
+	Old     string    `json:"old,omitempty"`
+	New     string    `json:"new,omitempty"`
+}
+
+func recordAudit(kv Store, e auditEntry) error {
+	v, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return kv.Update(auditBucket, func(b Bucket) error {
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		k := make([]byte, 8)
+		binary.BigEndian.PutUint64(k, seq)
+
+		return b.Put(k, v)
+	})
+}
+
+func recentAudit(kv Store, n int) ([]auditEntry, error) {
+	entries := []auditEntry{}
+
+	err := kv.View(auditBucket, func(b Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			e := auditEntry{}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+
+			entries = append(entries, e)
+			if len(entries) > n {
+				entries = entries[1:]
+			}
+
+			return nil
+		})
+	})
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, err
+}
+
+func (s settings) formatAuditEntry(e auditEntry) string {
+	out := s.msg("audit_entry", s.formatTime(e.Time), e.Actor, e.Action, e.Nick, e.New)
+	if e.Old != "" {
+		out = s.msg("audit_entry_changed", s.formatTime(e.Time), e.Actor, e.Action, e.Nick, e.New, e.Old)
+	}
+
+	if e.Channel != "" {
+		out = fmt.Sprintf("%s %s", out, s.msg("audit_channel", e.Channel))
+	}
+
+	return out
+}
+
+func auditHandler(kv Store, s settings) (string, error) {
+	n := s.listLength
+	if n <= 0 {
+		n = defaultAuditEntries
+	}
+
+	entries, err := recentAudit(kv, n)
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return s.msg("audit_empty"), nil
+	}
+
+	lines := []string{}
+	for _, e := range entries {
+		lines = append(lines, s.formatAuditEntry(e))
+	}
+
+	return s.formatter.Lines(lines), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentAudit(t *testing.T) {
+	kv := openTestDB(t)
+
+	entries, err := recentAudit(kv, 5)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	for _, user := range []string{"bob", "alice", "carol"} {
+		assert.Nil(t, recordAudit(kv, auditEntry{Actor: "nick1", Action: "set", Nick: "nick1", New: user}))
+	}
+
+	entries, err = recentAudit(kv, 2)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "carol", entries[0].New)
+	assert.Equal(t, "alice", entries[1].New)
+}
+
+func TestAuditHandler(t *testing.T) {
+	when := time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		entries  []auditEntry
+		expected string
+	}{
+		{
+			name:     "Empty",
+			expected: "no changes recorded",
+		},
+		{
+			name: "Entries",
+			entries: []auditEntry{
+				{Time: when, Actor: "nick1", Action: "set", Nick: "nick1", New: "bob"},
+				{Time: when.Add(time.Hour), Actor: "nick1", Action: "set", Nick: "nick1", Old: "bob", New: "alice"},
+			},
+			expected: "2021-03-04 06:06 UTC: nick1 set nick1 to alice (was bob) | 2021-03-04 05:06 UTC: nick1 set nick1 to bob",
+		},
+		{
+			name: "Channel",
+			entries: []auditEntry{
+				{Time: when, Actor: "nick1", Channel: "#chan", Action: "set", Nick: "nick1", New: "bob"},
+			},
+			expected: "2021-03-04 05:06 UTC: nick1 set nick1 to bob in #chan",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kv := openTestDB(t)
+			for _, e := range tc.entries {
+				assert.Nil(t, recordAudit(kv, e))
+			}
+
+			out, err := auditHandler(kv, testSettings)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
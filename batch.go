@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxSummaryIds       = 100
+	summaryBatchWindow  = 20 * time.Millisecond
+	summaryBatchTimeout = 10 * time.Second
+)
+
+type summaryResult struct {
+	ps  playerSummary
+	err error
+}
+
+type summaryBatch struct {
+	apiKey  string
+	client  *steamClient
+	ids     []string
+	waiters map[string][]chan summaryResult
+}
+
+type summaryBatcher struct {
+	mu      sync.Mutex
+	window  time.Duration
+	timeout time.Duration
+	batches map[string]*summaryBatch
+}
+
+var playerSummaries = newSummaryBatcher(summaryBatchWindow, summaryBatchTimeout)
+
+func newSummaryBatcher(window, timeout time.Duration) *summaryBatcher {
+	return &summaryBatcher{
+		window:  window,
+		timeout: timeout,
+		batches: make(map[string]*summaryBatch),
+	}
+}
+
+func (sb *summaryBatcher) Get(ctx context.Context, apiKey, id string, client *steamClient) (playerSummary, error) {
+	ch := make(chan summaryResult, 1)
+
+	sb.mu.Lock()
+
+	b, ok := sb.batches[apiKey]
+	if !ok {
+		b = &summaryBatch{apiKey: apiKey, client: client, waiters: make(map[string][]chan summaryResult)}
+		sb.batches[apiKey] = b
+		time.AfterFunc(sb.window, func() { sb.flush(b) })
+	}
+
+	if _, ok := b.waiters[id]; !ok {
+		b.ids = append(b.ids, id)
+	}
+	b.waiters[id] = append(b.waiters[id], ch)
+
+	full := len(b.ids) >= maxSummaryIds
+	if full {
+		delete(sb.batches, apiKey)
+	}
+
+	sb.mu.Unlock()
+
+	if full {
+		go sb.run(b)
+	}
+
+	select {
+	case r := <-ch:
+		return r.ps, r.err
+	case <-ctx.Done():
+		return playerSummary{}, ctx.Err()
+	}
+}
+
+func (sb *summaryBatcher) flush(b *summaryBatch) {
+	sb.mu.Lock()
+	if sb.batches[b.apiKey] != b {
+		sb.mu.Unlock()
+		return
+	}
+	delete(sb.batches, b.apiKey)
+	sb.mu.Unlock()
+
+	sb.run(b)
+}
+
+func (sb *summaryBatcher) run(b *summaryBatch) {
+	ctx, cancel := context.WithTimeout(context.Background(), sb.timeout)
+	defer cancel()
+
+	res, err := getPlayerSummaries(ctx, b.apiKey, strings.Join(b.ids, ","), b.client)
+
+	found := make(map[string]playerSummary)
+	if err == nil {
+		for _, ps := range res.Response.Players {
+			found[ps.SteamId] = ps
+		}
+	}
+
+	for id, chs := range b.waiters {
+		r := summaryResult{err: err}
+
+		if err == nil {
+			ps, ok := found[id]
+			if !ok {
+				r.err = ErrProfileNotFound
+			}
+			r.ps = ps
+		}
+
+		for _, ch := range chs {
+			ch <- r
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryBatcher(t *testing.T) {
+	var mu sync.Mutex
+	requested := [][]string{}
+
+	f := func(req *http.Request) *http.Response {
+		ids := strings.Split(req.URL.Query().Get("steamids"), ",")
+
+		mu.Lock()
+		requested = append(requested, ids)
+		mu.Unlock()
+
+		players := []string{}
+		for _, id := range ids {
+			if id != "404" {
+				players = append(players, fmt.Sprintf(`{"steamid":"%s","personaname":"p%s"}`, id, id))
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"response":{"players":[%s]}}`, strings.Join(players, ",")))),
+			Header:     make(http.Header),
+		}
+	}
+	client := newTestSteamClient(&http.Client{Transport: RoundTripFunc(f)})
+
+	sb := newSummaryBatcher(20*time.Millisecond, time.Second)
+
+	ids := []string{"1", "2", "3", "2", "404"}
+	names := make([]string, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for n, id := range ids {
+		wg.Add(1)
+		go func(n int, id string) {
+			defer wg.Done()
+
+			ps, err := sb.Get(context.Background(), "key", id, client)
+			names[n] = ps.PersonaName
+			errs[n] = err
+		}(n, id)
+	}
+	wg.Wait()
+
+	assert.Len(t, requested, 1)
+	sort.Strings(requested[0])
+	assert.Equal(t, []string{"1", "2", "3", "404"}, requested[0])
+
+	assert.Equal(t, []string{"p1", "p2", "p3", "p2", ""}, names)
+	assert.Equal(t, []error{nil, nil, nil, nil, ErrProfileNotFound}, errs)
+}
+
+func TestSummaryBatcherFull(t *testing.T) {
+	calls := 0
+	client := NewTestClient(200, `{"response":{"players":[]}}`)
+	client.httpClient.Transport = countingTransport(client.httpClient.Transport, &calls)
+
+	sb := newSummaryBatcher(time.Hour, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < maxSummaryIds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sb.Get(context.Background(), "key", fmt.Sprint(i), client)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestSummaryBatcherCancelled(t *testing.T) {
+	sb := newSummaryBatcher(time.Hour, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := sb.Get(ctx, "key", "1", NewTestClient(200, ""))
+
+	assert.Equal(t, context.Canceled, err)
+}
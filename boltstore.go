@@ -0,0 +1,100 @@
+package main
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+type boltBucket struct {
+	b *bolt.Bucket
+}
+
+func (bb boltBucket) Get(key []byte) []byte {
+	v := bb.b.Get(key)
+	if v == nil {
+		return nil
+	}
+
+	return append([]byte{}, v...)
+}
+
+func (bb boltBucket) Put(key, value []byte) error {
+	return bb.b.Put(key, value)
+}
+
+func (bb boltBucket) Delete(key []byte) error {
+	return bb.b.Delete(key)
+}
+
+func (bb boltBucket) ForEach(fn func(k, v []byte) error) error {
+	return bb.b.ForEach(fn)
+}
+
+func (bb boltBucket) NextSequence() (uint64, error) {
+	return bb.b.NextSequence()
+}
+
+func (bb boltBucket) Clear() (int, error) {
+	keys := [][]byte{}
+	err := bb.b.ForEach(func(k, v []byte) error {
+		keys = append(keys, append([]byte{}, k...))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		if err := bb.b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+func openBoltStore(path string) (Store, error) {
+	db, err := bolt.Open(path, 0666, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func (bs *boltStore) View(bucket []byte, fn func(b Bucket) error) error {
+	return bs.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+
+		return fn(boltBucket{b: b})
+	})
+}
+
+func (bs *boltStore) Update(bucket []byte, fn func(b Bucket) error) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+
+		return fn(boltBucket{b: b})
+	})
+}
+
+func (bs *boltStore) Ping() error {
+	return bs.db.View(func(tx *bolt.Tx) error { return nil })
+}
+
+func (bs *boltStore) Close() error {
+	return bs.db.Close()
+}
+
+func init() {
+	registerStoreDriver("bolt", openBoltStore)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var circuitOpenErr = errors.New("steam api circuit breaker is open")
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	trial     bool
+}
+
+func (cb *circuitBreaker) allow(now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+
+	if cb.trial || now.Sub(cb.openedAt) < cb.cooldown {
+		return false
+	}
+
+	cb.trial = true
+	return true
+}
+
+func (cb *circuitBreaker) record(now time.Time, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trial = false
+
+	if success {
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.openedAt = now
+	}
+}
+
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trial = false
+}
+
+type breakerTransport struct {
+	next    http.RoundTripper
+	hosts   map[string]bool
+	breaker *circuitBreaker
+}
+
+func (bt *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !bt.hosts[req.URL.Host] {
+		return bt.next.RoundTrip(req)
+	}
+
+	if !bt.breaker.allow(wallClock.Now()) {
+		return nil, circuitOpenErr
+	}
+
+	res, err := bt.next.RoundTrip(req)
+	if errors.Is(req.Context().Err(), context.Canceled) {
+		bt.breaker.release()
+		return res, err
+	}
+
+	bt.breaker.record(wallClock.Now(), !isTransient(res, err) && err == nil)
+
+	return res, err
+}
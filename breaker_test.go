@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cb := &circuitBreaker{threshold: 2, cooldown: time.Minute}
+
+	assert.True(t, cb.allow(now))
+	cb.record(now, false)
+	assert.True(t, cb.allow(now))
+	cb.record(now, false)
+
+	assert.False(t, cb.allow(now), "open after threshold failures")
+	assert.False(t, cb.allow(now.Add(30*time.Second)), "open during cooldown")
+
+	later := now.Add(2 * time.Minute)
+	assert.True(t, cb.allow(later), "trial after cooldown")
+	assert.False(t, cb.allow(later), "one trial at a time")
+
+	cb.record(later, false)
+	assert.False(t, cb.allow(later), "failed trial reopens")
+
+	evenLater := later.Add(2 * time.Minute)
+	assert.True(t, cb.allow(evenLater))
+	cb.record(evenLater, true)
+	assert.True(t, cb.allow(evenLater), "successful trial closes")
+	assert.True(t, cb.allow(evenLater))
+}
+
+func TestBreakerTransport(t *testing.T) {
+	st := &sequenceTransport{statuses: []int{500}}
+	bt := &breakerTransport{
+		next:    st,
+		hosts:   map[string]bool{"api.steampowered.com": true},
+		breaker: &circuitBreaker{threshold: 2, cooldown: time.Minute},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/x", nil)
+
+	for i := 0; i < 2; i++ {
+		res, err := bt.RoundTrip(req)
+		assert.Nil(t, err)
+		assert.Equal(t, 500, res.StatusCode)
+	}
+
+	_, err := bt.RoundTrip(req)
+	assert.ErrorIs(t, err, circuitOpenErr)
+	assert.Equal(t, 2, st.calls)
+
+	other, _ := http.NewRequest(http.MethodGet, "https://example.com/x", nil)
+	_, err = bt.RoundTrip(other)
+	assert.Nil(t, err)
+}
+
+type hangingTransport struct{}
+
+func (hangingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestBreakerTransportContext(t *testing.T) {
+	bt := &breakerTransport{
+		next:    hangingTransport{},
+		hosts:   map[string]bool{"api.steampowered.com": true},
+		breaker: &circuitBreaker{threshold: 2, cooldown: time.Minute},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/x", nil)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 3; i++ {
+		_, err := bt.RoundTrip(req.WithContext(cancelled))
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	assert.Equal(t, 0, bt.breaker.failures)
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err := bt.RoundTrip(req.WithContext(ctx))
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	_, err := bt.RoundTrip(req)
+	assert.ErrorIs(t, err, circuitOpenErr)
+}
+
+func TestBreakerTransportCooldownUsesWallClock(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC))
+
+	st := &sequenceTransport{statuses: []int{500, 200}}
+	bt := &breakerTransport{
+		next:    st,
+		hosts:   map[string]bool{"api.steampowered.com": true},
+		breaker: &circuitBreaker{threshold: 1, cooldown: time.Minute},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/x", nil)
+
+	_, err := bt.RoundTrip(req)
+	assert.Nil(t, err)
+
+	_, err = bt.RoundTrip(req)
+	assert.ErrorIs(t, err, circuitOpenErr)
+
+	clock.Advance(2 * time.Minute)
+
+	res, err := bt.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
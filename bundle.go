@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	packageDetailsPath  = "/api/packagedetails?packageids=%d"
+	bundleDetailsPath   = "/actions/ajaxresolvebundles?bundleids=%d&l=english"
+	bundleQueryRegex    = `^(?:https?://)?(?:store\.steampowered\.com/)?(sub|bundle)/(\d+)`
+	bundleCacheTTL      = time.Hour
+	maxBundleCandidates = 5
+	maxBundleItems      = 5
+)
+
+var bundleQueryRe = regexp.MustCompile(bundleQueryRegex)
+
+type packageDetailsRes map[string]struct {
+	Success bool
+	Data    struct {
+		Name string
+		Apps []struct {
+			Id   int
+			Name string
+		}
+		Price *struct {
+			Currency        string
+			Final           int
+			DiscountPercent int `json:"discount_percent"`
+			Individual      int
+		}
+	}
+}
+
+type bundleDetailsRes []struct {
+	BundleId            int    `json:"bundleid"`
+	Name                string `json:"name"`
+	AppIds              []int  `json:"appids"`
+	FormattedFinalPrice string `json:"formatted_final_price"`
+	FormattedOrigPrice  string `json:"formatted_orig_price"`
+	BundleBaseDiscount  int    `json:"bundle_base_discount"`
+}
+
+type bundleInfo struct {
+	Name      string
+	Items     []string
+	ItemCount int
+	Price     string
+	Separate  string
+	Discount  int
+}
+
+func formatMinorPrice(amount int, currency string) string {
+	return fmt.Sprintf("%.2f %s", float64(amount)/100, currency)
+}
+
+func getPackage(ctx context.Context, packageId int, client *steamClient) (*bundleInfo, error) {
+	j, err := getJSON[packageDetailsRes](ctx, client.storeUrl(packageDetailsPath, packageId), "", client)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := (*j)[strconv.Itoa(packageId)]
+	if !ok || !p.Success {
+		return nil, nil
+	}
+
+	b := &bundleInfo{Name: p.Data.Name, ItemCount: len(p.Data.Apps)}
+	for _, a := range p.Data.Apps {
+		b.Items = append(b.Items, a.Name)
+	}
+
+	if price := p.Data.Price; price != nil {
+		b.Price = formatMinorPrice(price.Final, price.Currency)
+
+		if price.Individual > price.Final {
+			b.Separate = formatMinorPrice(price.Individual, price.Currency)
+			b.Discount = 100 - price.Final*100/price.Individual
+		}
+	}
+
+	return b, nil
+}
+
+func getBundle(ctx context.Context, bundleId int, client *steamClient) (*bundleInfo, error) {
+	j, err := getJSON[bundleDetailsRes](ctx, client.storeUrl(bundleDetailsPath, bundleId), "", client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range *j {
+		if r.BundleId != bundleId {
+			continue
+		}
+
+		b := &bundleInfo{Name: r.Name, ItemCount: len(r.AppIds), Price: r.FormattedFinalPrice}
+		if r.BundleBaseDiscount > 0 {
+			b.Separate = r.FormattedOrigPrice
+			b.Discount = r.BundleBaseDiscount
+		}
+
+		return b, nil
+	}
+
+	return nil, nil
+}
+
+func cachedBundle(ctx context.Context, kind string, id int, client *steamClient) (*bundleInfo, error) {
+	key := fmt.Sprintf("%s:%d", kind, id)
+
+	b, err := client.cache.GetOrFetch(ctx, key, bundleCacheTTL, func(ctx context.Context) (interface{}, error) {
+		if kind == "bundle" {
+			return getBundle(ctx, id, client)
+		}
+
+		return getPackage(ctx, id, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.(*bundleInfo), nil
+}
+
+func parseBundleQuery(query string) (string, int, bool) {
+	m := bundleQueryRe.FindStringSubmatch(query)
+	if m == nil {
+		return "", 0, false
+	}
+
+	id, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+
+	return m[1], id, true
+}
+
+func bundleForGame(ctx context.Context, appId int, client *steamClient) (*bundleInfo, error) {
+	d, ok, err := getAppDetails(ctx, appId, client)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	for n, id := range d.Packages {
+		if n == maxBundleCandidates {
+			break
+		}
+
+		b, err := cachedBundle(ctx, "sub", id, client)
+		if err != nil {
+			return nil, err
+		}
+
+		if b != nil && b.ItemCount > 1 {
+			return b, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s settings) formatBundle(b *bundleInfo) string {
+	contents := s.msg("bundle_count", b.ItemCount)
+	if len(b.Items) > 0 {
+		contents = strings.Join(b.Items, ", ")
+		if len(b.Items) > maxBundleItems {
+			contents = s.msg("bundle_more", strings.Join(b.Items[:maxBundleItems], ", "), len(b.Items)-maxBundleItems)
+		}
+	}
+
+	price := b.Price
+	switch {
+	case price == "":
+		price = s.msg("store_no_price")
+	case b.Discount > 0:
+		price = s.msg("bundle_saving", price, b.Discount, b.Separate)
+	}
+
+	return s.msg("bundle", s.formatter.Colour("green", b.Name), contents, price)
+}
+
+func bundleHandler(ctx context.Context, args []string, client *steamClient, s settings) (string, error) {
+	query := strings.Join(args, " ")
+
+	kind, id, ok := parseBundleQuery(query)
+	if !ok {
+		kind = "sub"
+		id, _ = strconv.Atoi(query)
+	}
+
+	var b *bundleInfo
+	var err error
+
+	if id > 0 {
+		b, err = cachedBundle(ctx, kind, id, client)
+	} else {
+		appId, _, ok := resolveGame(query)
+		if !ok {
+			return s.msg("unknown_game", query), nil
+		}
+
+		b, err = bundleForGame(ctx, appId, client)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if b == nil {
+		return s.msg("bundle_not_found", query), nil
+	}
+
+	return s.formatBundle(b), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBundleQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		kind  string
+		id    int
+		ok    bool
+	}{
+		{
+			name:  "Package",
+			query: "sub/469",
+			kind:  "sub",
+			id:    469,
+			ok:    true,
+		},
+		{
+			name:  "Bundle store link",
+			query: "https://store.steampowered.com/bundle/232/Valve_Complete_Pack/",
+			kind:  "bundle",
+			id:    232,
+			ok:    true,
+		},
+		{
+			name:  "Game name",
+			query: "portal",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, id, ok := parseBundleQuery(tc.query)
+
+			assert.Equal(t, tc.kind, kind)
+			assert.Equal(t, tc.id, id)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestBundleHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		args  []string
+		fault mocksteam.Fault
+		out   string
+		err   error
+	}{
+		{
+			name: "Package id",
+			args: []string{"469"},
+			out:  "{green}The Orange Box{clear} - Half-Life 2, Half-Life 2: Episode One, Half-Life 2: Episode Two, Portal, Team Fortress 2 and 1 more - 19.99 GBP (45% less than 35.95 GBP separately)",
+		},
+		{
+			name: "Package without a saving",
+			args: []string{"sub/515"},
+			out:  "{green}Portal{clear} - Portal - 7.19 GBP",
+		},
+		{
+			name: "Bundle",
+			args: []string{"bundle/232"},
+			out:  "{green}Valve Complete Pack{clear} - 23 items - £65.59 (10% less than £72.89 separately)",
+		},
+		{
+			name: "Unknown bundle",
+			args: []string{"bundle/1"},
+			out:  "no package or bundle found for bundle/1",
+		},
+		{
+			name: "Game name",
+			args: []string{"portal"},
+			out:  "{green}The Orange Box{clear} - Half-Life 2, Half-Life 2: Episode One, Half-Life 2: Episode Two, Portal, Team Fortress 2 and 1 more - 19.99 GBP (45% less than 35.95 GBP separately)",
+		},
+		{
+			name: "Unknown game",
+			args: []string{"half", "life", "3"},
+			out:  "Error: unknown game half life 3",
+		},
+		{
+			name:  "Rate limited",
+			args:  []string{"469"},
+			fault: mocksteam.RateLimited,
+			err:   ErrRateLimited,
+		},
+	}
+
+	old := apps
+	apps = newAppIndex(map[int]string{400: "Portal"})
+	defer func() { apps = old }()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("packages", tc.fault)
+
+			out, err := bundleHandler(context.Background(), tc.args, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
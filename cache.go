@@ -0,0 +1,255 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	fetched time.Time
+	expires time.Time
+}
+
+type lruCache struct {
+	mu           sync.Mutex
+	size         int
+	ttl          time.Duration
+	staleFor     time.Duration
+	staleIfError time.Duration
+	ll           *list.List
+	entries      map[string]*list.Element
+	revalidating map[string]bool
+	hits         uint64
+	misses       uint64
+	clock        clock
+}
+
+const revalidateTimeout = 10 * time.Second
+
+func newLRUCache(size int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		size:         size,
+		ttl:          ttl,
+		ll:           list.New(),
+		entries:      make(map[string]*list.Element),
+		revalidating: make(map[string]bool),
+		clock:        wallClock,
+	}
+}
+
+func (c *lruCache) lookup(key string) (value interface{}, fresh, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false, false
+	}
+
+	ce := e.Value.(*cacheEntry)
+	now := c.clock.Now()
+
+	if now.After(ce.expires.Add(c.staleFor)) {
+		if !now.After(ce.fetched.Add(c.staleIfError)) {
+			c.misses++
+			return nil, false, false
+		}
+
+		c.ll.Remove(e)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false, false
+	}
+
+	c.ll.MoveToFront(e)
+
+	if now.After(ce.expires) {
+		c.misses++
+		return ce.value, false, true
+	}
+
+	c.hits++
+
+	return ce.value, true, true
+}
+
+func (c *lruCache) Get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	v, fresh, _ := c.lookup(key)
+	if !fresh {
+		return nil, false
+	}
+
+	return v, true
+}
+
+func (c *lruCache) fallback(key string) (interface{}, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	ce := e.Value.(*cacheEntry)
+	if c.clock.Now().After(ce.fetched.Add(c.staleIfError)) {
+		return nil, time.Time{}, false
+	}
+
+	return ce.value, ce.fetched, true
+}
+
+type fetchFunc func(ctx context.Context) (interface{}, error)
+
+func (c *lruCache) GetOrFetch(ctx context.Context, key string, ttl time.Duration, fetch fetchFunc) (interface{}, error) {
+	if c == nil {
+		return fetch(ctx)
+	}
+
+	v, fresh, ok := c.lookup(key)
+	if fresh {
+		return v, nil
+	}
+
+	if ok {
+		c.revalidate(key, ttl, fetch)
+		return v, nil
+	}
+
+	v, err := fetch(ctx)
+	if errors.Is(err, circuitOpenErr) {
+		if sv, fetched, ok := c.fallback(key); ok {
+			markStale(ctx, fetched)
+			return sv, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetWithTTL(key, v, ttl)
+
+	return v, nil
+}
+
+func (c *lruCache) revalidate(key string, ttl time.Duration, fetch fetchFunc) {
+	c.mu.Lock()
+	if c.revalidating[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[key] = true
+	c.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+		defer cancel()
+
+		if v, err := fetch(ctx); err == nil {
+			c.SetWithTTL(key, v, ttl)
+		}
+
+		c.mu.Lock()
+		delete(c.revalidating, key)
+		c.mu.Unlock()
+	}()
+}
+
+func (c *lruCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, 0)
+}
+
+func (c *lruCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	expires := now.Add(ttl)
+
+	if e, ok := c.entries[key]; ok {
+		ce := e.Value.(*cacheEntry)
+		ce.value = value
+		ce.fetched = now
+		ce.expires = expires
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.ll.PushFront(&cacheEntry{key: key, value: value, fetched: now, expires: expires})
+
+	for c.size > 0 && c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *lruCache) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *lruCache) Flush() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.ll.Len()
+	c.ll.Init()
+	c.entries = make(map[string]*list.Element)
+
+	return n
+}
+
+func (c *lruCache) Stats() (hits, misses uint64) {
+	if c == nil {
+		return 0, 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses
+}
+
+func (c *lruCache) HitRatio() float64 {
+	hits, misses := c.Stats()
+	if hits+misses == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(hits+misses)
+}
+
+func (c *lruCache) String() string {
+	hits, misses := c.Stats()
+	return fmt.Sprintf("cache hits: %d, misses: %d, hit ratio: %.2f", hits, misses, c.HitRatio())
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheGetSet(t *testing.T) {
+	c := newLRUCache(2, time.Minute)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Set("a", 1)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	c.Set("a", 2)
+	v, _ = c.Get("a")
+	assert.Equal(t, 2, v)
+
+	hits, misses := c.Stats()
+	assert.Equal(t, uint64(2), hits)
+	assert.Equal(t, uint64(1), misses)
+	assert.InDelta(t, 0.67, c.HitRatio(), 0.01)
+}
+
+func TestLRUCacheEviction(t *testing.T) {
+	c := newLRUCache(2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "least recently used entry evicted")
+
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	c := newLRUCache(2, time.Minute)
+
+	c.SetWithTTL("a", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestLRUCacheExpiryClock(t *testing.T) {
+	clk := newFakeClock(time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC))
+	c := newLRUCache(2, time.Minute)
+	c.clock = clk
+	c.staleFor = time.Minute
+
+	c.Set("a", 1)
+
+	clk.Advance(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	clk.Advance(2 * time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "expired")
+
+	v, fresh, ok := c.lookup("a")
+	assert.True(t, ok, "still within the stale window")
+	assert.False(t, fresh)
+	assert.Equal(t, 1, v)
+
+	clk.Advance(time.Minute)
+	_, _, ok = c.lookup("a")
+	assert.False(t, ok)
+}
+
+func TestLRUCacheNil(t *testing.T) {
+	var c *lruCache
+
+	c.Set("a", 1)
+	_, ok := c.Get("a")
+
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, c.HitRatio())
+}
+
+func TestLRUCacheGetOrFetch(t *testing.T) {
+	c := newLRUCache(2, time.Minute)
+
+	calls := 0
+	fetch := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := c.GetOrFetch(context.Background(), "a", 0, fetch)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+	}
+
+	assert.Equal(t, 1, calls)
+
+	_, err := c.GetOrFetch(context.Background(), "b", 0, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+}
+
+func TestLRUCacheStaleWhileRevalidate(t *testing.T) {
+	c := newLRUCache(2, time.Minute)
+	c.staleFor = time.Hour
+
+	c.SetWithTTL("a", "old", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	done := make(chan struct{})
+	fetch := func(ctx context.Context) (interface{}, error) {
+		defer close(done)
+		return "new", nil
+	}
+
+	v, err := c.GetOrFetch(context.Background(), "a", time.Minute, fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, "old", v)
+
+	<-done
+	assert.Eventually(t, func() bool {
+		v, ok := c.Get("a")
+		return ok && v == "new"
+	}, time.Second, time.Millisecond)
+}
+
+func TestLRUCacheStaleExpired(t *testing.T) {
+	c := newLRUCache(2, time.Minute)
+
+	c.SetWithTTL("a", "old", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	v, err := c.GetOrFetch(context.Background(), "a", time.Minute, func(ctx context.Context) (interface{}, error) {
+		return "new", nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "new", v)
+}
+
+func TestLRUCacheStaleIfError(t *testing.T) {
+	clk := newFakeClock(time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC))
+	c := newLRUCache(2, time.Minute)
+	c.clock = clk
+	c.staleIfError = time.Hour
+
+	c.Set("a", "old")
+	clk.Advance(10 * time.Minute)
+
+	_, err := c.GetOrFetch(context.Background(), "a", 0, func(ctx context.Context) (interface{}, error) {
+		return nil, ErrSteamAPI
+	})
+	assert.ErrorIs(t, err, ErrSteamAPI)
+
+	ctx := withStaleTracker(context.Background())
+	v, err := c.GetOrFetch(ctx, "a", 0, func(ctx context.Context) (interface{}, error) {
+		return nil, circuitOpenErr
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "old", v)
+
+	since, ok := staleSince(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, clk.Now().Add(-10*time.Minute), since)
+
+	clk.Advance(time.Hour)
+	_, err = c.GetOrFetch(context.Background(), "a", 0, func(ctx context.Context) (interface{}, error) {
+		return nil, circuitOpenErr
+	})
+	assert.ErrorIs(t, err, circuitOpenErr)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUCacheNilGetOrFetch(t *testing.T) {
+	var c *lruCache
+
+	v, err := c.GetOrFetch(context.Background(), "a", 0, func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestLRUCacheFlush(t *testing.T) {
+	c := newLRUCache(10, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 2, c.Flush())
+	assert.Equal(t, 0, c.Len())
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	var nilCache *lruCache
+	assert.Equal(t, 0, nilCache.Flush())
+	assert.Equal(t, 0, nilCache.Len())
+}
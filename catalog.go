@@ -0,0 +1,380 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+type catalog map[string]string
+
+var catalogs = map[string]catalog{
+	"en": {
+		"usage":                   "one of [s]et, [r]ecent or [a]chievements must be passed as a command, see help",
+		"error":                   "Error: %s",
+		"username_needed":         "Error: username needed",
+		"user_set":                "set %s's user to %s",
+		"timezone_needed":         "Error: timezone needed",
+		"unknown_timezone":        "Error: unknown timezone %s",
+		"timezone_set":            "set %s's timezone to %s",
+		"dateformat_needed":       "Error: date format needed, must be one of %s",
+		"dateformat_set":          "set %s's date format to %s",
+		"language_needed":         "Error: language needed",
+		"language_set":            "set %s's language to %s",
+		"toggle_invalid":          "Error: %s must be on or off",
+		"toggle_set":              "set %s's %s %s",
+		"no_id":                   "Error: no id found for %s",
+		"profile_not_public":      "Error: profile is not public",
+		"no_recent_games":         "%s has no recently played steam games",
+		"recent_games":            "%s's recently played steam games: %s",
+		"recent_games_header":     "%s's recently played steam games",
+		"no_recent_achievements":  "%s has no recently unlocked steam achievements",
+		"last_achievement":        "%s's last steam achievement: %s - %s (%s) (%s) (unlocked %s)",
+		"last_achievement_header": "%s's last steam achievement: %s - %s",
+		"progress":                "progress: %s %s",
+		"unlocked":                "unlocked: %s",
+		"rarity":                  "%.1f%% of players",
+		"hidden_achievement":      "<hidden achievement>",
+		"steam_down":              "Steam API appears down, try later",
+		"invalid_key":             "Error: invalid Steam API key",
+		"rate_limited":            "Error: rate limited by Steam, try later",
+		"steam_error":             "Error: Steam API error, try later",
+		"response_too_large":      "Error: Steam API response too large",
+		"cached_ago":              "(cached %s ago)",
+		"partial_results":         "(partial results, Steam was slow to respond)",
+		"store_app":               "%s - %s - %s",
+		"store_free":              "free to play",
+		"store_no_price":          "no price",
+		"store_discount":          "%s (-%d%%)",
+		"store_reviews":           "%s (%d%% of %d reviews positive)",
+		"store_no_reviews":        "no reviews",
+		"profile_preview":         "%s - level %d - %s - %s",
+		"profile_playing":         "playing %s",
+		"profile_not_playing":     "not in game",
+		"vac_clean":               "no VAC bans",
+		"vac_banned":              "%d VAC bans, last %d days ago",
+		"workshop_item":           "%s - %s workshop item - %d subscribers",
+		"help_list":               "commands: %s (use help <command> for details)",
+		"help_unknown":            "Error: unknown command %s",
+		"help_usage":              "%s - %s",
+		"help_aliases":            "(aliases: %s)",
+		"help_set":                "set your steam user",
+		"help_timezone":           "set your timezone, e.g. Europe/London",
+		"help_dateformat":         "set your date format",
+		"help_language":           "set your language",
+		"help_verbose":            "show multi-line output",
+		"help_spoilers":           "show hidden achievement names and descriptions",
+		"help_persona":            "show steam persona names",
+		"help_recent":             "show recently played games",
+		"help_achievement":        "show the most recently unlocked achievement",
+		"help_help":               "show help for a command",
+		"cooldown":                "slow down, try again in %s",
+		"command_failed":          "Error: command failed, try later",
+		"help_pm":                 "send long replies to you in full by private message",
+		"help_version":            "show the module version and build",
+		"help_stats":              "show uptime, usage and cache stats (admins only)",
+		"admin_only":              "Error: only admins can do that",
+		"command_disabled":        "Error: %s is disabled here",
+		"stats":                   "up %s, %d commands served, %s, cache hit ratio %.0f%%, %d panics recovered",
+		"stats_quota":             "%d/%d api requests today",
+		"stats_quota_unlimited":   "%d api requests today",
+		"error_rate_alert":        "steam module alert: %d failed commands in the last %s",
+		"help_audit":              "show recent changes to linked steam users (admins only)",
+		"audit_empty":             "no changes recorded",
+		"audit_entry":             "%s: %s %s %s to %s",
+		"audit_entry_changed":     "%s: %s %s %s to %s (was %s)",
+		"audit_channel":           "in %s",
+		"help_selftest":           "check the steam api, database, broker and cache (admins only)",
+		"help_quota":              "show steam api usage per key for today (admins only)",
+		"help_usage_stats":        "show who uses the module most, or how often a nick has used it",
+		"usage_top":               "top users: %s",
+		"usage_nick":              "%s has run %d commands, %d today",
+		"usage_none":              "%s hasn't run any commands",
+		"usage_empty":             "no commands recorded yet",
+		"usage_limit":             "Error: you have reached the daily command limit, try again tomorrow",
+		"quota_key":               "key %d: %s",
+		"quota_key_percent":       "key %d: %s (%.1f%%)",
+		"selftest_pass":           "%s: pass",
+		"selftest_fail":           "%s: fail (%s)",
+		"sent_pm":                 "(full reply sent by pm)",
+		"help_spy":                "show steamspy ownership and playtime estimates for a game",
+		"game_needed":             "Error: game needed",
+		"unknown_game":            "Error: unknown game %s",
+		"spy_app":                 "%s - %s owners - %.1fh average playtime (%.1fh in the last 2 weeks) - peak of %d players yesterday",
+		"spy_no_data":             "no steamspy data for %s",
+		"help_deal":               "show the best current price and historical low for a game from isthereanydeal",
+		"deal":                    "%s - best price now %s - historical low %s",
+		"deal_price":              "%s at %s",
+		"deal_price_cut":          "%s (-%d%%) at %s",
+		"deal_no_price":           "none",
+		"deal_at_low":             "(at its historical low)",
+		"deal_not_found":          "no isthereanydeal data for %s",
+		"deal_unavailable":        "Error: deals aren't set up, an isthereanydeal key is needed",
+		"help_bundle":             "show what a steam package or bundle contains and how much it saves, ids are package ids unless written as bundle/<id>",
+		"bundle":                  "%s - %s - %s",
+		"bundle_count":            "%d items",
+		"bundle_more":             "%s and %d more",
+		"bundle_saving":           "%s (%d%% less than %s separately)",
+		"bundle_not_found":        "no package or bundle found for %s",
+		"help_curator":            "show a steam curator's recent recommendations, or their verdict on a game",
+		"curator_needed":          "Error: curator id or link needed",
+		"curator_unknown":         "Error: %s isn't a curator id or link",
+		"curator_none":            "no recommendations found for curator %s",
+		"curator_no_verdict":      "curator %s hasn't reviewed %s",
+		"curator_picks":           "recent picks from curator %s: %s",
+		"curator_verdict":         "curator %s on %s: %s",
+		"curator_recommended":     "recommended",
+		"curator_not_recommended": "not recommended",
+		"curator_informational":   "informational",
+		"help_easy":               "list your locked achievements in a game, most commonly unlocked first",
+		"easy_achievements":       "%s's easiest remaining achievements in %s (%d left): %s",
+		"easy_header":             "%s's easiest remaining achievements in %s (%d left)",
+		"easy_all_done":           "%s has unlocked every achievement in %s",
+		"easy_no_achievements":    "%s has no achievements",
+		"help_next":               "suggest the most attainable locked achievement in the game you're playing now",
+		"next_achievement":        "%s is playing %s, next up: %s",
+		"not_playing":             "%s isn't playing anything right now",
+		"help_now_playing":        "show the game someone is playing right now",
+		"now_playing":             "%s is playing %s",
+		"help_owns":               "check whether someone owns a game, or the base game of a dlc",
+		"owns_needed":             "Error: nick and game needed",
+		"owns":                    "%s owns %s",
+		"owns_not":                "%s doesn't own %s",
+		"owns_dlc_unknown":        "%s owns %s, but steam doesn't show whether they own the %s dlc",
+		"owns_dlc_no_base":        "%s doesn't own %s, which %s needs",
+		"help_friends_playing":    "list which of your steam friends are in game right now",
+		"friends_playing":         "%s's friends in game: %s",
+		"friends_more":            "(and %d more)",
+		"friends_none_playing":    "none of %s's friends are in game",
+		"friends_private":         "Error: %s's friends list is not public",
+		"help_hours":              "compare your playtime in a game with a linked nick or a steam friend",
+		"hours_needed":            "Error: game and nick or friend needed",
+		"hours":                   "%s - %s %.1fh vs %s %.1fh - %s",
+		"hours_ahead":             "%s leads by %.1fh",
+		"hours_tied":              "dead even",
+		"hours_unknown_opponent":  "Error: %s isn't a linked nick or one of your steam friends",
+		"hours_private":           "Error: %s's library is not public",
+		"help_players_history":    "show current, today's and 7 day peak player counts for a watched game",
+		"players_history":         "%s - %d playing now, peak today %d, 7 day peak %d %s",
+		"players_history_none":    "no player counts recorded for %s, is it watched?",
+	},
+	"de": {
+		"usage":                   "einer der Befehle [s]et, [r]ecent oder [a]chievements muss angegeben werden, siehe help",
+		"error":                   "Fehler: %s",
+		"username_needed":         "Fehler: Benutzername benötigt",
+		"user_set":                "Benutzer von %s auf %s gesetzt",
+		"timezone_needed":         "Fehler: Zeitzone benötigt",
+		"unknown_timezone":        "Fehler: unbekannte Zeitzone %s",
+		"timezone_set":            "Zeitzone von %s auf %s gesetzt",
+		"dateformat_needed":       "Fehler: Datumsformat benötigt, erlaubt sind %s",
+		"dateformat_set":          "Datumsformat von %s auf %s gesetzt",
+		"language_needed":         "Fehler: Sprache benötigt",
+		"language_set":            "Sprache von %s auf %s gesetzt",
+		"toggle_invalid":          "Fehler: %s muss on oder off sein",
+		"toggle_set":              "%s: %s ist jetzt %s",
+		"no_id":                   "Fehler: keine ID für %s gefunden",
+		"profile_not_public":      "Fehler: Profil ist nicht öffentlich",
+		"no_recent_games":         "%s hat kürzlich keine Steam-Spiele gespielt",
+		"recent_games":            "Kürzlich gespielte Steam-Spiele von %s: %s",
+		"recent_games_header":     "Kürzlich gespielte Steam-Spiele von %s",
+		"no_recent_achievements":  "%s hat kürzlich keine Steam-Errungenschaften freigeschaltet",
+		"last_achievement":        "Letzte Steam-Errungenschaft von %s: %s - %s (%s) (%s) (freigeschaltet %s)",
+		"last_achievement_header": "Letzte Steam-Errungenschaft von %s: %s - %s",
+		"progress":                "Fortschritt: %s %s",
+		"unlocked":                "freigeschaltet: %s",
+		"rarity":                  "%.1f%% der Spieler",
+		"hidden_achievement":      "<versteckte Errungenschaft>",
+		"steam_down":              "Die Steam-API scheint nicht erreichbar zu sein, bitte später erneut versuchen",
+		"invalid_key":             "Fehler: ungültiger Steam-API-Schlüssel",
+		"rate_limited":            "Fehler: von Steam gedrosselt, bitte später erneut versuchen",
+		"steam_error":             "Fehler: Steam-API-Fehler, bitte später erneut versuchen",
+		"response_too_large":      "Fehler: Antwort der Steam-API zu groß",
+		"cached_ago":              "(zwischengespeichert vor %s)",
+		"partial_results":         "(unvollständige Ergebnisse, Steam hat zu langsam geantwortet)",
+		"store_app":               "%s - %s - %s",
+		"store_free":              "kostenlos spielbar",
+		"store_no_price":          "kein Preis",
+		"store_discount":          "%s (-%d%%)",
+		"store_reviews":           "%s (%d%% von %d Rezensionen positiv)",
+		"store_no_reviews":        "keine Rezensionen",
+		"profile_preview":         "%s - Level %d - %s - %s",
+		"profile_playing":         "spielt %s",
+		"profile_not_playing":     "nicht im Spiel",
+		"vac_clean":               "keine VAC-Sperren",
+		"vac_banned":              "%d VAC-Sperren, letzte vor %d Tagen",
+		"workshop_item":           "%s - Workshop-Objekt für %s - %d Abonnenten",
+		"help_list":               "Befehle: %s (help <Befehl> für Details)",
+		"help_unknown":            "Fehler: unbekannter Befehl %s",
+		"help_usage":              "%s - %s",
+		"help_aliases":            "(Aliase: %s)",
+		"help_set":                "deinen Steam-Benutzer setzen",
+		"help_timezone":           "deine Zeitzone setzen, z.B. Europe/Berlin",
+		"help_dateformat":         "dein Datumsformat setzen",
+		"help_language":           "deine Sprache setzen",
+		"help_verbose":            "mehrzeilige Ausgabe anzeigen",
+		"help_spoilers":           "Namen und Beschreibungen versteckter Errungenschaften anzeigen",
+		"help_persona":            "Steam-Anzeigenamen anzeigen",
+		"help_recent":             "kürzlich gespielte Spiele anzeigen",
+		"help_achievement":        "die zuletzt freigeschaltete Errungenschaft anzeigen",
+		"help_help":               "Hilfe zu einem Befehl anzeigen",
+		"cooldown":                "langsamer, versuche es in %s erneut",
+		"command_failed":          "Fehler: Befehl fehlgeschlagen, bitte später erneut versuchen",
+		"help_pm":                 "lange Antworten vollständig per Privatnachricht senden",
+		"help_version":            "Version und Build des Moduls anzeigen",
+		"help_stats":              "Laufzeit, Nutzung und Cache-Statistiken anzeigen (nur Admins)",
+		"admin_only":              "Fehler: nur Admins dürfen das",
+		"command_disabled":        "Fehler: %s ist hier deaktiviert",
+		"stats":                   "läuft seit %s, %d Befehle beantwortet, %s, Cache-Trefferquote %.0f%%, %d abgefangene Panics",
+		"stats_quota":             "%d/%d API-Anfragen heute",
+		"stats_quota_unlimited":   "%d API-Anfragen heute",
+		"error_rate_alert":        "Steam-Modul-Warnung: %d fehlgeschlagene Befehle in den letzten %s",
+		"help_audit":              "letzte Änderungen an verknüpften Steam-Benutzern anzeigen (nur Admins)",
+		"audit_empty":             "keine Änderungen aufgezeichnet",
+		"audit_entry":             "%s: %s %s %s auf %s",
+		"audit_entry_changed":     "%s: %s %s %s auf %s (vorher %s)",
+		"audit_channel":           "in %s",
+		"help_selftest":           "Steam-API, Datenbank, Broker und Cache prüfen (nur Admins)",
+		"help_quota":              "heutige Steam-API-Nutzung pro Schlüssel anzeigen (nur Admins)",
+		"help_usage_stats":        "zeigen, wer das Modul am meisten nutzt, oder wie oft ein Nick es genutzt hat",
+		"usage_top":               "häufigste Nutzer: %s",
+		"usage_nick":              "%s hat %d Befehle ausgeführt, %d heute",
+		"usage_none":              "%s hat noch keine Befehle ausgeführt",
+		"usage_empty":             "noch keine Befehle aufgezeichnet",
+		"usage_limit":             "Fehler: du hast das tägliche Befehlslimit erreicht, versuch es morgen wieder",
+		"quota_key":               "Schlüssel %d: %s",
+		"quota_key_percent":       "Schlüssel %d: %s (%.1f%%)",
+		"selftest_pass":           "%s: ok",
+		"selftest_fail":           "%s: Fehler (%s)",
+		"sent_pm":                 "(vollständige Antwort per PN gesendet)",
+		"help_spy":                "zeigt SteamSpy-Schätzungen zu Besitzern und Spielzeit eines Spiels",
+		"game_needed":             "Fehler: Spiel benötigt",
+		"unknown_game":            "Fehler: unbekanntes Spiel %s",
+		"spy_app":                 "%s - %s Besitzer - %.1fh durchschnittliche Spielzeit (%.1fh in den letzten 2 Wochen) - Höchstwert von %d Spielern gestern",
+		"spy_no_data":             "keine SteamSpy-Daten für %s",
+		"help_deal":               "zeigt den besten aktuellen Preis und das historische Tief eines Spiels von IsThereAnyDeal",
+		"deal":                    "%s - bester Preis jetzt %s - historisches Tief %s",
+		"deal_price":              "%s bei %s",
+		"deal_price_cut":          "%s (-%d%%) bei %s",
+		"deal_no_price":           "keiner",
+		"deal_at_low":             "(auf historischem Tief)",
+		"deal_not_found":          "keine IsThereAnyDeal-Daten für %s",
+		"deal_unavailable":        "Fehler: Angebote sind nicht eingerichtet, ein IsThereAnyDeal-Schlüssel wird benötigt",
+		"help_bundle":             "zeigt, was ein Steam-Paket oder -Bundle enthält und wie viel es spart, IDs sind Paket-IDs, außer sie werden als bundle/<id> geschrieben",
+		"bundle":                  "%s - %s - %s",
+		"bundle_count":            "%d Artikel",
+		"bundle_more":             "%s und %d weitere",
+		"bundle_saving":           "%s (%d%% weniger als %s einzeln)",
+		"bundle_not_found":        "kein Paket oder Bundle für %s gefunden",
+		"help_curator":            "zeigt die neuesten Empfehlungen eines Steam-Kurators oder sein Urteil zu einem Spiel",
+		"curator_needed":          "Fehler: Kurator-ID oder -Link benötigt",
+		"curator_unknown":         "Fehler: %s ist keine Kurator-ID und kein Kurator-Link",
+		"curator_none":            "keine Empfehlungen für Kurator %s gefunden",
+		"curator_no_verdict":      "Kurator %s hat %s nicht bewertet",
+		"curator_picks":           "neueste Empfehlungen von Kurator %s: %s",
+		"curator_verdict":         "Kurator %s zu %s: %s",
+		"curator_recommended":     "empfohlen",
+		"curator_not_recommended": "nicht empfohlen",
+		"curator_informational":   "informativ",
+		"help_easy":               "listet deine gesperrten Erfolge in einem Spiel auf, die häufigsten zuerst",
+		"easy_achievements":       "Einfachste verbleibende Erfolge von %s in %s (%d übrig): %s",
+		"easy_header":             "Einfachste verbleibende Erfolge von %s in %s (%d übrig)",
+		"easy_all_done":           "%s hat alle Erfolge in %s freigeschaltet",
+		"easy_no_achievements":    "%s hat keine Erfolge",
+		"help_next":               "schlägt den am leichtesten erreichbaren gesperrten Erfolg im gerade gespielten Spiel vor",
+		"next_achievement":        "%s spielt %s, als Nächstes: %s",
+		"not_playing":             "%s spielt gerade nichts",
+		"help_now_playing":        "zeigt das Spiel, das jemand gerade spielt",
+		"now_playing":             "%s spielt %s",
+		"help_owns":               "prüft, ob jemand ein Spiel oder das Hauptspiel eines DLCs besitzt",
+		"owns_needed":             "Fehler: Nick und Spiel benötigt",
+		"owns":                    "%s besitzt %s",
+		"owns_not":                "%s besitzt %s nicht",
+		"owns_dlc_unknown":        "%s besitzt %s, aber Steam zeigt nicht, ob der DLC %s dazugehört",
+		"owns_dlc_no_base":        "%s besitzt %s nicht, das für %s benötigt wird",
+		"help_friends_playing":    "listet auf, welche deiner Steam-Freunde gerade spielen",
+		"friends_playing":         "Freunde von %s im Spiel: %s",
+		"friends_more":            "(und %d weitere)",
+		"friends_none_playing":    "keiner der Freunde von %s ist im Spiel",
+		"friends_private":         "Fehler: die Freundesliste von %s ist nicht öffentlich",
+		"help_hours":              "vergleicht deine Spielzeit in einem Spiel mit einem verknüpften Nick oder einem Steam-Freund",
+		"hours_needed":            "Fehler: Spiel und Nick oder Freund benötigt",
+		"hours":                   "%s - %s %.1fh gegen %s %.1fh - %s",
+		"hours_ahead":             "%s liegt %.1fh vorn",
+		"hours_tied":              "Gleichstand",
+		"hours_unknown_opponent":  "Fehler: %s ist weder ein verknüpfter Nick noch einer deiner Steam-Freunde",
+		"hours_private":           "Fehler: die Bibliothek von %s ist nicht öffentlich",
+		"help_players_history":    "zeigt aktuelle, heutige und 7-Tage-Spitzen der Spielerzahlen eines beobachteten Spiels",
+		"players_history":         "%s - %d spielen gerade, Spitze heute %d, 7-Tage-Spitze %d %s",
+		"players_history_none":    "keine Spielerzahlen für %s aufgezeichnet, wird es beobachtet?",
+	},
+}
+
+func catalogNames() string {
+	names := []string{}
+	for n := range catalogs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func loadCatalogs(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	extra := map[string]catalog{}
+	err = json.Unmarshal(b, &extra)
+	if err != nil {
+		return err
+	}
+
+	for lang, c := range extra {
+		if _, ok := catalogs[lang]; !ok {
+			catalogs[lang] = catalog{}
+		}
+
+		for id, m := range c {
+			catalogs[lang][id] = m
+		}
+	}
+
+	return nil
+}
+
+func parseCatalog(name string) (catalog, error) {
+	c, ok := catalogs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown message catalog %s, must be one of %s", name, catalogNames())
+	}
+
+	return c, nil
+}
+
+func parseDestCatalogs(in map[string]string) (map[string]catalog, error) {
+	out := make(map[string]catalog)
+
+	for dest, name := range in {
+		c, err := parseCatalog(name)
+		if err != nil {
+			return nil, err
+		}
+
+		out[dest] = c
+	}
+
+	return out, nil
+}
+
+func (s settings) msg(id string, a ...interface{}) string {
+	f, ok := s.messages[id]
+	if !ok {
+		f = catalogs["en"][id]
+	}
+
+	return fmt.Sprintf(f, a...)
+}
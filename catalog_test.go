@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	for lang, c := range catalogs {
+		t.Run(lang, func(t *testing.T) {
+			for id, m := range catalogs["en"] {
+				tm, ok := c[id]
+				assert.True(t, ok, "missing message %s", id)
+				assert.Equal(t, strings.Count(m, "%"), strings.Count(tm, "%"), "verb count differs for %s", id)
+			}
+		})
+	}
+}
+
+func TestSettingsMsg(t *testing.T) {
+	s := testSettings
+	assert.Equal(t, "Error: no id found for bob", s.msg("no_id", "bob"))
+
+	s.messages = catalogs["de"]
+	assert.Equal(t, "Fehler: keine ID für bob gefunden", s.msg("no_id", "bob"))
+
+	s.messages = catalog{}
+	assert.Equal(t, "Error: no id found for bob", s.msg("no_id", "bob"))
+}
+
+func TestParseDestCatalogs(t *testing.T) {
+	out, err := parseDestCatalogs(map[string]string{"#a": "DE"})
+	assert.Nil(t, err)
+	assert.Equal(t, catalogs["de"], out["#a"])
+
+	_, err = parseDestCatalogs(map[string]string{"#a": "xx"})
+	assert.ErrorContains(t, err, "unknown message catalog xx")
+}
+
+func TestLoadCatalogs(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "messages.json")
+	err := os.WriteFile(fp, []byte(`{"fr":{"no_id":"Erreur : aucun id pour %s"}}`), 0644)
+	assert.Nil(t, err)
+
+	err = loadCatalogs(fp)
+	assert.Nil(t, err)
+	defer delete(catalogs, "fr")
+
+	c, err := parseCatalog("fr")
+	assert.Nil(t, err)
+
+	s := testSettings
+	s.messages = c
+	assert.Equal(t, "Erreur : aucun id pour bob", s.msg("no_id", "bob"))
+	assert.Equal(t, "Error: profile is not public", s.msg("profile_not_public"))
+}
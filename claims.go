@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type claimDir struct {
+	dir    string
+	window time.Duration
+}
+
+var messageClaims *claimDir
+
+func newClaimDir(dir string, window time.Duration) (*claimDir, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &claimDir{dir: dir, window: window}, nil
+}
+
+func claimKey(payload []byte) (string, bool) {
+	m := struct {
+		Tags map[string]string `json:"tags"`
+	}{}
+
+	if json.Unmarshal(payload, &m) != nil || m.Tags["msgid"] == "" {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte("msgid:" + m.Tags["msgid"]))
+	return hex.EncodeToString(sum[:]), true
+}
+
+func (cd *claimDir) create(path string, now time.Time) (bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := f.Close(); err != nil {
+		return true, err
+	}
+
+	return true, os.Chtimes(path, now, now)
+}
+
+func (cd *claimDir) Claim(payload []byte, now time.Time) bool {
+	if cd == nil {
+		return true
+	}
+
+	key, ok := claimKey(payload)
+	if !ok {
+		return true
+	}
+
+	path := filepath.Join(cd.dir, key)
+
+	ok, err := cd.create(path, now)
+	if err != nil {
+		log.Printf("couldn't claim message: %s\n", err)
+		return true
+	}
+
+	if ok {
+		return true
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil || now.Sub(fi.ModTime()) < cd.window {
+		return false
+	}
+
+	if err := os.Remove(path); err != nil {
+		return false
+	}
+
+	ok, err = cd.create(path, now)
+	return ok && err == nil
+}
+
+func (cd *claimDir) Prune(now time.Time) (pruned int, err error) {
+	entries, err := os.ReadDir(cd.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil || now.Sub(fi.ModTime()) < cd.window {
+			continue
+		}
+
+		if os.Remove(filepath.Join(cd.dir, e.Name())) == nil {
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
+
+func pruneClaims(cd *claimDir, interval time.Duration) {
+	for range time.Tick(interval) {
+		if _, err := cd.Prune(wallClock.Now()); err != nil {
+			log.Println(err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimKey(t *testing.T) {
+	a, ok := claimKey([]byte(`{"module":"gowon","msg":".steam r","tags":{"msgid":"abc"}}`))
+	assert.True(t, ok)
+	b, _ := claimKey([]byte(`{"module":"gowon","msg":".steam r","tags":{"msgid":"abc","time":"x"}}`))
+	c, _ := claimKey([]byte(`{"module":"gowon","msg":".steam r","tags":{"msgid":"def"}}`))
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 64)
+
+	_, ok = claimKey([]byte(`{"module":"gowon","msg":".steam r"}`))
+	assert.False(t, ok, "messages without a msgid can't be told apart from repeats")
+}
+
+func claimPayload(id string) []byte {
+	return []byte(`{"msg":".steam r","tags":{"msgid":"` + id + `"}}`)
+}
+
+func TestClaimDirNil(t *testing.T) {
+	var cd *claimDir
+	assert.True(t, cd.Claim([]byte("x"), time.Now()))
+}
+
+func TestClaimDirClaim(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	first, err := newClaimDir(dir, time.Minute)
+	assert.Nil(t, err)
+	second, err := newClaimDir(dir, time.Minute)
+	assert.Nil(t, err)
+
+	assert.True(t, first.Claim(claimPayload("a"), now))
+	assert.False(t, second.Claim(claimPayload("a"), now))
+	assert.True(t, second.Claim(claimPayload("b"), now))
+
+	assert.True(t, second.Claim(claimPayload("a"), now.Add(2*time.Minute)))
+
+	repeat := []byte(`{"msg":".steam r bob"}`)
+	assert.True(t, first.Claim(repeat, now))
+	assert.True(t, second.Claim(repeat, now), "messages without a msgid are always answered")
+}
+
+func TestClaimDirClaimFakeClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC))
+
+	cd, err := newClaimDir(t.TempDir(), time.Minute)
+	assert.Nil(t, err)
+
+	assert.True(t, cd.Claim(claimPayload("a"), clock.Now()))
+	clock.Advance(30 * time.Second)
+	assert.False(t, cd.Claim(claimPayload("a"), clock.Now()))
+	clock.Advance(time.Minute)
+	assert.True(t, cd.Claim(claimPayload("a"), clock.Now()))
+
+	pruned, err := cd.Prune(clock.Now().Add(2 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, pruned)
+}
+
+func TestClaimDirPrune(t *testing.T) {
+	cd, err := newClaimDir(filepath.Join(t.TempDir(), "claims"), time.Minute)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	assert.True(t, cd.Claim(claimPayload("a"), now))
+	assert.True(t, cd.Claim(claimPayload("b"), now))
+
+	old := now.Add(-2 * time.Minute)
+	key, _ := claimKey(claimPayload("a"))
+	assert.Nil(t, os.Chtimes(filepath.Join(cd.dir, key), old, old))
+
+	pruned, err := cd.Prune(now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, pruned)
+
+	entries, err := os.ReadDir(cd.dir)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
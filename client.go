@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type steamClient struct {
+	httpClient *http.Client
+	baseUrls   map[string]string
+	language   string
+	limiter    *tokenBucket
+	cache      *lruCache
+}
+
+type clientOption func(*steamClient) error
+
+func WithHTTPClient(hc *http.Client) clientOption {
+	return func(c *steamClient) error {
+		c.httpClient = hc
+		return nil
+	}
+}
+
+func WithBaseURL(name, in string) clientOption {
+	return func(c *steamClient) error {
+		if _, ok := c.baseUrls[name]; !ok {
+			return fmt.Errorf("unknown base url %s", name)
+		}
+
+		u, err := parseBaseUrl(name, in)
+		if err != nil {
+			return err
+		}
+
+		c.baseUrls[name] = u
+		return nil
+	}
+}
+
+func WithLanguage(lang string) clientOption {
+	return func(c *steamClient) (err error) {
+		c.language, err = parseLanguage(lang)
+		return err
+	}
+}
+
+func WithRateLimiter(tb *tokenBucket) clientOption {
+	return func(c *steamClient) error {
+		c.limiter = tb
+		return nil
+	}
+}
+
+func WithCache(lc *lruCache) clientOption {
+	return func(c *steamClient) error {
+		c.cache = lc
+		return nil
+	}
+}
+
+func newSteamClient(options ...clientOption) (*steamClient, error) {
+	c := &steamClient{
+		httpClient: http.DefaultClient,
+		baseUrls: map[string]string{
+			"api":       apiBaseUrl,
+			"store":     storeBaseUrl,
+			"community": communityBaseUrl,
+			"steamspy":  steamSpyBaseUrl,
+			"itad":      itadBaseUrl,
+		},
+		language: "en",
+	}
+
+	for _, o := range options {
+		if err := o(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func steamClientOptions(opts Options) []clientOption {
+	var limiter *tokenBucket
+	if opts.RateLimit > 0 {
+		limiter = newTokenBucket(opts.RateLimit, opts.RateBurst)
+	}
+
+	options := []clientOption{
+		WithHTTPClient(newHTTPClient(opts, limiter)),
+		WithBaseURL("api", opts.APIURL),
+		WithBaseURL("store", opts.StoreURL),
+		WithBaseURL("community", opts.CommunityURL),
+		WithBaseURL("steamspy", opts.SteamSpyURL),
+		WithBaseURL("itad", opts.ITADURL),
+		WithLanguage(opts.Language),
+		WithRateLimiter(limiter),
+	}
+
+	if opts.CacheSize > 0 {
+		lc := newLRUCache(opts.CacheSize, opts.CacheTTL)
+		lc.staleFor = opts.CacheStaleFor
+		lc.staleIfError = opts.StaleTTL
+		options = append(options, WithCache(lc))
+	}
+
+	return options
+}
+
+func (c *steamClient) Do(req *http.Request) (*http.Response, error) {
+	return c.httpClient.Do(req)
+}
+
+func (c *steamClient) settings(s settings) settings {
+	s.language = c.language
+	return s
+}
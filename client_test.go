@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSteamClient(t *testing.T) {
+	hc := &http.Client{Timeout: time.Second}
+	lc := newLRUCache(10, time.Minute)
+
+	cases := []struct {
+		name     string
+		options  []clientOption
+		http     *http.Client
+		api      string
+		store    string
+		language string
+		cache    *lruCache
+		errMsg   string
+	}{
+		{
+			name:     "Defaults",
+			http:     http.DefaultClient,
+			api:      apiBaseUrl,
+			store:    storeBaseUrl,
+			language: "en",
+		},
+		{
+			name:     "All options",
+			options:  []clientOption{WithHTTPClient(hc), WithBaseURL("api", "http://localhost:8080/"), WithBaseURL("store", "http://localhost:8081"), WithLanguage("DE"), WithCache(lc)},
+			http:     hc,
+			api:      "http://localhost:8080",
+			store:    "http://localhost:8081",
+			language: "de",
+			cache:    lc,
+		},
+		{
+			name:    "Invalid base url",
+			options: []clientOption{WithBaseURL("api", "api.steampowered.com")},
+			errMsg:  "invalid api url api.steampowered.com",
+		},
+		{
+			name:    "Unknown base url",
+			options: []clientOption{WithBaseURL("steamdb", "https://steamdb.info")},
+			errMsg:  "unknown base url steamdb",
+		},
+		{
+			name:    "Unknown language",
+			options: []clientOption{WithLanguage("klingon")},
+			errMsg:  "unknown language klingon, must be one of " + strings.Join(languages, ", "),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := newSteamClient(tc.options...)
+
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				assert.Nil(t, c)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.http, c.httpClient)
+			assert.Equal(t, tc.api, c.baseUrls["api"])
+			assert.Equal(t, tc.store, c.baseUrls["store"])
+			assert.Equal(t, tc.language, c.language)
+			assert.Equal(t, tc.cache, c.cache)
+		})
+	}
+}
+
+func TestSteamClientRateLimiter(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c, err := newSteamClient(steamClientOptions(Options{
+		APIURL:        ts.URL,
+		StoreURL:      storeBaseUrl,
+		CommunityURL:  communityBaseUrl,
+		SteamSpyURL:   steamSpyBaseUrl,
+		ITADURL:       itadBaseUrl,
+		Language:      "en",
+		RetryAttempts: 3,
+		RetryDelay:    time.Millisecond,
+		RateLimit:     0.001,
+		RateBurst:     1,
+	})...)
+	assert.Nil(t, err)
+	assert.NotNil(t, c.limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/x", nil)
+	_, err = c.httpClient.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, calls, "retries wait for the rate limiter")
+}
+
+func TestSteamClientUrls(t *testing.T) {
+	c, err := newSteamClient(steamClientOptions(Options{
+		APIURL:       "http://localhost:8080/",
+		StoreURL:     "http://localhost:8081",
+		CommunityURL: "http://localhost:8082",
+		SteamSpyURL:  "http://localhost:8083",
+		ITADURL:      "http://localhost:8084",
+		Language:     "en",
+		CacheSize:    10,
+		CacheTTL:     time.Minute,
+	})...)
+	assert.Nil(t, err)
+
+	other, err := newSteamClient()
+	assert.Nil(t, err)
+
+	assert.NotNil(t, c.cache)
+	assert.Equal(t, "http://localhost:8080/ISteamUser/ResolveVanityURL/v1/?key=key&vanityurl=bob", c.apiUrl(resolveVanityPath, "key", "bob"))
+	assert.Equal(t, "http://localhost:8081/app/1", c.storeUrl(storeAppPath, 1))
+	assert.Equal(t, "http://localhost:8082/profiles/1/games/?tab=recent", c.communityUrl(communityRecentPath, "1"))
+	assert.Equal(t, "http://localhost:8083/api.php?request=appdetails&appid=1", c.steamSpyUrl(steamSpyPath, 1))
+	assert.Equal(t, "http://localhost:8084/games/lookup/v1?key=key&appid=1", c.itadUrl(itadLookupPath, "key", 1))
+
+	assert.Nil(t, other.cache)
+	assert.Equal(t, "https://api.steampowered.com/ISteamUser/ResolveVanityURL/v1/?key=key&vanityurl=bob", other.apiUrl(resolveVanityPath, "key", "bob"))
+	assert.Equal(t, "https://store.steampowered.com/app/1", other.storeUrl(storeAppPath, 1))
+}
@@ -0,0 +1,15 @@
+package main
+
+import "time"
+
+type clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+var wallClock clock = systemClock{}
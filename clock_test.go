@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func useFakeClock(t *testing.T, now time.Time) *fakeClock {
+	c := newFakeClock(now)
+
+	old := wallClock
+	wallClock = c
+	t.Cleanup(func() { wallClock = old })
+
+	return c
+}
+
+func TestSystemClock(t *testing.T) {
+	assert.WithinDuration(t, time.Now(), systemClock{}.Now(), time.Second)
+}
+
+func TestFakeClockAdvance(t *testing.T) {
+	start := time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC)
+	c := newFakeClock(start)
+
+	c.Advance(time.Hour)
+
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gowon-irc/go-gowon"
+)
+
+type subcommandFunc func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error)
+
+type subcommand struct {
+	name       string
+	aliases    []string
+	args       string
+	help       string
+	adminOnly  bool
+	needsLink  bool
+	needsGame  bool
+	takesCount bool
+	run        subcommandFunc
+}
+
+var subcommands []subcommand
+
+func init() {
+	subcommands = []subcommand{
+		{
+			name:    "set",
+			aliases: []string{"s"},
+			args:    "<steam user>",
+			help:    "help_set",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return setUserHandler(kv, s, m, user)
+			},
+		},
+		{
+			name:    "timezone",
+			aliases: []string{"tz"},
+			args:    "<timezone>",
+			help:    "help_timezone",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return setTimezoneHandler(kv, s, m.Nick, user)
+			},
+		},
+		{
+			name:    "dateformat",
+			aliases: []string{"df"},
+			args:    "<format>",
+			help:    "help_dateformat",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return setDateFormatHandler(kv, s, m.Nick, user)
+			},
+		},
+		{
+			name:    "language",
+			aliases: []string{"l"},
+			args:    "<language>",
+			help:    "help_language",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return setLanguageHandler(kv, s, m.Nick, user)
+			},
+		},
+		toggleSubcommand("verbose", verbosePref, "help_verbose"),
+		toggleSubcommand("spoilers", spoilersPref, "help_spoilers"),
+		toggleSubcommand("persona", personaPref, "help_persona"),
+		toggleSubcommand("pm", pmPref, "help_pm"),
+		{
+			name:       "recent",
+			aliases:    []string{"r"},
+			args:       "[steam user] [count]",
+			help:       "help_recent",
+			needsLink:  true,
+			takesCount: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return steamLastGame(ctx, apiKey, user, client, s)
+			},
+		},
+		{
+			name:      "nowplaying",
+			aliases:   []string{"np"},
+			args:      "[steam user]",
+			help:      "help_now_playing",
+			needsLink: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return nowPlayingHandler(ctx, apiKey, user, client, s)
+			},
+		},
+		{
+			name:      "achievement",
+			aliases:   []string{"a"},
+			args:      "[steam user]",
+			help:      "help_achievement",
+			needsLink: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return steamLastAchievement(ctx, apiKey, user, client, s)
+			},
+		},
+		{
+			name:      "easy",
+			args:      "<game>",
+			help:      "help_easy",
+			needsGame: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				linked, err := linkedUser(kv, m.Nick, "")
+				if err != nil || linked == "" {
+					return s.msg("username_needed"), err
+				}
+
+				return easyHandler(ctx, apiKey, linked, args, client, s)
+			},
+		},
+		{
+			name:      "next",
+			args:      "[steam user]",
+			help:      "help_next",
+			needsLink: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return nextHandler(ctx, apiKey, user, client, s)
+			},
+		},
+		{
+			name: "owns",
+			args: "<nick> <game or dlc>",
+			help: "help_owns",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return ownsHandler(ctx, apiKey, kv, args, client, s)
+			},
+		},
+		{
+			name:      "friendsplaying",
+			aliases:   []string{"fp"},
+			args:      "[steam user]",
+			help:      "help_friends_playing",
+			needsLink: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return friendsPlayingHandler(ctx, apiKey, user, client, s)
+			},
+		},
+		{
+			name: "hours",
+			args: "<game> <nick|friend>",
+			help: "help_hours",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return hoursHandler(ctx, apiKey, kv, m.Nick, args, client, s)
+			},
+		},
+		{
+			name:      "spy",
+			args:      "<game>",
+			help:      "help_spy",
+			needsGame: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return spyHandler(ctx, args, client, s)
+			},
+		},
+		{
+			name:      "deal",
+			args:      "<game>",
+			help:      "help_deal",
+			needsGame: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return dealHandler(ctx, args, client, s)
+			},
+		},
+		{
+			name:      "bundle",
+			args:      "<name or id>",
+			help:      "help_bundle",
+			needsGame: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return bundleHandler(ctx, args, client, s)
+			},
+		},
+		{
+			name: "curator",
+			args: "<curator> [game]",
+			help: "help_curator",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return curatorHandler(ctx, args, client, s)
+			},
+		},
+		{
+			name:      "playershistory",
+			aliases:   []string{"ph"},
+			args:      "<game>",
+			help:      "help_players_history",
+			needsGame: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return playersHistoryHandler(kv, args, s, wallClock.Now())
+			},
+		},
+		{
+			name: "usage",
+			args: "[nick]",
+			help: "help_usage_stats",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return usageHandler(kv, s, user, wallClock.Now())
+			},
+		},
+		{
+			name:      "stats",
+			help:      "help_stats",
+			adminOnly: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return s.statsMessage(wallClock.Now(), client.cache), nil
+			},
+		},
+		{
+			name:      "quota",
+			help:      "help_quota",
+			adminOnly: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return s.quotaMessage(wallClock.Now()), nil
+			},
+		},
+		{
+			name:      "selftest",
+			help:      "help_selftest",
+			adminOnly: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return s.runSelfChecks(ctx, selfChecks(apiKey, kv, client, wallClock.Now())), nil
+			},
+		},
+		{
+			name:       "audit",
+			args:       "[count]",
+			help:       "help_audit",
+			adminOnly:  true,
+			takesCount: true,
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return auditHandler(kv, s)
+			},
+		},
+		{
+			name: "version",
+			help: "help_version",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return buildInfo(), nil
+			},
+		},
+		{
+			name:    "help",
+			aliases: []string{"h"},
+			args:    "[command]",
+			help:    "help_help",
+			run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+				return s.helpMessage(user), nil
+			},
+		},
+	}
+}
+
+func toggleSubcommand(name, pref, help string) subcommand {
+	return subcommand{
+		name: name,
+		args: "<on|off>",
+		help: help,
+		run: func(ctx context.Context, user, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message, args []string) (string, error) {
+			return setToggleHandler(kv, s, pref, m.Nick, user)
+		},
+	}
+}
+
+func findSubcommand(name string) (subcommand, bool) {
+	name = strings.ToLower(name)
+
+	for _, sc := range subcommands {
+		if sc.name == name {
+			return sc, true
+		}
+
+		for _, a := range sc.aliases {
+			if a == name {
+				return sc, true
+			}
+		}
+	}
+
+	return subcommand{}, false
+}
+
+func parseDisabledCommands(in []string) (map[string]map[string]bool, error) {
+	out := make(map[string]map[string]bool)
+
+	for _, entry := range in {
+		dest, name := "", entry
+		if i := strings.LastIndex(entry, ":"); i >= 0 {
+			dest, name = entry[:i], entry[i+1:]
+		}
+
+		sc, ok := findSubcommand(name)
+		if !ok {
+			return nil, fmt.Errorf("can't disable unknown command %s", name)
+		}
+
+		if out[dest] == nil {
+			out[dest] = make(map[string]bool)
+		}
+		out[dest][sc.name] = true
+	}
+
+	return out, nil
+}
+
+func (s settings) commandDisabled(dest, name string) bool {
+	return s.disabledCommands[""][name] || s.disabledCommands[dest][name]
+}
+
+func linkedUser(kv Store, nick, user string) (string, error) {
+	if user != "" {
+		return user, nil
+	}
+
+	linked, err := getUser(kv, []byte(nick))
+	if err != nil {
+		return "", err
+	}
+
+	return string(linked), nil
+}
+
+func nickSteamUser(kv Store, nick string) (string, error) {
+	linked, err := linkedUser(kv, nick, "")
+	if err != nil || linked == "" {
+		return nick, err
+	}
+
+	return linked, nil
+}
+
+func routeCommand(ctx context.Context, command string, args []string, apiKey string, kv Store, client *steamClient, s settings, m gowon.Message) (string, error) {
+	sc, ok := findSubcommand(command)
+	if !ok {
+		return s.msg("usage"), nil
+	}
+
+	if s.commandDisabled(m.Dest, sc.name) {
+		return s.msg("command_disabled", sc.name), nil
+	}
+
+	if sc.adminOnly && !s.isAdmin(m.Nick) {
+		return s.msg("admin_only"), nil
+	}
+
+	if sc.needsGame && len(args) == 0 {
+		return s.msg("game_needed"), nil
+	}
+
+	user := ""
+	if len(args) > 0 {
+		user = args[0]
+	}
+
+	if sc.needsLink {
+		linked, err := linkedUser(kv, m.Nick, user)
+		if err != nil {
+			return "", err
+		}
+
+		if linked == "" {
+			return s.msg("username_needed"), nil
+		}
+
+		user = linked
+	}
+
+	return sc.run(ctx, user, apiKey, kv, client, s, m, args)
+}
+
+func (sc subcommand) usage() string {
+	if sc.args == "" {
+		return sc.name
+	}
+
+	return fmt.Sprintf("%s %s", sc.name, sc.args)
+}
+
+func (s settings) subcommandHelp(sc subcommand) string {
+	out := s.msg("help_usage", sc.usage(), s.msg(sc.help))
+	if len(sc.aliases) > 0 {
+		out = fmt.Sprintf("%s %s", out, s.msg("help_aliases", strings.Join(sc.aliases, ", ")))
+	}
+
+	return out
+}
+
+func (s settings) helpMessage(name string) string {
+	if name == "" {
+		names := []string{}
+		for _, sc := range subcommands {
+			names = append(names, sc.name)
+		}
+
+		return s.msg("help_list", strings.Join(names, ", "))
+	}
+
+	sc, ok := findSubcommand(name)
+	if !ok {
+		return s.msg("help_unknown", name)
+	}
+
+	return s.subcommandHelp(sc)
+}
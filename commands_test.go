@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSubcommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		command  string
+		expected string
+		ok       bool
+	}{
+		{name: "Name", command: "recent", expected: "recent", ok: true},
+		{name: "Alias", command: "a", expected: "achievement", ok: true},
+		{name: "Upper case", command: "TZ", expected: "timezone", ok: true},
+		{name: "Unknown", command: "nope", ok: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sc, ok := findSubcommand(tc.command)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, sc.name)
+		})
+	}
+}
+
+func TestSubcommandsHaveHelp(t *testing.T) {
+	for _, sc := range subcommands {
+		for lang, c := range catalogs {
+			_, ok := c[sc.help]
+			assert.True(t, ok, "%s missing %s", lang, sc.help)
+		}
+	}
+}
+
+func TestHelpMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{
+			name:     "List",
+			command:  "",
+			expected: "commands: set, timezone, dateformat, language, verbose, spoilers, persona, pm, recent, nowplaying, achievement, easy, next, owns, friendsplaying, hours, spy, deal, bundle, curator, playershistory, usage, stats, quota, selftest, audit, version, help (use help <command> for details)",
+		},
+		{
+			name:     "Command with aliases",
+			command:  "r",
+			expected: "recent [steam user] [count] - show recently played games (aliases: r)",
+		},
+		{
+			name:     "Command without aliases",
+			command:  "verbose",
+			expected: "verbose <on|off> - show multi-line output",
+		},
+		{
+			name:     "Unknown command",
+			command:  "nope",
+			expected: "Error: unknown command nope",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, testSettings.helpMessage(tc.command))
+		})
+	}
+}
+
+func TestParseDisabledCommands(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       []string
+		expected map[string]map[string]bool
+		errMsg   string
+	}{
+		{
+			name:     "Empty",
+			expected: map[string]map[string]bool{},
+		},
+		{
+			name: "Everywhere and per destination",
+			in:   []string{"selftest", "#quiet:r", "#quiet:achievement"},
+			expected: map[string]map[string]bool{
+				"":       {"selftest": true},
+				"#quiet": {"recent": true, "achievement": true},
+			},
+		},
+		{
+			name:   "Unknown command",
+			in:     []string{"#quiet:nope"},
+			errMsg: "can't disable unknown command nope",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := parseDisabledCommands(tc.in)
+
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
+
+func TestRouteCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		command  string
+		nick     string
+		dest     string
+		expected string
+	}{
+		{
+			name:     "Unknown command",
+			command:  "nope",
+			nick:     "boss",
+			expected: "one of [s]et, [r]ecent or [a]chievements must be passed as a command, see help",
+		},
+		{
+			name:     "Admin only",
+			command:  "audit",
+			nick:     "someone",
+			expected: "Error: only admins can do that",
+		},
+		{
+			name:     "Admin",
+			command:  "audit",
+			nick:     "boss",
+			expected: "no changes recorded",
+		},
+		{
+			name:     "Disabled everywhere",
+			command:  "selftest",
+			nick:     "boss",
+			dest:     "#chan",
+			expected: "Error: selftest is disabled here",
+		},
+		{
+			name:     "Disabled in destination",
+			command:  "r",
+			nick:     "nick1",
+			dest:     "#quiet",
+			expected: "Error: recent is disabled here",
+		},
+		{
+			name:     "Needs link",
+			command:  "recent",
+			nick:     "nick1",
+			dest:     "#chan",
+			expected: "Error: username needed",
+		},
+		{
+			name:     "Needs game",
+			command:  "spy",
+			nick:     "nick1",
+			dest:     "#chan",
+			expected: "Error: game needed",
+		},
+		{
+			name:     "Needs game before link",
+			command:  "easy",
+			nick:     "nick1",
+			dest:     "#chan",
+			expected: "Error: game needed",
+		},
+		{
+			name:     "Bundle needs a name",
+			command:  "bundle",
+			nick:     "nick1",
+			dest:     "#chan",
+			expected: "Error: game needed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.admins = adminSet([]string{"Boss"})
+			s.disabledCommands, _ = parseDisabledCommands([]string{"selftest", "#quiet:recent"})
+
+			m := gowon.Message{Nick: tc.nick, Dest: tc.dest}
+			out, err := routeCommand(context.Background(), tc.command, nil, "key", openTestDB(t), nil, s, m)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const channelCooldownWindow = time.Minute
+
+type cooldownTracker struct {
+	mu           sync.Mutex
+	userCooldown time.Duration
+	channelLimit int
+	users        map[string]time.Time
+	channels     map[string][]time.Time
+	notified     map[string]bool
+}
+
+var cooldowns *cooldownTracker
+
+func newCooldownTracker(userCooldown time.Duration, channelLimit int) *cooldownTracker {
+	return &cooldownTracker{
+		userCooldown: userCooldown,
+		channelLimit: channelLimit,
+		users:        make(map[string]time.Time),
+		channels:     make(map[string][]time.Time),
+		notified:     make(map[string]bool),
+	}
+}
+
+func (ct *cooldownTracker) recent(channel string, now time.Time) []time.Time {
+	kept := ct.channels[channel][:0]
+	for _, t := range ct.channels[channel] {
+		if now.Sub(t) < channelCooldownWindow {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) == 0 {
+		delete(ct.channels, channel)
+		return nil
+	}
+
+	ct.channels[channel] = kept
+	return kept
+}
+
+func (ct *cooldownTracker) wait(nick, channel string, now time.Time) time.Duration {
+	if last, ok := ct.users[nick]; ok {
+		if now.Sub(last) < ct.userCooldown {
+			return ct.userCooldown - now.Sub(last)
+		}
+		delete(ct.users, nick)
+	}
+
+	if ct.channelLimit > 0 && channel != "" {
+		if recent := ct.recent(channel, now); len(recent) >= ct.channelLimit {
+			return channelCooldownWindow - now.Sub(recent[0])
+		}
+	}
+
+	return 0
+}
+
+func (ct *cooldownTracker) Allow(nick, channel string, now time.Time) (time.Duration, bool) {
+	if ct == nil {
+		return 0, false
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if d := ct.wait(nick, channel, now); d > 0 {
+		notified := ct.notified[nick]
+		ct.notified[nick] = true
+		return d, notified
+	}
+
+	delete(ct.notified, nick)
+
+	if ct.userCooldown > 0 {
+		ct.users[nick] = now
+	}
+
+	if ct.channelLimit > 0 && channel != "" {
+		ct.channels[channel] = append(ct.channels[channel], now)
+	}
+
+	return 0, false
+}
+
+func (ct *cooldownTracker) Prune(now time.Time) (pruned int) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	for nick, last := range ct.users {
+		if now.Sub(last) >= ct.userCooldown {
+			delete(ct.users, nick)
+			pruned++
+		}
+	}
+
+	for channel := range ct.channels {
+		ct.recent(channel, now)
+	}
+
+	for nick := range ct.notified {
+		if _, ok := ct.users[nick]; !ok {
+			delete(ct.notified, nick)
+		}
+	}
+
+	return pruned
+}
+
+func pruneCooldowns(ct *cooldownTracker, interval time.Duration) {
+	for range time.Tick(interval) {
+		ct.Prune(wallClock.Now())
+	}
+}
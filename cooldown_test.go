@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCooldownTrackerNil(t *testing.T) {
+	var ct *cooldownTracker
+
+	wait, notified := ct.Allow("nick", "#chan", time.Now())
+	assert.Equal(t, time.Duration(0), wait)
+	assert.False(t, notified)
+}
+
+func TestCooldownTrackerUser(t *testing.T) {
+	ct := newCooldownTracker(10*time.Second, 0)
+	now := time.Now()
+
+	wait, _ := ct.Allow("nick", "#chan", now)
+	assert.Equal(t, time.Duration(0), wait)
+
+	wait, notified := ct.Allow("nick", "#chan", now.Add(4*time.Second))
+	assert.Equal(t, 6*time.Second, wait)
+	assert.False(t, notified)
+
+	wait, notified = ct.Allow("nick", "#chan", now.Add(5*time.Second))
+	assert.Equal(t, 5*time.Second, wait)
+	assert.True(t, notified)
+
+	wait, _ = ct.Allow("other", "#chan", now.Add(5*time.Second))
+	assert.Equal(t, time.Duration(0), wait)
+
+	wait, notified = ct.Allow("nick", "#chan", now.Add(10*time.Second))
+	assert.Equal(t, time.Duration(0), wait)
+	assert.False(t, notified)
+}
+
+func TestCooldownTrackerChannel(t *testing.T) {
+	ct := newCooldownTracker(0, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		wait, _ := ct.Allow("nick", "#chan", now.Add(time.Duration(i)*time.Second))
+		assert.Equal(t, time.Duration(0), wait)
+	}
+
+	wait, _ := ct.Allow("nick", "#chan", now.Add(10*time.Second))
+	assert.Equal(t, 50*time.Second, wait)
+
+	wait, _ = ct.Allow("nick", "#other", now.Add(10*time.Second))
+	assert.Equal(t, time.Duration(0), wait)
+
+	wait, _ = ct.Allow("nick", "#chan", now.Add(time.Minute))
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestCooldownTrackerPrune(t *testing.T) {
+	ct := newCooldownTracker(10*time.Second, 1)
+	now := time.Now()
+
+	ct.Allow("nick", "#chan", now)
+	ct.Allow("other", "#chan", now.Add(time.Second))
+	ct.Allow("third", "#other", now.Add(5*time.Second))
+
+	assert.Equal(t, 1, ct.Prune(now.Add(12*time.Second)))
+	assert.Len(t, ct.users, 1)
+	assert.Len(t, ct.notified, 0)
+	assert.Len(t, ct.channels, 2)
+
+	assert.Equal(t, 1, ct.Prune(now.Add(2*time.Minute)))
+	assert.Len(t, ct.users, 0)
+	assert.Len(t, ct.channels, 0)
+}
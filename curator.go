@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	curatorRecommendationsPath = "/curator/%d/ajaxgetfilteredrecommendations/?query=&start=%d&count=%d&sort=recent"
+	curatorQueryRegex          = `^(?:(?:https?://)?store\.steampowered\.com/curator/)?(\d+)(?:-([^/]+))?/?$`
+	curatorPageSize            = 50
+	maxCuratorPages            = 10
+	maxCuratorPicks            = 5
+	curatorCacheTTL            = time.Hour
+)
+
+var curatorQueryRe = regexp.MustCompile(curatorQueryRegex)
+
+type curatorRecommendation struct {
+	AppId               int    `json:"appid"`
+	RecommendationState int    `json:"recommendation_state"`
+	Blurb               string `json:"blurb"`
+	TimeRecommended     int64  `json:"time_recommended"`
+}
+
+type curatorRecommendationsRes struct {
+	Success         int                     `json:"success"`
+	TotalCount      int                     `json:"total_count"`
+	Recommendations []curatorRecommendation `json:"recommendations"`
+}
+
+func getCuratorRecommendations(ctx context.Context, curatorId, start int, client *steamClient) (*curatorRecommendationsRes, error) {
+	return getJSON[curatorRecommendationsRes](ctx, client.storeUrl(curatorRecommendationsPath, curatorId, start, curatorPageSize), "", client)
+}
+
+func cachedCuratorRecommendations(ctx context.Context, curatorId, start int, client *steamClient) (*curatorRecommendationsRes, error) {
+	key := fmt.Sprintf("curator:%d:%d", curatorId, start)
+
+	r, err := client.cache.GetOrFetch(ctx, key, curatorCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return getCuratorRecommendations(ctx, curatorId, start, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*curatorRecommendationsRes), nil
+}
+
+func findCuratorRecommendation(ctx context.Context, curatorId, appId int, client *steamClient) (*curatorRecommendation, bool, error) {
+	for page := 0; page < maxCuratorPages; page++ {
+		r, err := cachedCuratorRecommendations(ctx, curatorId, page*curatorPageSize, client)
+		if err != nil || r.Success != 1 {
+			return nil, false, err
+		}
+
+		for _, rec := range r.Recommendations {
+			if rec.AppId == appId {
+				return &rec, true, nil
+			}
+		}
+
+		if (page+1)*curatorPageSize >= r.TotalCount {
+			break
+		}
+	}
+
+	return nil, true, nil
+}
+
+func parseCuratorQuery(query string) (int, string, bool) {
+	m := curatorQueryRe.FindStringSubmatch(query)
+	if m == nil {
+		return 0, "", false
+	}
+
+	id, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, "", false
+	}
+
+	name := strings.ReplaceAll(m[2], "-", " ")
+	if name == "" {
+		name = m[1]
+	}
+
+	return id, name, true
+}
+
+func (s settings) formatVerdict(state int, text string) string {
+	switch state {
+	case 0:
+		return s.formatter.Colour("green", text)
+	case 1:
+		return s.formatter.Colour("red", text)
+	}
+
+	return s.formatter.Colour("yellow", text)
+}
+
+func (s settings) verdictName(state int) string {
+	switch state {
+	case 0:
+		return s.msg("curator_recommended")
+	case 1:
+		return s.msg("curator_not_recommended")
+	}
+
+	return s.msg("curator_informational")
+}
+
+func (s settings) formatCuratorPicks(ctx context.Context, name string, recs []curatorRecommendation, client *steamClient) string {
+	picks := []string{}
+
+	for n, r := range recs {
+		if n == maxCuratorPicks {
+			break
+		}
+
+		picks = append(picks, s.formatVerdict(r.RecommendationState, appName(ctx, r.AppId, client)))
+	}
+
+	return s.msg("curator_picks", name, strings.Join(picks, ", "))
+}
+
+func (s settings) formatCuratorVerdict(name, game string, r *curatorRecommendation) string {
+	out := s.msg("curator_verdict", name, game, s.formatVerdict(r.RecommendationState, s.verdictName(r.RecommendationState)))
+	if r.Blurb != "" {
+		out = fmt.Sprintf("%s - \"%s\"", out, r.Blurb)
+	}
+
+	return out
+}
+
+func curatorHandler(ctx context.Context, args []string, client *steamClient, s settings) (string, error) {
+	if len(args) == 0 {
+		return s.msg("curator_needed"), nil
+	}
+
+	curatorId, name, ok := parseCuratorQuery(args[0])
+	if !ok {
+		return s.msg("curator_unknown", args[0]), nil
+	}
+
+	query := strings.Join(args[1:], " ")
+	if query == "" {
+		r, err := cachedCuratorRecommendations(ctx, curatorId, 0, client)
+		if err != nil {
+			return "", err
+		}
+
+		if r.Success != 1 || len(r.Recommendations) == 0 {
+			return s.msg("curator_none", name), nil
+		}
+
+		return s.formatCuratorPicks(ctx, name, r.Recommendations, client), nil
+	}
+
+	appId, game, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	if game == "" {
+		game = appName(ctx, appId, client)
+	}
+
+	rec, found, err := findCuratorRecommendation(ctx, curatorId, appId, client)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case !found:
+		return s.msg("curator_none", name), nil
+	case rec == nil:
+		return s.msg("curator_no_verdict", name, game), nil
+	}
+
+	return s.formatCuratorVerdict(name, game, rec), nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCuratorQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		id    int
+		out   string
+		ok    bool
+	}{
+		{
+			name:  "Id",
+			query: "33075774",
+			id:    33075774,
+			out:   "33075774",
+			ok:    true,
+		},
+		{
+			name:  "Store link",
+			query: "https://store.steampowered.com/curator/33075774-PC-Gamer/",
+			id:    33075774,
+			out:   "PC Gamer",
+			ok:    true,
+		},
+		{
+			name:  "Name",
+			query: "pcgamer",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, name, ok := parseCuratorQuery(tc.query)
+
+			assert.Equal(t, tc.id, id)
+			assert.Equal(t, tc.out, name)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestCuratorHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		args  []string
+		fault mocksteam.Fault
+		out   string
+		err   error
+	}{
+		{
+			name: "No curator",
+			args: []string{},
+			out:  "Error: curator id or link needed",
+		},
+		{
+			name: "Not a curator",
+			args: []string{"pcgamer"},
+			out:  "Error: pcgamer isn't a curator id or link",
+		},
+		{
+			name: "Recent picks",
+			args: []string{"store.steampowered.com/curator/33075774-PC-Gamer"},
+			out:  "recent picks from curator PC Gamer: {green}Portal 2{clear}, {yellow}Portal{clear}, {red}Counter-Strike{clear}",
+		},
+		{
+			name: "No recommendations",
+			args: []string{"1"},
+			out:  "no recommendations found for curator 1",
+		},
+		{
+			name: "Verdict on a game",
+			args: []string{"33075774", "portal", "2"},
+			out:  "curator 33075774 on Portal 2: {green}recommended{clear} - \"Still the best co-op puzzler around.\"",
+		},
+		{
+			name: "Verdict without a blurb",
+			args: []string{"33075774", "400"},
+			out:  "curator 33075774 on Portal: {yellow}informational{clear}",
+		},
+		{
+			name: "Game not reviewed",
+			args: []string{"33075774", "70"},
+			out:  "curator 33075774 hasn't reviewed Half-Life",
+		},
+		{
+			name: "Unknown game",
+			args: []string{"33075774", "zzzz"},
+			out:  "Error: unknown game zzzz",
+		},
+		{
+			name:  "Rate limited",
+			args:  []string{"33075774"},
+			fault: mocksteam.RateLimited,
+			err:   ErrRateLimited,
+		},
+	}
+
+	old := apps
+	apps = newAppIndex(map[int]string{10: "Counter-Strike", 70: "Half-Life", 400: "Portal", 620: "Portal 2"})
+	defer func() { apps = old }()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("curator", tc.fault)
+
+			out, err := curatorHandler(context.Background(), tc.args, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
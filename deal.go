@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	itadLookupPath   = "/games/lookup/v1?key=%s&appid=%d"
+	itadOverviewPath = "/games/overview/v2?key=%s&country=%s"
+	itadCacheTTL     = time.Hour
+)
+
+type itadLookupRes struct {
+	Found bool
+	Game  struct {
+		Id    string
+		Title string
+	}
+}
+
+type itadPrice struct {
+	Amount   float64
+	Currency string
+}
+
+type itadDeal struct {
+	Shop struct {
+		Name string
+	}
+	Price itadPrice
+	Cut   int
+}
+
+type itadOverviewRes struct {
+	Prices []struct {
+		Id      string
+		Current *itadDeal
+		Lowest  *itadDeal
+	}
+}
+
+type gameDeal struct {
+	Title   string
+	Current *itadDeal
+	Lowest  *itadDeal
+}
+
+func getITADGame(ctx context.Context, key string, appId int, client *steamClient) (*itadLookupRes, error) {
+	return getJSON[itadLookupRes](ctx, client.itadUrl(itadLookupPath, key, appId), "", client)
+}
+
+func getITADOverview(ctx context.Context, key, country, id string, client *steamClient) (*itadOverviewRes, error) {
+	return postJSON[itadOverviewRes](ctx, client.itadUrl(itadOverviewPath, key, country), "", []string{id}, client)
+}
+
+func getGameDeal(ctx context.Context, key, country string, appId int, client *steamClient) (*gameDeal, error) {
+	g, err := getITADGame(ctx, key, appId, client)
+	if err != nil || !g.Found {
+		return nil, err
+	}
+
+	o, err := getITADOverview(ctx, key, country, g.Game.Id, client)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &gameDeal{Title: g.Game.Title}
+	for _, p := range o.Prices {
+		if p.Id == g.Game.Id {
+			d.Current, d.Lowest = p.Current, p.Lowest
+		}
+	}
+
+	return d, nil
+}
+
+func cachedGameDeal(ctx context.Context, key, country string, appId int, client *steamClient) (*gameDeal, error) {
+	cacheKey := fmt.Sprintf("deal:%s:%d", strings.ToLower(country), appId)
+
+	d, err := client.cache.GetOrFetch(ctx, cacheKey, itadCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return getGameDeal(ctx, key, country, appId, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d.(*gameDeal), nil
+}
+
+func (s settings) formatDealPrice(d *itadDeal) string {
+	if d == nil {
+		return s.msg("deal_no_price")
+	}
+
+	price := fmt.Sprintf("%.2f %s", d.Price.Amount, d.Price.Currency)
+	if d.Cut > 0 {
+		return s.msg("deal_price_cut", price, d.Cut, d.Shop.Name)
+	}
+
+	return s.msg("deal_price", price, d.Shop.Name)
+}
+
+func (s settings) formatGameDeal(d *gameDeal) string {
+	out := s.msg("deal", s.formatter.Colour("green", d.Title), s.formatDealPrice(d.Current), s.formatDealPrice(d.Lowest))
+
+	if d.Current != nil && d.Lowest != nil && d.Current.Price.Amount <= d.Lowest.Price.Amount {
+		out = fmt.Sprintf("%s %s", out, s.msg("deal_at_low"))
+	}
+
+	return out
+}
+
+func dealHandler(ctx context.Context, args []string, client *steamClient, s settings) (string, error) {
+	if s.itadKey == "" {
+		return s.msg("deal_unavailable"), nil
+	}
+
+	query := strings.Join(args, " ")
+
+	appId, _, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	d, err := cachedGameDeal(ctx, s.itadKey, s.itadCountry, appId, client)
+	if err != nil {
+		return "", err
+	}
+
+	if d == nil {
+		return s.msg("deal_not_found", query), nil
+	}
+
+	return s.formatGameDeal(d), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDealHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		args  []string
+		fault mocksteam.Fault
+		out   string
+		err   error
+	}{
+		{
+			name: "No key",
+			args: []string{"620"},
+			out:  "Error: deals aren't set up, an isthereanydeal key is needed",
+		},
+		{
+			name: "Unknown game",
+			key:  "key",
+			args: []string{"half", "life", "3"},
+			out:  "Error: unknown game half life 3",
+		},
+		{
+			name: "Known game",
+			key:  "key",
+			args: []string{"620"},
+			out:  "{green}Portal 2{clear} - best price now 1.99 USD (-80%) at Steam - historical low 1.49 USD (-85%) at GOG",
+		},
+		{
+			name: "Not on isthereanydeal",
+			key:  "key",
+			args: []string{"400"},
+			out:  "no isthereanydeal data for 400",
+		},
+		{
+			name:  "Server error",
+			key:   "key",
+			args:  []string{"620"},
+			fault: mocksteam.ServerError,
+			err:   ErrSteamAPI,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("itadoverview", tc.fault)
+
+			s := testSettings
+			s.itadKey = tc.key
+			s.itadCountry = "US"
+
+			out, err := dealHandler(context.Background(), tc.args, client, s)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestFormatGameDeal(t *testing.T) {
+	deal := func(amount float64, cut int, shop string) *itadDeal {
+		d := &itadDeal{Price: itadPrice{Amount: amount, Currency: "EUR"}, Cut: cut}
+		d.Shop.Name = shop
+		return d
+	}
+
+	cases := []struct {
+		name string
+		deal gameDeal
+		out  string
+	}{
+		{
+			name: "At historical low",
+			deal: gameDeal{Title: "a", Current: deal(2, 50, "Steam"), Lowest: deal(2, 50, "Steam")},
+			out:  "{green}a{clear} - best price now 2.00 EUR (-50%) at Steam - historical low 2.00 EUR (-50%) at Steam (at its historical low)",
+		},
+		{
+			name: "Full price",
+			deal: gameDeal{Title: "a", Current: deal(10, 0, "Steam"), Lowest: deal(5, 50, "GOG")},
+			out:  "{green}a{clear} - best price now 10.00 EUR at Steam - historical low 5.00 EUR (-50%) at GOG",
+		},
+		{
+			name: "No prices",
+			deal: gameDeal{Title: "a"},
+			out:  "{green}a{clear} - best price now none - historical low none",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, testSettings.formatGameDeal(&tc.deal))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugMux(t *testing.T) {
+	mux := newDebugMux()
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{path: "/debug/pprof/", status: http.StatusOK},
+		{path: "/debug/pprof/goroutine?debug=1", status: http.StatusOK},
+		{path: "/debug/pprof/cmdline", status: http.StatusOK},
+		{path: "/other", status: http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
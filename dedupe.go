@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// detachedContext keeps the values of its parent but not its deadline or
+// cancellation, so a shared call outlives the caller that started it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (dc detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (dc detachedContext) Done() <-chan struct{}             { return nil }
+func (dc detachedContext) Err() error                        { return nil }
+func (dc detachedContext) Value(key interface{}) interface{} { return dc.parent.Value(key) }
+
+type sharedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+type dedupeCall struct {
+	done     chan struct{}
+	waiters  int
+	finished bool
+	shared   bool
+
+	// res is handed unbuffered to the only waiter, sr is copied to every
+	// waiter when there are several.
+	res *http.Response
+	sr  *sharedResponse
+	err error
+}
+
+type dedupeTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*dedupeCall
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (cb *cancelBody) Close() error {
+	defer cb.cancel()
+	return cb.ReadCloser.Close()
+}
+
+func (dt *dedupeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return dt.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+
+	dt.mu.Lock()
+	if dt.calls == nil {
+		dt.calls = make(map[string]*dedupeCall)
+	}
+
+	c, ok := dt.calls[key]
+	if !ok {
+		c = &dedupeCall{done: make(chan struct{})}
+		dt.calls[key] = c
+		go dt.do(key, c, req)
+	}
+	c.waiters++
+	dt.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-req.Context().Done():
+		dt.mu.Lock()
+		finished, shared := c.finished, c.shared
+		if !finished {
+			c.waiters--
+		}
+		dt.mu.Unlock()
+
+		if finished && !shared {
+			<-c.done
+			if c.res != nil {
+				c.res.Body.Close()
+			}
+		}
+
+		return nil, req.Context().Err()
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if c.res != nil {
+		c.res.Request = req
+		return c.res, nil
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(c.sr.statusCode),
+		StatusCode:    c.sr.statusCode,
+		Header:        c.sr.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(c.sr.body)),
+		ContentLength: int64(len(c.sr.body)),
+		Request:       req,
+	}, nil
+}
+
+func (dt *dedupeTransport) do(key string, c *dedupeCall, req *http.Request) {
+	defer close(c.done)
+
+	var ctx context.Context = detachedContext{parent: req.Context()}
+	cancel := func() {}
+	if dt.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, dt.timeout)
+	}
+
+	res, err := dt.next.RoundTrip(req.Clone(ctx))
+
+	dt.mu.Lock()
+	delete(dt.calls, key)
+	c.finished = true
+	waiters := c.waiters
+	c.shared = waiters > 1
+	dt.mu.Unlock()
+
+	if err != nil {
+		cancel()
+		c.err = err
+		return
+	}
+
+	if waiters == 0 {
+		res.Body.Close()
+		cancel()
+		return
+	}
+
+	if waiters == 1 {
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		c.res = res
+		return
+	}
+
+	defer cancel()
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	c.sr = &sharedResponse{statusCode: res.StatusCode, header: res.Header, body: body}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeTransport(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	f := func(req *http.Request) *http.Response {
+		atomic.AddInt32(&calls, 1)
+		<-release
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("body")),
+			Header:     make(http.Header),
+		}
+	}
+
+	dt := &dedupeTransport{next: RoundTripFunc(f)}
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/?steamid=1", nil)
+			res, err := dt.RoundTrip(req)
+			assert.Nil(t, err)
+
+			body, _ := ioutil.ReadAll(res.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, []string{"body", "body", "body", "body", "body"}, bodies)
+}
+
+func TestDedupeTransportSequential(t *testing.T) {
+	calls := 0
+	client := NewTestClient(200, "body")
+	dt := &dedupeTransport{next: countingTransport(client.httpClient.Transport, &calls)}
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/", nil)
+		_, err := dt.RoundTrip(req)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestDedupeTransportWaiterContext(t *testing.T) {
+	release := make(chan struct{})
+	upstream := make(chan error, 1)
+
+	f := func(req *http.Request) *http.Response {
+		<-release
+		upstream <- req.Context().Err()
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("body")),
+			Header:     make(http.Header),
+		}
+	}
+
+	dt := &dedupeTransport{next: RoundTripFunc(f), timeout: time.Second}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.steampowered.com/?steamid=1", nil)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := dt.RoundTrip(first)
+		errs <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	bodies := make(chan string, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/?steamid=1", nil)
+		res, err := dt.RoundTrip(req)
+		assert.Nil(t, err)
+
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		bodies <- string(body)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	close(release)
+	assert.Nil(t, <-upstream)
+	assert.Equal(t, "body", <-bodies)
+}
+
+func TestDedupeTransportSingleWaiterStreams(t *testing.T) {
+	body := ioutil.NopCloser(bytes.NewBufferString("body"))
+
+	f := func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: body, Header: make(http.Header), ContentLength: -1}
+	}
+
+	dt := &dedupeTransport{next: RoundTripFunc(f)}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/", nil)
+	res, err := dt.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), res.ContentLength)
+	assert.Equal(t, req, res.Request)
+
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "body", string(b))
+	assert.Nil(t, res.Body.Close())
+}
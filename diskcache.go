@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+var diskCacheBucket = []byte("cache")
+
+type diskCacheEntry struct {
+	Expires time.Time
+	Value   json.RawMessage
+}
+
+type boltCache struct {
+	kv    Store
+	ttl   time.Duration
+	clock clock
+}
+
+var diskCache *boltCache
+
+func newBoltCache(kv Store, ttl time.Duration) (*boltCache, error) {
+	err := kv.Update(diskCacheBucket, func(b Bucket) error {
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &boltCache{kv: kv, ttl: ttl, clock: wallClock}, nil
+}
+
+func (c *boltCache) Get(key string, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+
+	var b []byte
+	err := c.kv.View(diskCacheBucket, func(bucket Bucket) error {
+		b = bucket.Get([]byte(key))
+		return nil
+	})
+	if err != nil || len(b) == 0 {
+		return false
+	}
+
+	e := diskCacheEntry{}
+	if err := json.Unmarshal(b, &e); err != nil || c.clock.Now().After(e.Expires) {
+		return false
+	}
+
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+func (c *boltCache) Set(key string, v interface{}) error {
+	if c == nil {
+		return nil
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(diskCacheEntry{Expires: c.clock.Now().Add(c.ttl), Value: value})
+	if err != nil {
+		return err
+	}
+
+	return c.kv.Update(diskCacheBucket, func(bucket Bucket) error {
+		return bucket.Put([]byte(key), b)
+	})
+}
+
+func (c *boltCache) Prune(now time.Time) (pruned int, err error) {
+	err = c.kv.Update(diskCacheBucket, func(b Bucket) error {
+		expired := [][]byte{}
+
+		err := b.ForEach(func(k, v []byte) error {
+			e := diskCacheEntry{}
+			if json.Unmarshal(v, &e) != nil || now.After(e.Expires) {
+				expired = append(expired, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		pruned = len(expired)
+		return nil
+	})
+
+	return pruned, err
+}
+
+func (c *boltCache) Flush() (flushed int, err error) {
+	if c == nil {
+		return 0, nil
+	}
+
+	err = c.kv.Update(diskCacheBucket, func(b Bucket) error {
+		flushed, err = b.Clear()
+		return err
+	})
+
+	return flushed, err
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoltCacheGetSet(t *testing.T) {
+	c, err := newBoltCache(openTestDB(t), time.Hour)
+	assert.Nil(t, err)
+
+	out := map[string]float64{}
+	assert.False(t, c.Get("a", &out))
+
+	assert.Nil(t, c.Set("a", map[string]float64{"ach": 12.5}))
+
+	assert.True(t, c.Get("a", &out))
+	assert.Equal(t, map[string]float64{"ach": 12.5}, out)
+}
+
+func TestBoltCacheExpiry(t *testing.T) {
+	c, err := newBoltCache(openTestDB(t), -time.Second)
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.Set("a", 1))
+
+	out := 0
+	assert.False(t, c.Get("a", &out))
+
+	n, err := c.Prune(time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestBoltCacheExpiryClock(t *testing.T) {
+	clk := newFakeClock(time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC))
+	c, err := newBoltCache(openTestDB(t), time.Hour)
+	assert.Nil(t, err)
+	c.clock = clk
+
+	assert.Nil(t, c.Set("a", 1))
+
+	out := 0
+	clk.Advance(59 * time.Minute)
+	assert.True(t, c.Get("a", &out))
+
+	clk.Advance(2 * time.Minute)
+	assert.False(t, c.Get("a", &out))
+}
+
+func TestBoltCacheNil(t *testing.T) {
+	var c *boltCache
+
+	out := 0
+	assert.False(t, c.Get("a", &out))
+	assert.Nil(t, c.Set("a", 1))
+}
+
+func TestCachedGameSchemaFromDisk(t *testing.T) {
+	c, err := newBoltCache(openTestDB(t), time.Hour)
+	assert.Nil(t, err)
+
+	diskCache = c
+	defer func() { diskCache = nil }()
+
+	body := `{"game":{"gameName":"Game","availableGameStats":{"achievements":[{"name":"a","hidden":1}]}}}`
+
+	calls := 0
+	client := NewTestClient(200, body)
+	client.httpClient.Transport = countingTransport(client.httpClient.Transport, &calls)
+
+	for i := 0; i < 2; i++ {
+		gs, err := cachedGameSchema(context.Background(), "key", 1, "en", client)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]bool{"a": true}, gs.Hidden())
+	}
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestBoltCacheFlush(t *testing.T) {
+	c, err := newBoltCache(openTestDB(t), time.Hour)
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.Set("a", 1))
+	assert.Nil(t, c.Set("b", 2))
+
+	n, err := c.Flush()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+
+	v := 0
+	assert.False(t, c.Get("a", &v))
+
+	assert.Nil(t, c.Set("a", 1))
+	assert.True(t, c.Get("a", &v))
+}
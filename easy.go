@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxEasyAchievements = 5
+
+type easyAchievement struct {
+	Name        string
+	Description string
+	Rarity      float64
+}
+
+type easyAchievementsResult struct {
+	User         string
+	Game         string
+	Achievements []easyAchievement
+	Remaining    int
+	Total        int
+}
+
+func fetchEasyAchievements(ctx context.Context, apiKey, user string, appId int, client *steamClient, s settings) (*easyAchievementsResult, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	as, err := getAchievements(ctx, apiKey, id, appId, s.language, client)
+	if err != nil {
+		return nil, err
+	}
+
+	percentages, err := cachedGlobalPercentages(ctx, appId, client)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &easyAchievementsResult{
+		User:  s.displayName(ctx, apiKey, id, user, client),
+		Game:  as.PlayerStats.GameName,
+		Total: len(as.PlayerStats.Achievements),
+	}
+
+	for _, a := range as.PlayerStats.Achievements {
+		if a.UnlockTime > 0 {
+			continue
+		}
+
+		a = s.withSchema(ctx, apiKey, appId, a, client)
+		r.Achievements = append(r.Achievements, easyAchievement{
+			Name:        a.Name,
+			Description: s.achievementDescription(ctx, apiKey, appId, a, client),
+			Rarity:      percentages[a.ApiName],
+		})
+	}
+
+	sort.SliceStable(r.Achievements, func(i, j int) bool {
+		return r.Achievements[i].Rarity > r.Achievements[j].Rarity
+	})
+
+	r.Remaining = len(r.Achievements)
+	if len(r.Achievements) > maxEasyAchievements {
+		r.Achievements = r.Achievements[:maxEasyAchievements]
+	}
+
+	return r, nil
+}
+
+func (s settings) formatEasyAchievement(a easyAchievement) string {
+	out := fmt.Sprintf("%s (%s)", a.Name, s.msg("rarity", a.Rarity))
+	if a.Description != "" {
+		out = fmt.Sprintf("%s - %s", out, a.Description)
+	}
+
+	return out
+}
+
+func (s settings) renderEasyAchievements(r *easyAchievementsResult) string {
+	switch {
+	case r.Total == 0:
+		return s.msg("easy_no_achievements", r.Game)
+	case r.Remaining == 0:
+		return s.msg("easy_all_done", r.User, r.Game)
+	}
+
+	items := []string{}
+	for _, a := range r.Achievements {
+		items = append(items, s.formatEasyAchievement(a))
+	}
+
+	if s.verbose {
+		lines := append([]string{s.msg("easy_header", r.User, r.Game, r.Remaining)}, items...)
+		return s.formatter.Lines(lines)
+	}
+
+	return s.msg("easy_achievements", r.User, r.Game, r.Remaining, strings.Join(items, "; "))
+}
+
+func easyHandler(ctx context.Context, apiKey, user string, args []string, client *steamClient, s settings) (string, error) {
+	query := strings.Join(args, " ")
+
+	appId, _, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	r, err := fetchEasyAchievements(ctx, apiKey, user, appId, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("profile_not_public"), nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if r.Game == "" {
+		r.Game = query
+	}
+
+	return s.renderEasyAchievements(r), nil
+}
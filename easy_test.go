@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasyHandler(t *testing.T) {
+	cases := []struct {
+		name    string
+		user    string
+		args    []string
+		fault   mocksteam.Fault
+		verbose bool
+		out     string
+		err     error
+	}{
+		{
+			name: "Sorted by global percentage",
+			user: "gaben",
+			args: []string{"400"},
+			out:  "gaben's easiest remaining achievements in Portal (2 left): Fratricide (48.6% of players) - Do whatever it takes to survive; Transmission Received (8.4% of players) - Find all the radios",
+		},
+		{
+			name:    "Verbose",
+			user:    "gaben",
+			args:    []string{"400"},
+			verbose: true,
+			out:     "gaben's easiest remaining achievements in Portal (2 left) | Fratricide (48.6% of players) - Do whatever it takes to survive | Transmission Received (8.4% of players) - Find all the radios",
+		},
+		{
+			name: "Game without achievements",
+			user: "gaben",
+			args: []string{"1"},
+			out:  "1 has no achievements",
+		},
+		{
+			name: "Unknown user",
+			user: "nobody",
+			args: []string{"400"},
+			out:  "Error: no id found for nobody",
+		},
+		{
+			name:  "Private profile",
+			user:  "gaben",
+			args:  []string{"400"},
+			fault: mocksteam.Private,
+			out:   "Error: profile is not public",
+		},
+		{
+			name:  "Rate limited",
+			user:  "gaben",
+			args:  []string{"400"},
+			fault: mocksteam.RateLimited,
+			err:   ErrRateLimited,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("achievements", tc.fault)
+
+			s := testSettings
+			s.verbose = tc.verbose
+
+			out, err := easyHandler(context.Background(), "key", tc.user, tc.args, client, s)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRenderEasyAchievements(t *testing.T) {
+	r := &easyAchievementsResult{User: "gaben", Game: "Portal 2", Total: 3}
+
+	assert.Equal(t, "gaben has unlocked every achievement in Portal 2", testSettings.renderEasyAchievements(r))
+}
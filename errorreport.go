@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+var apiKeyRe = regexp.MustCompile(`key=[^&\s"]+`)
+
+type errorReport struct {
+	Module  string    `json:"module"`
+	Command string    `json:"command"`
+	Nick    string    `json:"nick"`
+	Dest    string    `json:"dest"`
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+}
+
+type errorPublisher struct {
+	client mqtt.Client
+	topic  string
+	qos    byte
+}
+
+var errorReports *errorPublisher
+
+func sanitiseError(err error) string {
+	return apiKeyRe.ReplaceAllString(err.Error(), "key=REDACTED")
+}
+
+func newErrorReport(command, nick, dest string, err error, now time.Time) errorReport {
+	return errorReport{
+		Module:  moduleName,
+		Command: command,
+		Nick:    nick,
+		Dest:    dest,
+		Error:   sanitiseError(err),
+		Time:    now.UTC(),
+	}
+}
+
+func (ep *errorPublisher) Report(command, nick, dest string, err error) {
+	if ep == nil || err == nil {
+		return
+	}
+
+	b, err := json.Marshal(newErrorReport(command, nick, dest, err, time.Now()))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	ep.client.Publish(ep.topic, ep.qos, false, b)
+}
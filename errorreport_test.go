@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitiseError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "No key",
+			err:      errors.New("boom"),
+			expected: "boom",
+		},
+		{
+			name:     "Key in url",
+			err:      errors.New(`Get "https://api.steampowered.com/x/?key=abc123&steamid=1": timeout`),
+			expected: `Get "https://api.steampowered.com/x/?key=REDACTED&steamid=1": timeout`,
+		},
+		{
+			name:     "Key at end of url",
+			err:      errors.New(`Get "https://api.steampowered.com/x/?steamid=1&key=abc123": timeout`),
+			expected: `Get "https://api.steampowered.com/x/?steamid=1&key=REDACTED": timeout`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sanitiseError(tc.err))
+		})
+	}
+}
+
+func TestNewErrorReport(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.FixedZone("x", 3600))
+
+	r := newErrorReport("r", "nick", "#chan", errors.New("https://x/?key=abc"), now)
+
+	assert.Equal(t, errorReport{
+		Module:  "steam",
+		Command: "r",
+		Nick:    "nick",
+		Dest:    "#chan",
+		Error:   "https://x/?key=REDACTED",
+		Time:    now.UTC(),
+	}, r)
+}
+
+func TestErrorPublisherNil(t *testing.T) {
+	var ep *errorPublisher
+	ep.Report("r", "nick", "#chan", errors.New("boom"))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type formatter interface {
+	Colour(colour, s string) string
+	Stat(colour, s string) string
+	Link(url string) string
+	Lines(lines []string) string
+	Render(s string) string
+}
+
+type ircFormatter struct{}
+
+func (ircFormatter) Colour(colour, s string) string {
+	return fmt.Sprintf("{%s}%s{clear}", colour, s)
+}
+
+func (ircFormatter) Stat(colour, s string) string {
+	return fmt.Sprintf("{%s}%s{clear}", colour, s)
+}
+
+func (ircFormatter) Link(url string) string {
+	return url
+}
+
+func (ircFormatter) Lines(lines []string) string {
+	return strings.Join(lines, " | ")
+}
+
+func (ircFormatter) Render(s string) string {
+	return s
+}
+
+type discordFormatter struct{}
+
+func (discordFormatter) Colour(colour, s string) string {
+	return fmt.Sprintf("**%s**", s)
+}
+
+func (discordFormatter) Stat(colour, s string) string {
+	return fmt.Sprintf("`%s`", s)
+}
+
+func (discordFormatter) Link(url string) string {
+	return fmt.Sprintf("<%s>", url)
+}
+
+func (discordFormatter) Lines(lines []string) string {
+	return strings.Join(lines, "\n")
+}
+
+func (discordFormatter) Render(s string) string {
+	return s
+}
+
+var htmlColours = map[string]string{
+	"green":   "#00aa00",
+	"red":     "#cc0000",
+	"blue":    "#0000cc",
+	"orange":  "#ff8800",
+	"magenta": "#cc00cc",
+	"cyan":    "#00aaaa",
+	"yellow":  "#ccaa00",
+}
+
+// htmlFormatter writes its tags with unguessable markers instead of < and >,
+// so Render can escape everything else in the message, names and catalog
+// strings included, before turning the markers back into tags.
+type htmlFormatter struct{}
+
+var htmlTagOpen, htmlTagClose = newHTMLMarkers()
+
+var (
+	htmlEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	htmlUnmarker  = strings.NewReplacer(htmlTagOpen, "<", htmlTagClose, ">")
+	htmlURLQuoter = strings.NewReplacer(`"`, "%22")
+)
+
+func newHTMLMarkers() (string, string) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	prefix := "\uE000" + hex.EncodeToString(b)
+
+	return prefix + "o", prefix + "c"
+}
+
+func htmlTag(s string) string {
+	return htmlTagOpen + s + htmlTagClose
+}
+
+func (htmlFormatter) Colour(colour, s string) string {
+	hex, ok := htmlColours[colour]
+	if !ok {
+		return s
+	}
+
+	return htmlTag(fmt.Sprintf(`font color="%s" data-mx-color="%s"`, hex, hex)) + s + htmlTag("/font")
+}
+
+func (f htmlFormatter) Stat(colour, s string) string {
+	return f.Colour(colour, s)
+}
+
+func (htmlFormatter) Link(url string) string {
+	return htmlTag(fmt.Sprintf(`a href="%s"`, htmlURLQuoter.Replace(url))) + url + htmlTag("/a")
+}
+
+func (htmlFormatter) Lines(lines []string) string {
+	return strings.Join(lines, htmlTag("br"))
+}
+
+func (htmlFormatter) Render(s string) string {
+	return htmlUnmarker.Replace(htmlEscaper.Replace(s))
+}
+
+var formatters = map[string]formatter{
+	"irc":     ircFormatter{},
+	"discord": discordFormatter{},
+	"html":    htmlFormatter{},
+}
+
+func formatterNames() string {
+	names := []string{}
+	for n := range formatters {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func parseFormatter(name string) (formatter, error) {
+	f, ok := formatters[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %s, must be one of %s", name, formatterNames())
+	}
+
+	return f, nil
+}
+
+func parseDestFormatters(in map[string]string) (map[string]formatter, error) {
+	out := make(map[string]formatter)
+
+	for dest, name := range in {
+		f, err := parseFormatter(name)
+		if err != nil {
+			return nil, err
+		}
+
+		out[dest] = f
+	}
+
+	return out, nil
+}
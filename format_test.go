@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatters(t *testing.T) {
+	cases := []struct {
+		name   string
+		f      formatter
+		colour string
+		stat   string
+		link   string
+		lines  string
+	}{
+		{
+			name:   "irc",
+			f:      ircFormatter{},
+			colour: "{green}game{clear}",
+			stat:   "{yellow}1/2{clear}",
+			link:   "https://example.com",
+			lines:  "a | b",
+		},
+		{
+			name:   "discord",
+			f:      discordFormatter{},
+			colour: "**game**",
+			stat:   "`1/2`",
+			link:   "<https://example.com>",
+			lines:  "a\nb",
+		},
+		{
+			name:   "html",
+			f:      htmlFormatter{},
+			colour: `<font color="#00aa00" data-mx-color="#00aa00">game</font>`,
+			stat:   `<font color="#ccaa00" data-mx-color="#ccaa00">1/2</font>`,
+			link:   `<a href="https://example.com">https://example.com</a>`,
+			lines:  "a<br>b",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.colour, tc.f.Render(tc.f.Colour("green", "game")))
+			assert.Equal(t, tc.stat, tc.f.Render(tc.f.Stat("yellow", "1/2")))
+			assert.Equal(t, tc.link, tc.f.Render(tc.f.Link("https://example.com")))
+			assert.Equal(t, tc.lines, tc.f.Render(tc.f.Lines([]string{"a", "b"})))
+		})
+	}
+}
+
+func TestHtmlFormatterEscapes(t *testing.T) {
+	f := htmlFormatter{}
+
+	assert.Equal(t, `<font color="#cc0000" data-mx-color="#cc0000">Tom &amp; Jerry</font>`, f.Render(f.Colour("red", "Tom & Jerry")))
+	assert.Equal(t, "&lt;b&gt;", f.Render(f.Colour("unknown", "<b>")))
+	assert.Equal(t, `<a href="https://example.com/?a=1&amp;b=%22x%22">https://example.com/?a=1&amp;b="x"</a>`, f.Render(f.Link(`https://example.com/?a=1&b="x"`)))
+}
+
+func TestHtmlFormatterRendersMessages(t *testing.T) {
+	s := testSettings
+	s.formatter = htmlFormatter{}
+
+	r := &lastAchievementResult{
+		User:        "<script>x</script>",
+		Found:       true,
+		Game:        "Tom & Jerry",
+		Name:        "A<b>",
+		Description: s.msg("hidden_achievement"),
+		Achieved:    1,
+		Total:       2,
+		UnlockTime:  1638316294,
+	}
+
+	out := s.formatter.Render(s.renderLastAchievement(context.Background(), r, nil))
+
+	assert.Equal(t, `&lt;script&gt;x&lt;/script&gt;'s last steam achievement: Tom &amp; Jerry - A&lt;b&gt; (&lt;hidden achievement&gt;) (<font color="#ccaa00" data-mx-color="#ccaa00">1/2</font>) (unlocked 2021-11-30 23:51 UTC)`, out)
+}
+
+func TestParseDestFormatters(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     map[string]string
+		out    map[string]formatter
+		errMsg string
+	}{
+		{
+			name: "No destinations",
+			in:   map[string]string{},
+			out:  map[string]formatter{},
+		},
+		{
+			name: "Valid destinations",
+			in:   map[string]string{"#a": "discord", "#b": "IRC"},
+			out:  map[string]formatter{"#a": discordFormatter{}, "#b": ircFormatter{}},
+		},
+		{
+			name:   "Unknown format",
+			in:     map[string]string{"#a": "telegram"},
+			errMsg: "unknown format telegram",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := parseDestFormatters(tc.in)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.out, out)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
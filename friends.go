@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	friendListPath    = "/ISteamUser/GetFriendList/v1/?key=%s&steamid=%s&relationship=friend"
+	maxFriendsPlaying = 10
+)
+
+type friendListRes struct {
+	FriendsList struct {
+		Friends []struct {
+			SteamId string
+		}
+	}
+}
+
+func getFriendList(ctx context.Context, apiKey string, id SteamID, client *steamClient) ([]string, error) {
+	j, err := getJSON[friendListRes](ctx, client.apiUrl(friendListPath, apiKey, id), id.String(), client)
+	if errors.Is(err, ErrInvalidKey) {
+		// private friend lists are also a 401, so check the key works elsewhere
+		if _, perr := getPlayerSummaries(ctx, apiKey, id.String(), client); perr != nil {
+			return nil, err
+		}
+		return nil, ErrProfilePrivate
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, f := range j.FriendsList.Friends {
+		ids = append(ids, f.SteamId)
+	}
+
+	return ids, nil
+}
+
+func getFriendSummaries(ctx context.Context, apiKey string, ids []string, client *steamClient) ([]playerSummary, error) {
+	summaries := []playerSummary{}
+
+	for start := 0; start < len(ids); start += maxSummaryIds {
+		end := start + maxSummaryIds
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		res, err := getPlayerSummaries(ctx, apiKey, strings.Join(ids[start:end], ","), client)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ps := range res.Response.Players {
+			client.cache.SetWithTTL(summaryCacheKey(ps.SteamId), ps, summaryCacheTTL)
+			summaries = append(summaries, ps)
+		}
+	}
+
+	return summaries, nil
+}
+
+func friendsInGame(ctx context.Context, apiKey string, id SteamID, client *steamClient) ([]playerSummary, error) {
+	ids, err := getFriendList(ctx, apiKey, id, client)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := getFriendSummaries(ctx, apiKey, ids, client)
+	if err != nil {
+		return nil, err
+	}
+
+	friends := make(map[string]bool)
+	for _, f := range ids {
+		friends[f] = true
+	}
+
+	playing := []playerSummary{}
+	for _, ps := range summaries {
+		if friends[ps.SteamId] && ps.GameExtraInfo != "" {
+			playing = append(playing, ps)
+		}
+	}
+
+	sort.Slice(playing, func(i, j int) bool {
+		return strings.ToLower(playing[i].PersonaName) < strings.ToLower(playing[j].PersonaName)
+	})
+
+	return playing, nil
+}
+
+func friendsPlaying(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
+	if err != nil {
+		return "", err
+	}
+
+	playing, err := friendsInGame(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	name := s.displayName(ctx, apiKey, id, user, client)
+	if len(playing) == 0 {
+		return s.msg("friends_none_playing", name), nil
+	}
+
+	games := []string{}
+	for _, ps := range playing {
+		games = append(games, ps.GameExtraInfo)
+	}
+	games = s.colourList(games)
+
+	items := []string{}
+	for n, ps := range playing {
+		if n == maxFriendsPlaying {
+			break
+		}
+
+		items = append(items, fmt.Sprintf("%s (%s)", ps.PersonaName, games[n]))
+	}
+
+	out := s.msg("friends_playing", name, strings.Join(items, ", "))
+	if len(playing) > maxFriendsPlaying {
+		out = fmt.Sprintf("%s %s", out, s.msg("friends_more", len(playing)-maxFriendsPlaying))
+	}
+
+	return out, nil
+}
+
+func friendsPlayingHandler(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	out, err := friendsPlaying(ctx, apiKey, user, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("friends_private", user), nil
+	}
+
+	return out, err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFriendsPlayingHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		user     string
+		endpoint string
+		fault    mocksteam.Fault
+		probe    mocksteam.Fault
+		out      string
+		err      error
+	}{
+		{
+			name: "Friends in game",
+			user: "gaben",
+			out:  "gaben's friends in game: Doug ({green}Portal 2{clear}), robin ({red}Half-Life{clear})",
+		},
+		{
+			name: "No friends in game",
+			user: "76561197960287931",
+			out:  "none of 76561197960287931's friends are in game",
+		},
+		{
+			name: "Unknown user",
+			user: "nobody",
+			out:  "Error: no id found for nobody",
+		},
+		{
+			name:     "Private friends list",
+			user:     "gaben",
+			endpoint: "friends",
+			fault:    mocksteam.Private,
+			out:      "Error: gaben's friends list is not public",
+		},
+		{
+			name:     "Invalid key",
+			user:     "76561197960287930",
+			endpoint: "friends",
+			fault:    mocksteam.Private,
+			probe:    mocksteam.Private,
+			err:      ErrInvalidKey,
+		},
+		{
+			name:     "Summaries rate limited",
+			user:     "gaben",
+			endpoint: "summaries",
+			fault:    mocksteam.RateLimited,
+			err:      ErrRateLimited,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject(tc.endpoint, tc.fault)
+			if tc.probe != mocksteam.NoFault {
+				ms.Inject("summaries", tc.probe)
+			}
+
+			out, err := friendsPlayingHandler(context.Background(), "key", tc.user, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestGetFriendSummariesBatches(t *testing.T) {
+	ids := []string{}
+	for n := 0; n < maxSummaryIds+1; n++ {
+		ids = append(ids, "1")
+	}
+
+	requests := 0
+	f := func(req *http.Request) *http.Response {
+		requests++
+		return NewTestClient(200, `{"response":{"players":[]}}`).httpClient.Transport.(RoundTripFunc)(req)
+	}
+	client := newTestSteamClient(&http.Client{Transport: RoundTripFunc(f)})
+
+	_, err := getFriendSummaries(context.Background(), "key", ids, client)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, requests)
+}
@@ -0,0 +1,229 @@
+package main
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/steam/v1/steam.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	steamv1 "github.com/gowon-irc/gowon-steam/proto/steam/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const grpcActor = "grpc"
+
+var grpcAuthMethods = map[string]bool{
+	steamv1.SteamService_GetUser_FullMethodName: true,
+	steamv1.SteamService_SetUser_FullMethodName: true,
+}
+
+var grpcErrorCodes = map[error]codes.Code{
+	ErrProfileNotFound:       codes.NotFound,
+	ErrInvalidSteamID:        codes.NotFound,
+	ErrProfilePrivate:        codes.PermissionDenied,
+	ErrRateLimited:           codes.ResourceExhausted,
+	dailyBudgetErr:           codes.ResourceExhausted,
+	circuitOpenErr:           codes.Unavailable,
+	ErrSteamAPI:              codes.Unavailable,
+	context.DeadlineExceeded: codes.DeadlineExceeded,
+}
+
+type grpcServer struct {
+	steamv1.UnimplementedSteamServiceServer
+
+	token       string
+	apiKey      string
+	kv          Store
+	client      *steamClient
+	defaults    settings
+	timeout     time.Duration
+	retryBudget int
+}
+
+func grpcError(err error) error {
+	for e, code := range grpcErrorCodes {
+		if errors.Is(err, e) {
+			return status.Error(code, sanitiseError(err))
+		}
+	}
+
+	return status.Error(codes.Internal, sanitiseError(err))
+}
+
+func (g *grpcServer) authorise(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !grpcAuthMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		got := strings.TrimPrefix(v, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(g.token)) == 1 {
+			return handler(ctx, req)
+		}
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "invalid token")
+}
+
+func (g *grpcServer) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	return withRetryBudget(ctx, g.retryBudget), cancel
+}
+
+func (g *grpcServer) Recent(ctx context.Context, req *steamv1.RecentRequest) (*steamv1.RecentReply, error) {
+	if req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	ctx, cancel := g.context(ctx)
+	defer cancel()
+
+	s := g.defaults
+	if req.GetCount() > 0 {
+		s = s.withModifiers([]string{"--n=" + strconv.Itoa(int(req.GetCount()))})
+	}
+
+	atomic.AddUint64(&commandsServed, 1)
+
+	r, err := fetchRecentGames(ctx, g.apiKey, req.GetUser(), g.client, s)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	reply := &steamv1.RecentReply{User: r.User, Link: r.Link, Partial: r.Partial}
+	for _, rg := range r.Games {
+		reply.Games = append(reply.Games, &steamv1.RecentGame{
+			Appid:    int32(rg.AppId),
+			Name:     rg.Name,
+			Hours:    rg.Hours,
+			Achieved: int32(rg.Achieved),
+			Total:    int32(rg.Total),
+		})
+	}
+
+	return reply, nil
+}
+
+func (g *grpcServer) Achievement(ctx context.Context, req *steamv1.AchievementRequest) (*steamv1.AchievementReply, error) {
+	if req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	ctx, cancel := g.context(ctx)
+	defer cancel()
+
+	atomic.AddUint64(&commandsServed, 1)
+
+	r, err := fetchLastAchievement(ctx, g.apiKey, req.GetUser(), g.client, g.defaults)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	reply := &steamv1.AchievementReply{
+		User:        r.User,
+		Found:       r.Found,
+		Game:        r.Game,
+		Appid:       int32(r.AppId),
+		Name:        r.Name,
+		Description: r.Description,
+		Achieved:    int32(r.Achieved),
+		Total:       int32(r.Total),
+		UnlockTime:  int64(r.UnlockTime),
+		Link:        r.Link,
+		Partial:     r.Partial,
+	}
+	if r.HasRarity {
+		reply.Rarity = &r.Rarity
+	}
+
+	return reply, nil
+}
+
+func (g *grpcServer) GetUser(ctx context.Context, req *steamv1.GetUserRequest) (*steamv1.GetUserReply, error) {
+	if req.GetNick() == "" {
+		return nil, status.Error(codes.InvalidArgument, "nick is required")
+	}
+
+	user, err := getUser(g.kv, []byte(req.GetNick()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(user) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no user set for %s", req.GetNick())
+	}
+
+	return &steamv1.GetUserReply{Nick: req.GetNick(), User: string(user)}, nil
+}
+
+func (g *grpcServer) SetUser(ctx context.Context, req *steamv1.SetUserRequest) (*steamv1.SetUserReply, error) {
+	if req.GetNick() == "" || req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "nick and user are required")
+	}
+
+	nick, user := req.GetNick(), normaliseSteamUser(req.GetUser())
+
+	old, err := getUser(g.kv, []byte(nick))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := setUser(g.kv, []byte(nick), []byte(user)); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = recordAudit(g.kv, auditEntry{Time: wallClock.Now(), Actor: grpcActor, Action: "set", Nick: nick, Old: string(old), New: user})
+	if err != nil {
+		log.Printf("couldn't record audit entry: %s\n", err)
+	}
+
+	return &steamv1.SetUserReply{Nick: nick, User: user}, nil
+}
+
+func serveGRPC(addr string, g *grpcServer) (*grpc.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(g.authorise))
+	steamv1.RegisterSteamServiceServer(srv, g)
+
+	go func() {
+		log.Printf("grpc server listening on %s\n", lis.Addr())
+		if err := srv.Serve(lis); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	return srv, nil
+}
+
+func shutdownGRPC(srv *grpc.Server) {
+	if srv == nil {
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(apiShutdownTimeout):
+		srv.Stop()
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	steamv1 "github.com/gowon-irc/gowon-steam/proto/steam/v1"
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func dialTestGRPC(t *testing.T, kv Store, sc *steamClient) steamv1.SteamServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	g := &grpcServer{token: "secret", apiKey: "key", kv: kv, client: sc, defaults: testSettings, timeout: time.Second}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(g.authorise))
+	steamv1.RegisterSteamServiceServer(srv, g)
+	go srv.Serve(lis)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+
+	return steamv1.NewSteamServiceClient(conn)
+}
+
+func TestGRPCRecent(t *testing.T) {
+	cases := []struct {
+		name     string
+		req      *steamv1.RecentRequest
+		endpoint string
+		fault    mocksteam.Fault
+		games    []string
+		code     codes.Code
+	}{
+		{
+			name:  "Recent games",
+			req:   &steamv1.RecentRequest{User: "gaben"},
+			games: []string{"Portal 2", "Portal"},
+			code:  codes.OK,
+		},
+		{
+			name:  "With count",
+			req:   &steamv1.RecentRequest{User: "gaben", Count: 1},
+			games: []string{"Portal 2"},
+			code:  codes.OK,
+		},
+		{
+			name: "Missing user",
+			req:  &steamv1.RecentRequest{},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "Unknown user",
+			req:  &steamv1.RecentRequest{User: "nobody"},
+			code: codes.NotFound,
+		},
+		{
+			name:     "Rate limited",
+			req:      &steamv1.RecentRequest{User: "gaben"},
+			endpoint: "recent",
+			fault:    mocksteam.RateLimited,
+			code:     codes.ResourceExhausted,
+		},
+		{
+			name:     "Steam error",
+			req:      &steamv1.RecentRequest{User: "gaben"},
+			endpoint: "recent",
+			fault:    mocksteam.ServerError,
+			code:     codes.Unavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, sc := useMockSteam(t)
+			ms.Inject(tc.endpoint, tc.fault)
+			client := dialTestGRPC(t, openTestDB(t), sc)
+
+			reply, err := client.Recent(context.Background(), tc.req)
+			assert.Equal(t, tc.code, status.Code(err))
+
+			games := []string{}
+			for _, g := range reply.GetGames() {
+				games = append(games, g.GetName())
+			}
+			if tc.games != nil {
+				assert.Equal(t, tc.games, games)
+			}
+		})
+	}
+}
+
+func TestGRPCAchievement(t *testing.T) {
+	_, sc := useMockSteam(t)
+	client := dialTestGRPC(t, openTestDB(t), sc)
+
+	reply, err := client.Achievement(context.Background(), &steamv1.AchievementRequest{User: "gaben"})
+	assert.Nil(t, err)
+	assert.True(t, reply.GetFound())
+	assert.Equal(t, "Portal 2", reply.GetGame())
+	assert.Equal(t, "Wake Up Call", reply.GetName())
+	assert.Equal(t, 87.5, reply.GetRarity())
+	assert.Equal(t, int32(2), reply.GetAchieved())
+	assert.Equal(t, int32(3), reply.GetTotal())
+}
+
+func TestGRPCUsers(t *testing.T) {
+	kv := openTestDB(t)
+	client := dialTestGRPC(t, kv, testClient)
+
+	_, err := client.GetUser(context.Background(), &steamv1.GetUserRequest{Nick: "nick1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	wrong := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	_, err = client.SetUser(wrong, &steamv1.SetUserRequest{Nick: "nick1", User: "gaben"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+
+	_, err = client.GetUser(ctx, &steamv1.GetUserRequest{Nick: "nick1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.SetUser(ctx, &steamv1.SetUserRequest{Nick: "nick1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	set, err := client.SetUser(ctx, &steamv1.SetUserRequest{Nick: "nick1", User: "https://steamcommunity.com/id/gaben"})
+	assert.Nil(t, err)
+	assert.Equal(t, "gaben", set.GetUser())
+
+	got, err := client.GetUser(ctx, &steamv1.GetUserRequest{Nick: "nick1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "gaben", got.GetUser())
+
+	entries, err := recentAudit(kv, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, grpcActor, entries[0].Actor)
+}
+
+func TestGRPCError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{
+			name: "Profile not found",
+			err:  &apiError{endpoint: "ISteamUser/ResolveVanityURL/v1", user: "nobody", err: ErrProfileNotFound},
+			code: codes.NotFound,
+		},
+		{
+			name: "Private profile",
+			err:  ErrProfilePrivate,
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "Steam error",
+			err:  ErrSteamAPI,
+			code: codes.Unavailable,
+		},
+		{
+			name: "Unknown error",
+			err:  errors.New("unexpected end of JSON input"),
+			code: codes.Internal,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, status.Code(grpcError(tc.err)))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+type readinessReply struct {
+	Ready  bool            `json:"ready"`
+	Checks map[string]bool `json:"checks"`
+}
+
+type readiness struct {
+	brokerConnected func() bool
+	kv              Store
+	keysValid       bool
+}
+
+func kvOpen(kv Store) bool {
+	return kv != nil && kv.Ping() == nil
+}
+
+func (r readiness) check() readinessReply {
+	checks := map[string]bool{
+		"broker":  r.brokerConnected(),
+		"kv":      kvOpen(r.kv),
+		"api_key": r.keysValid,
+	}
+
+	ready := true
+	for _, ok := range checks {
+		ready = ready && ok
+	}
+
+	return readinessReply{Ready: ready, Checks: checks}
+}
+
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (r readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	reply := r.check()
+
+	status := http.StatusOK
+	if !reply.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, reply)
+}
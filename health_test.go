@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	healthzHandler(w, httptest.NewRequest(http.MethodGet, healthzPath, nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	cases := []struct {
+		name      string
+		connected bool
+		closeKV   bool
+		keysValid bool
+		status    int
+		body      string
+	}{
+		{
+			name:      "Ready",
+			connected: true,
+			keysValid: true,
+			status:    http.StatusOK,
+			body:      `{"ready":true,"checks":{"broker":true,"kv":true,"api_key":true}}`,
+		},
+		{
+			name:      "Broker disconnected",
+			connected: false,
+			keysValid: true,
+			status:    http.StatusServiceUnavailable,
+			body:      `{"ready":false,"checks":{"broker":false,"kv":true,"api_key":true}}`,
+		},
+		{
+			name:      "KV closed",
+			connected: true,
+			closeKV:   true,
+			keysValid: true,
+			status:    http.StatusServiceUnavailable,
+			body:      `{"ready":false,"checks":{"broker":true,"kv":false,"api_key":true}}`,
+		},
+		{
+			name:      "Invalid key",
+			connected: true,
+			keysValid: false,
+			status:    http.StatusServiceUnavailable,
+			body:      `{"ready":false,"checks":{"broker":true,"kv":true,"api_key":false}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kv := openTestDB(t)
+			if tc.closeKV {
+				kv.Close()
+			}
+
+			r := readiness{brokerConnected: func() bool { return tc.connected }, kv: kv, keysValid: tc.keysValid}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, readyzPath, nil))
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.JSONEq(t, tc.body, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type playtimeResult struct {
+	Game    string
+	Minutes int
+}
+
+func findFriend(ctx context.Context, apiKey string, id SteamID, name string, client *steamClient) (string, bool, error) {
+	ids, err := getFriendList(ctx, apiKey, id, client)
+	if err != nil {
+		return "", false, err
+	}
+
+	summaries, err := getFriendSummaries(ctx, apiKey, ids, client)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, ps := range summaries {
+		if strings.EqualFold(ps.PersonaName, name) {
+			return ps.SteamId, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+func userPlaytime(ctx context.Context, apiKey, user string, appId int, client *steamClient) (playtimeResult, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
+	if err != nil {
+		return playtimeResult{}, err
+	}
+
+	owned, err := cachedOwnedGames(ctx, apiKey, id, client)
+	if err != nil {
+		return playtimeResult{}, err
+	}
+
+	g, _ := owned.Game(appId)
+
+	return playtimeResult{Game: g.Name, Minutes: g.PlaytimeForever}, nil
+}
+
+func (s settings) formatHoursVerdict(caller, opponent string, mine, theirs int) string {
+	diff := float64(mine-theirs) / 60
+
+	switch {
+	case mine > theirs:
+		return s.formatter.Colour("green", s.msg("hours_ahead", caller, diff))
+	case mine < theirs:
+		return s.formatter.Colour("red", s.msg("hours_ahead", opponent, -diff))
+	}
+
+	return s.formatter.Colour("yellow", s.msg("hours_tied"))
+}
+
+func compareHours(ctx context.Context, apiKey string, kv Store, nick, caller string, args []string, client *steamClient, s settings) (string, error) {
+	opponent := args[len(args)-1]
+	query := strings.Join(args[:len(args)-1], " ")
+
+	appId, game, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	callerId, err := resolveSteamID(ctx, apiKey, caller, client)
+	if err != nil {
+		return "", err
+	}
+
+	other, err := linkedUser(kv, opponent, "")
+	if err != nil {
+		return "", err
+	}
+
+	if other == "" {
+		friend, found, err := findFriend(ctx, apiKey, callerId, opponent, client)
+		if err != nil && !errors.Is(err, ErrProfilePrivate) {
+			return "", err
+		}
+
+		if !found {
+			return s.msg("hours_unknown_opponent", opponent), nil
+		}
+
+		other = friend
+	}
+
+	mine, err := userPlaytime(ctx, apiKey, caller, appId, client)
+	if err != nil {
+		return "", err
+	}
+
+	theirs, err := userPlaytime(ctx, apiKey, other, appId, client)
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("hours_private", opponent), nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, g := range []string{mine.Game, theirs.Game, game, query} {
+		if g != "" {
+			game = g
+			break
+		}
+	}
+
+	verdict := s.formatHoursVerdict(nick, opponent, mine.Minutes, theirs.Minutes)
+
+	return s.msg("hours", game, nick, float64(mine.Minutes)/60, opponent, float64(theirs.Minutes)/60, verdict), nil
+}
+
+func hoursHandler(ctx context.Context, apiKey string, kv Store, nick string, args []string, client *steamClient, s settings) (string, error) {
+	if len(args) < 2 {
+		return s.msg("hours_needed"), nil
+	}
+
+	caller, err := linkedUser(kv, nick, "")
+	if err != nil {
+		return "", err
+	}
+
+	if caller == "" {
+		return s.msg("username_needed"), nil
+	}
+
+	out, err := compareHours(ctx, apiKey, kv, nick, caller, args, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", caller), nil
+	}
+
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("profile_not_public"), nil
+	}
+
+	return out, err
+}
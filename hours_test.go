@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoursHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		nick     string
+		args     []string
+		endpoint string
+		fault    mocksteam.Fault
+		out      string
+		err      error
+	}{
+		{
+			name: "Missing opponent",
+			nick: "me",
+			args: []string{"620"},
+			out:  "Error: game and nick or friend needed",
+		},
+		{
+			name: "Caller not linked",
+			nick: "stranger",
+			args: []string{"620", "bob"},
+			out:  "Error: username needed",
+		},
+		{
+			name: "Ahead of a linked nick",
+			nick: "me",
+			args: []string{"400", "bob"},
+			out:  "Portal - me 2.0h vs bob 0.0h - {green}me leads by 2.0h{clear}",
+		},
+		{
+			name: "Tied with a linked nick",
+			nick: "me",
+			args: []string{"620", "bob"},
+			out:  "Portal 2 - me 40.9h vs bob 40.9h - {yellow}dead even{clear}",
+		},
+		{
+			name: "Not one of the caller's friends",
+			nick: "bob",
+			args: []string{"400", "RABSCUTTLE"},
+			out:  "Error: RABSCUTTLE isn't a linked nick or one of your steam friends",
+		},
+		{
+			name: "Against a steam friend",
+			nick: "me",
+			args: []string{"620", "doug"},
+			out:  "Portal 2 - me 40.9h vs doug 0.0h - {green}me leads by 40.9h{clear}",
+		},
+		{
+			name: "Behind",
+			nick: "bob",
+			args: []string{"400", "me"},
+			out:  "Portal - bob 0.0h vs me 2.0h - {red}me leads by 2.0h{clear}",
+		},
+		{
+			name: "Unknown opponent",
+			nick: "me",
+			args: []string{"620", "nobody"},
+			out:  "Error: nobody isn't a linked nick or one of your steam friends",
+		},
+		{
+			name: "Unknown game",
+			nick: "me",
+			args: []string{"zzzz", "bob"},
+			out:  "Error: unknown game zzzz",
+		},
+		{
+			name:     "Private library",
+			nick:     "me",
+			args:     []string{"620", "bob"},
+			endpoint: "owned",
+			fault:    mocksteam.Private,
+			out:      "Error: profile is not public",
+		},
+		{
+			name:     "Rate limited",
+			nick:     "me",
+			args:     []string{"620", "bob"},
+			endpoint: "owned",
+			fault:    mocksteam.RateLimited,
+			err:      ErrRateLimited,
+		},
+	}
+
+	kv := openTestDB(t)
+	assert.Nil(t, setUser(kv, []byte("me"), []byte("gaben")))
+	assert.Nil(t, setUser(kv, []byte("bob"), []byte("76561197960287932")))
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject(tc.endpoint, tc.fault)
+
+			out, err := hoursHandler(context.Background(), "key", kv, tc.nick, tc.args, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
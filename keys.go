@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func parseAPIKeys(in string) []string {
+	keys := []string{}
+
+	for _, k := range strings.Split(in, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+func resolveAPIKey(opts Options) (string, error) {
+	key := opts.APIKey
+
+	if opts.APIKeyFile != "" {
+		if key != "" {
+			return "", errors.New("only one of api-key and api-key-file can be set")
+		}
+
+		b, err := ioutil.ReadFile(opts.APIKeyFile)
+		if err != nil {
+			return "", err
+		}
+
+		key = strings.ReplaceAll(strings.TrimSpace(string(b)), "\n", ",")
+	}
+
+	if len(parseAPIKeys(key)) == 0 {
+		return "", errors.New("an api key is required, set api-key or api-key-file")
+	}
+
+	return key, nil
+}
+
+const probeSteamId = "76561197960435530"
+
+func checkAPIKeys(ctx context.Context, keys []string, client *steamClient) error {
+	for n, k := range keys {
+		_, err := getPlayerSummaries(ctx, k, probeSteamId, client)
+		if err != nil {
+			return fmt.Errorf("api key %d of %d failed validation: %w", n+1, len(keys), err)
+		}
+	}
+
+	return nil
+}
+
+type keyRing struct {
+	mu       sync.Mutex
+	keys     []string
+	next     int
+	budgets  []*dailyBudget
+	benched  []time.Time
+	cooldown time.Duration
+}
+
+func newKeyRing(keys []string, dailyLimit int, cooldown time.Duration) *keyRing {
+	kr := &keyRing{
+		keys:     keys,
+		budgets:  make([]*dailyBudget, len(keys)),
+		benched:  make([]time.Time, len(keys)),
+		cooldown: cooldown,
+	}
+
+	for n := range kr.budgets {
+		kr.budgets[n] = &dailyBudget{name: fmt.Sprintf("api key %d", n+1), limit: dailyLimit}
+	}
+
+	return kr
+}
+
+func (kr *keyRing) take(now time.Time) (int, bool) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	fallback := -1
+
+	for i := range kr.keys {
+		n := (kr.next + i) % len(kr.keys)
+
+		if now.Before(kr.benched[n]) {
+			if fallback < 0 {
+				fallback = n
+			}
+			continue
+		}
+
+		if !kr.budgets[n].take(now) {
+			continue
+		}
+
+		kr.next = n + 1
+		return n, true
+	}
+
+	if fallback < 0 {
+		return 0, false
+	}
+
+	kr.next = fallback + 1
+	return fallback, true
+}
+
+func (kr *keyRing) bench(n int, now time.Time) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	kr.benched[n] = now.Add(kr.cooldown)
+}
+
+type keyTransport struct {
+	next  http.RoundTripper
+	hosts map[string]bool
+	ring  *keyRing
+}
+
+func (kt *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	if !kt.hosts[req.URL.Host] || q.Get("key") == "" {
+		return kt.next.RoundTrip(req)
+	}
+
+	n, ok := kt.ring.take(time.Now())
+	if !ok {
+		return nil, dailyBudgetErr
+	}
+
+	q.Set("key", kt.ring.keys[n])
+
+	r := req.Clone(req.Context())
+	r.URL.RawQuery = q.Encode()
+
+	res, err := kt.next.RoundTrip(r)
+	if err == nil && (res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusForbidden) {
+		kt.ring.bench(n, time.Now())
+	}
+
+	return res, err
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		out  []string
+	}{
+		{
+			name: "Single key",
+			in:   "abc",
+			out:  []string{"abc"},
+		},
+		{
+			name: "Several keys",
+			in:   "abc, def,ghi",
+			out:  []string{"abc", "def", "ghi"},
+		},
+		{
+			name: "Empty entries",
+			in:   "abc,,",
+			out:  []string{"abc"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, parseAPIKeys(tc.in))
+		})
+	}
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "key")
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("abc\ndef\n"), 0600))
+
+	cases := []struct {
+		name   string
+		opts   Options
+		out    string
+		errMsg string
+	}{
+		{
+			name: "Key",
+			opts: Options{APIKey: "abc"},
+			out:  "abc",
+		},
+		{
+			name: "Key file",
+			opts: Options{APIKeyFile: fp},
+			out:  "abc,def",
+		},
+		{
+			name:   "Both",
+			opts:   Options{APIKey: "abc", APIKeyFile: fp},
+			errMsg: "only one of api-key and api-key-file can be set",
+		},
+		{
+			name:   "Neither",
+			opts:   Options{},
+			errMsg: "an api key is required, set api-key or api-key-file",
+		},
+		{
+			name:   "Missing file",
+			opts:   Options{APIKeyFile: filepath.Join(t.TempDir(), "missing")},
+			errMsg: "no such file or directory",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := resolveAPIKey(tc.opts)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestKeyRingRoundRobin(t *testing.T) {
+	kr := newKeyRing([]string{"a", "b", "c"}, 0, time.Minute)
+	now := time.Now()
+
+	out := []int{}
+	for i := 0; i < 4; i++ {
+		n, ok := kr.take(now)
+		assert.True(t, ok)
+		out = append(out, n)
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 0}, out)
+}
+
+func TestKeyRingBench(t *testing.T) {
+	kr := newKeyRing([]string{"a", "b"}, 0, time.Minute)
+	now := time.Now()
+
+	kr.bench(0, now)
+
+	n, _ := kr.take(now)
+	assert.Equal(t, 1, n)
+	n, _ = kr.take(now)
+	assert.Equal(t, 1, n)
+
+	kr.bench(1, now)
+	n, ok := kr.take(now)
+	assert.True(t, ok)
+	assert.Equal(t, 0, n)
+
+	n, _ = kr.take(now.Add(2 * time.Minute))
+	assert.Equal(t, 1, n)
+}
+
+func TestKeyRingBudget(t *testing.T) {
+	kr := newKeyRing([]string{"a", "b"}, 1, time.Minute)
+	now := time.Now()
+
+	_, ok := kr.take(now)
+	assert.True(t, ok)
+	_, ok = kr.take(now)
+	assert.True(t, ok)
+	_, ok = kr.take(now)
+	assert.False(t, ok)
+}
+
+func TestKeyTransport(t *testing.T) {
+	seen := []string{}
+	f := func(req *http.Request) *http.Response {
+		key := req.URL.Query().Get("key")
+		seen = append(seen, key)
+
+		status := 200
+		if key == "a" {
+			status = 429
+		}
+
+		return NewTestClient(status, "").httpClient.Transport.(RoundTripFunc)(req)
+	}
+
+	kt := &keyTransport{
+		next:  RoundTripFunc(f),
+		hosts: map[string]bool{"api.steampowered.com": true},
+		ring:  newKeyRing([]string{"a", "b"}, 0, time.Minute),
+	}
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/?key=a&steamid=1", nil)
+		_, err := kt.RoundTrip(req)
+		assert.Nil(t, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://store.steampowered.com/app/1", nil)
+	_, err := kt.RoundTrip(req)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"a", "b", "b", ""}, seen)
+}
+
+func TestCheckAPIKeys(t *testing.T) {
+	f := func(req *http.Request) *http.Response {
+		status := 200
+		if req.URL.Query().Get("key") == "bad" {
+			status = 403
+		}
+
+		return NewTestClient(status, `{"response":{"players":[]}}`).httpClient.Transport.(RoundTripFunc)(req)
+	}
+	client := newTestSteamClient(&http.Client{Transport: RoundTripFunc(f)})
+
+	cases := []struct {
+		name   string
+		keys   []string
+		errMsg string
+	}{
+		{
+			name: "Valid keys",
+			keys: []string{"a", "b"},
+		},
+		{
+			name:   "Invalid key",
+			keys:   []string{"a", "bad"},
+			errMsg: "api key 2 of 2 failed validation: ISteamUser/GetPlayerSummaries/v2 for 76561197960435530: invalid API key",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkAPIKeys(context.Background(), tc.keys, client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tc.errMsg)
+				assert.ErrorIs(t, err, ErrInvalidKey)
+			}
+		})
+	}
+}
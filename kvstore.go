@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Bucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	ForEach(fn func(k, v []byte) error) error
+	NextSequence() (uint64, error)
+	Clear() (int, error)
+}
+
+type Store interface {
+	View(bucket []byte, fn func(b Bucket) error) error
+	Update(bucket []byte, fn func(b Bucket) error) error
+	Ping() error
+	Close() error
+}
+
+type storeDriver func(dsn string) (Store, error)
+
+var storeDrivers = map[string]storeDriver{}
+
+func registerStoreDriver(name string, driver storeDriver) {
+	if _, ok := storeDrivers[name]; ok {
+		panic(fmt.Sprintf("store driver %s registered twice", name))
+	}
+
+	storeDrivers[name] = driver
+}
+
+func storeDriverNames() string {
+	names := []string{}
+	for n := range storeDrivers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func checkStoreDriver(name string) error {
+	if _, ok := storeDrivers[name]; !ok {
+		return fmt.Errorf("unknown kv driver %s, must be one of %s", name, storeDriverNames())
+	}
+
+	return nil
+}
+
+func openStore(name, dsn string) (Store, error) {
+	if err := checkStoreDriver(name); err != nil {
+		return nil, err
+	}
+
+	return storeDrivers[name](dsn)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreDrivers(t *testing.T) {
+	bucket := []byte("test")
+
+	for _, driver := range []string{"bolt", "memory", "redis", "sqlite"} {
+		t.Run(driver, func(t *testing.T) {
+			dsn := filepath.Join(t.TempDir(), "test.db")
+			if driver == "redis" {
+				dsn = "redis://" + miniredis.RunT(t).Addr()
+			}
+
+			kv, err := openStore(driver, dsn)
+			assert.Nil(t, err)
+			defer kv.Close()
+
+			assert.Nil(t, kv.Ping())
+
+			called := false
+			err = kv.View(bucket, func(b Bucket) error {
+				called = true
+				return nil
+			})
+			assert.Nil(t, err)
+			assert.False(t, called)
+
+			err = kv.Update(bucket, func(b Bucket) error {
+				for _, k := range []string{"b", "c", "a"} {
+					if err := b.Put([]byte(k), []byte(k+k)); err != nil {
+						return err
+					}
+				}
+				return b.Delete([]byte("c"))
+			})
+			assert.Nil(t, err)
+
+			keys := []string{}
+			err = kv.View(bucket, func(b Bucket) error {
+				assert.Equal(t, []byte("aa"), b.Get([]byte("a")))
+				assert.Nil(t, b.Get([]byte("c")))
+
+				return b.ForEach(func(k, v []byte) error {
+					keys = append(keys, string(k))
+					return nil
+				})
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"a", "b"}, keys)
+
+			err = kv.Update(bucket, func(b Bucket) error {
+				first, _ := b.NextSequence()
+				second, _ := b.NextSequence()
+				assert.Equal(t, first+1, second)
+
+				n, err := b.Clear()
+				assert.Equal(t, 2, n)
+				return err
+			})
+			assert.Nil(t, err)
+
+			err = kv.View(bucket, func(b Bucket) error {
+				assert.Nil(t, b.Get([]byte("a")))
+				return nil
+			})
+			assert.Nil(t, err)
+
+			failed := errors.New("failed")
+			err = kv.Update(bucket, func(b Bucket) error {
+				if err := b.Put([]byte("a"), []byte("aa")); err != nil {
+					return err
+				}
+				return failed
+			})
+			assert.ErrorIs(t, err, failed)
+
+			err = kv.View(bucket, func(b Bucket) error {
+				assert.Nil(t, b.Get([]byte("a")), "failed updates are rolled back")
+				return nil
+			})
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestOpenStoreUnknownDriver(t *testing.T) {
+	_, err := openStore("mongo", "")
+	assert.EqualError(t, err, "unknown kv driver mongo, must be one of bolt, memory, redis, sqlite")
+}
+
+func TestRegisterStoreDriverTwice(t *testing.T) {
+	assert.Panics(t, func() { registerStoreDriver("bolt", openBoltStore) })
+}
+
+func TestMemStoreClosed(t *testing.T) {
+	kv, _ := openStore("memory", "")
+	kv.Close()
+
+	assert.ErrorIs(t, kv.Ping(), storeClosedErr)
+	assert.ErrorIs(t, kv.Update([]byte("test"), func(b Bucket) error { return nil }), storeClosedErr)
+}
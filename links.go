@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	storeAppPath         = "/app/%d"
+	communityRecentPath  = "/profiles/%s/games/?tab=recent"
+	communityProfilePath = "/profiles/%s"
+	maxShortenedUrlBytes = 512
+)
+
+func shortenUrl(ctx context.Context, shortener, long string, client *steamClient) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(shortener, url.QueryEscape(long)), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("shortener returned status %d", res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	short := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(short, "http") || len(short) > maxShortenedUrlBytes {
+		return "", fmt.Errorf("shortener returned an invalid url")
+	}
+
+	return short, nil
+}
+
+func (s settings) link(ctx context.Context, long string, client *steamClient) string {
+	if !s.links {
+		return ""
+	}
+
+	if s.shortener == "" {
+		return long
+	}
+
+	short, err := shortenUrl(ctx, s.shortener, long, client)
+	if err != nil {
+		return long
+	}
+
+	return short
+}
+
+func (s settings) withLink(ctx context.Context, out, long string, client *steamClient) string {
+	l := s.link(ctx, long, client)
+	if l == "" {
+		return out
+	}
+
+	return fmt.Sprintf("%s - %s", out, s.formatter.Link(l))
+}
+
+func (s settings) withLinkLine(ctx context.Context, lines []string, long string, client *steamClient) []string {
+	l := s.link(ctx, long, client)
+	if l == "" {
+		return lines
+	}
+
+	return append(lines, s.formatter.Link(l))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortenUrl(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		out        string
+		errMsg     string
+	}{
+		{
+			name:       "Success",
+			statusCode: 200,
+			body:       "https://sho.rt/abc\n",
+			out:        "https://sho.rt/abc",
+			errMsg:     "",
+		},
+		{
+			name:       "Error status",
+			statusCode: 500,
+			body:       "",
+			out:        "",
+			errMsg:     "shortener returned status 500",
+		},
+		{
+			name:       "Not a url",
+			statusCode: 200,
+			body:       "Error: rate limited",
+			out:        "",
+			errMsg:     "shortener returned an invalid url",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(tc.statusCode, tc.body)
+
+			out, err := shortenUrl(context.Background(), "https://sho.rt/new?url=%s", "https://example.com", client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestSettingsWithLink(t *testing.T) {
+	cases := []struct {
+		name      string
+		links     bool
+		shortener string
+		body      string
+		out       string
+	}{
+		{
+			name:  "Links disabled",
+			links: false,
+			out:   "out",
+		},
+		{
+			name:  "Links enabled",
+			links: true,
+			out:   "out - https://example.com",
+		},
+		{
+			name:      "Shortened",
+			links:     true,
+			shortener: "https://sho.rt/new?url=%s",
+			body:      "https://sho.rt/abc",
+			out:       "out - https://sho.rt/abc",
+		},
+		{
+			name:      "Shortener failure falls back to long url",
+			links:     true,
+			shortener: "https://sho.rt/new?url=%s",
+			body:      "",
+			out:       "out - https://example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.links = tc.links
+			s.shortener = tc.shortener
+			client := NewTestClient(200, tc.body)
+
+			out := s.withLink(context.Background(), "out", "https://example.com", client)
+
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
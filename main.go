@@ -1,45 +1,153 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
-	"github.com/boltdb/bolt"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/gowon-irc/go-gowon"
 	"github.com/jessevdk/go-flags"
+	"google.golang.org/grpc"
 )
 
 type Options struct {
-	Prefix string `short:"P" long:"prefix" env:"GOWON_PREFIX" default:"." description:"prefix for commands"`
-	Broker string `short:"b" long:"broker" env:"GOWON_BROKER" default:"localhost:1883" description:"mqtt broker"`
-	APIKey string `short:"k" long:"api-key" env:"GOWON_STEAM_API_KEY" required:"true" description:"steam api key"`
-	KVPath string `short:"K" long:"kv-path" env:"GOWON_STEAM_KV_PATH" default:"kv.db" description:"path to kv db"`
+	Prefix            string        `short:"P" long:"prefix" env:"GOWON_PREFIX" default:"." description:"prefix for commands"`
+	Broker            string        `short:"b" long:"broker" env:"GOWON_BROKER" default:"localhost:1883" description:"mqtt broker"`
+	SubscribeQoS      byte          `long:"subscribe-qos" env:"GOWON_STEAM_SUBSCRIBE_QOS" default:"0" choice:"0" choice:"1" choice:"2" description:"qos for the command subscription"`
+	PublishQoS        byte          `long:"publish-qos" env:"GOWON_STEAM_PUBLISH_QOS" default:"0" choice:"0" choice:"1" choice:"2" description:"qos for published replies"`
+	PersistentSession bool          `long:"persistent-session" env:"GOWON_STEAM_PERSISTENT_SESSION" description:"ask the broker to keep the session and queued messages across reconnects"`
+	UnorderedMessages bool          `long:"unordered-messages" env:"GOWON_STEAM_UNORDERED_MESSAGES" description:"handle commands concurrently instead of in the order they arrive"`
+	StatusTopic       string        `long:"status-topic" env:"GOWON_STEAM_STATUS_TOPIC" default:"/gowon/status/steam" description:"topic for retained online and offline status messages, empty to disable"`
+	NoAliases         bool          `long:"no-aliases" env:"GOWON_STEAM_NO_ALIASES" description:"only register the steam command, not the recent, nowplaying and achievement shortcuts"`
+	NoLinkPreviews    bool          `long:"no-link-previews" env:"GOWON_STEAM_NO_LINK_PREVIEWS" description:"don't reply to steam store, community profile and workshop links posted in channels"`
+	UserCooldown      time.Duration `long:"user-cooldown" env:"GOWON_STEAM_USER_COOLDOWN" default:"0s" description:"time a user has to wait between commands, 0 to disable"`
+	ChannelLimit      int           `long:"channel-limit" env:"GOWON_STEAM_CHANNEL_LIMIT" default:"0" description:"maximum commands per minute in a channel, 0 to disable"`
+	UserDailyLimit    int           `long:"user-daily-limit" env:"GOWON_STEAM_USER_DAILY_LIMIT" default:"0" description:"maximum commands a nick can run per day, admins are exempt, 0 to disable"`
+	AdminTopic        string        `long:"admin-topic" env:"GOWON_STEAM_ADMIN_TOPIC" default:"/gowon/admin/steam" description:"topic for json admin commands, replies are published to <topic>/reply"`
+	AdminToken        string        `long:"admin-token" env:"GOWON_STEAM_ADMIN_TOKEN" description:"shared token admin commands must include, the admin topic is disabled without one"`
+	ErrorTopic        string        `long:"error-topic" env:"GOWON_STEAM_ERROR_TOPIC" default:"/gowon/errors/steam" description:"topic to publish command errors to, empty to disable"`
+	ErrorDSN          string        `long:"error-dsn" env:"GOWON_STEAM_ERROR_DSN" description:"sentry compatible dsn to send unexpected errors and panics to, empty to disable"`
+	DrainTimeout      time.Duration `long:"drain-timeout" env:"GOWON_STEAM_DRAIN_TIMEOUT" default:"10s" description:"how long to wait for in flight commands to finish on shutdown"`
+	Once              string        `long:"once" description:"run a single command, e.g. \"r username\", print the reply and exit without connecting to mqtt"`
+	HTTPListen        string        `long:"http-listen" env:"GOWON_STEAM_HTTP_LISTEN" description:"address to serve the json http api and health checks on, e.g. :8080, disabled if empty"`
+	GRPCListen        string        `long:"grpc-listen" env:"GOWON_STEAM_GRPC_LISTEN" description:"address to serve the grpc api on, e.g. :9090, disabled if empty, requires an admin token"`
+	WebhookToken      string        `long:"webhook-token" env:"GOWON_STEAM_WEBHOOK_TOKEN" description:"bearer token for the http announcement webhook, disabled if empty"`
+	ClientID          string        `long:"client-id" env:"GOWON_STEAM_CLIENT_ID" description:"mqtt client id, defaults to gowon_steam, or a per process id when using a share group"`
+	ClientIDSuffix    string        `long:"client-id-suffix" env:"GOWON_STEAM_CLIENT_ID_SUFFIX" description:"suffix appended to the mqtt client id, so several instances can share a broker"`
+	ClaimDir          string        `long:"claim-dir" env:"GOWON_STEAM_CLAIM_DIR" description:"directory shared between replicas used to claim messages, so each command is only answered once (needs the msgid tag, messages without one are always answered)"`
+	ClaimWindow       time.Duration `long:"claim-window" env:"GOWON_STEAM_CLAIM_WINDOW" default:"30s" description:"time a claimed message is remembered for"`
+	DebugAddr         string        `long:"debug-addr" env:"GOWON_STEAM_DEBUG_ADDR" description:"address to serve pprof debug endpoints on, e.g. localhost:6060, disabled if empty"`
+	Version           bool          `long:"version" description:"print the version and build info and exit"`
+	AdminNicks        []string      `long:"admin-nick" env:"GOWON_STEAM_ADMIN_NICKS" env-delim:"," description:"nick allowed to use admin subcommands such as stats, can be repeated"`
+	AlertDest         string        `long:"alert-dest" env:"GOWON_STEAM_ALERT_DEST" description:"channel or nick to alert when commands fail too often, disabled if empty"`
+	AlertThreshold    int           `long:"alert-threshold" env:"GOWON_STEAM_ALERT_THRESHOLD" default:"10" description:"failed commands within the alert window that trigger an alert"`
+	AlertWindow       time.Duration `long:"alert-window" env:"GOWON_STEAM_ALERT_WINDOW" default:"5m" description:"window failed commands are counted over"`
+	AlertCooldown     time.Duration `long:"alert-cooldown" env:"GOWON_STEAM_ALERT_COOLDOWN" default:"30m" description:"minimum time between alerts"`
+	ShareGroup        string        `long:"share-group" env:"GOWON_STEAM_SHARE_GROUP" description:"mqtt shared subscription group, so several replicas can split commands between them"`
+	APIKey            string        `short:"k" long:"api-key" env:"GOWON_STEAM_API_KEY" description:"steam api key, or a comma separated list of keys to rotate between"`
+	KeyCheck          string        `long:"key-check" env:"GOWON_STEAM_KEY_CHECK" default:"warn" choice:"off" choice:"warn" choice:"fail" description:"check the api keys work on startup, and warn or exit if they don't"`
+	APIKeyFile        string        `long:"api-key-file" env:"GOWON_STEAM_API_KEY_FILE" description:"file containing the steam api key or keys, one per line or comma separated"`
+	KVDriver          string        `long:"kv-driver" env:"GOWON_STEAM_KV_DRIVER" default:"bolt" description:"storage driver for the kv db, bolt, memory, redis or sqlite"`
+	KVPath            string        `short:"K" long:"kv-path" env:"GOWON_STEAM_KV_PATH" default:"kv.db" description:"path to kv db, or the connection string for the kv driver, e.g. redis://localhost:6379/0"`
+
+	Timeout              time.Duration `long:"timeout" env:"GOWON_STEAM_TIMEOUT" default:"10s" description:"time allowed for steam api requests per command"`
+	GatherBudget         time.Duration `long:"gather-budget" env:"GOWON_STEAM_GATHER_BUDGET" default:"6s" description:"time commands spend gathering per game details before replying with partial results, 0 for no limit"`
+	RetryAttempts        int           `long:"retry-attempts" env:"GOWON_STEAM_RETRY_ATTEMPTS" default:"3" description:"maximum attempts for a steam api request that fails transiently"`
+	RetryBudget          int           `long:"retry-budget" env:"GOWON_STEAM_RETRY_BUDGET" default:"5" description:"maximum retries across all steam api requests made by one command"`
+	RetryDelay           time.Duration `long:"retry-delay" env:"GOWON_STEAM_RETRY_DELAY" default:"500ms" description:"base delay between retries, doubled on each attempt"`
+	RetryMaxDelay        time.Duration `long:"retry-max-delay" env:"GOWON_STEAM_RETRY_MAX_DELAY" default:"4s" description:"maximum delay between retries"`
+	RateLimit            float64       `long:"rate-limit" env:"GOWON_STEAM_RATE_LIMIT" default:"5" description:"maximum steam api requests per second, 0 for no limit"`
+	RateBurst            int           `long:"rate-burst" env:"GOWON_STEAM_RATE_BURST" default:"10" description:"steam api requests allowed in a burst above the rate limit"`
+	BreakerThreshold     int           `long:"breaker-threshold" env:"GOWON_STEAM_BREAKER_THRESHOLD" default:"5" description:"consecutive steam api failures before requests are short-circuited, 0 to disable"`
+	BreakerCooldown      time.Duration `long:"breaker-cooldown" env:"GOWON_STEAM_BREAKER_COOLDOWN" default:"1m" description:"time to wait before retrying steam api after the breaker opens"`
+	CacheSize            int           `long:"cache-size" env:"GOWON_STEAM_CACHE_SIZE" default:"1000" description:"maximum number of cached steam api responses, 0 to disable caching"`
+	CacheTTL             time.Duration `long:"cache-ttl" env:"GOWON_STEAM_CACHE_TTL" default:"1h" description:"time to cache steam api responses"`
+	DiskCache            bool          `long:"disk-cache" env:"GOWON_STEAM_DISK_CACHE" description:"cache slow changing steam api responses, such as game schemas and global achievement percentages, in the kv store across restarts"`
+	DiskCacheTTL         time.Duration `long:"disk-cache-ttl" env:"GOWON_STEAM_DISK_CACHE_TTL" default:"168h" description:"time to keep responses in the disk cache"`
+	SchemaCacheTTL       time.Duration `long:"schema-cache-ttl" env:"GOWON_STEAM_SCHEMA_CACHE_TTL" default:"24h" description:"time to cache game schemas, which hold achievement names, descriptions and icons"`
+	RarityCacheTTL       time.Duration `long:"rarity-cache-ttl" env:"GOWON_STEAM_RARITY_CACHE_TTL" default:"24h" description:"time to cache global achievement percentages"`
+	NoAppIndex           bool          `long:"no-app-index" env:"GOWON_STEAM_NO_APP_INDEX" description:"don't keep a local index of steam app names, game arguments must then be app ids"`
+	AppIndexRefresh      time.Duration `long:"app-index-refresh" env:"GOWON_STEAM_APP_INDEX_REFRESH" default:"24h" description:"time between refreshes of the app name index, 0 to only refresh on startup"`
+	WatchGames           []string      `long:"watch-game" env:"GOWON_STEAM_WATCH_GAMES" env-delim:"," description:"steam app id to sample player counts for, for playershistory, can be repeated"`
+	PlayerSampleInterval time.Duration `long:"player-sample-interval" env:"GOWON_STEAM_PLAYER_SAMPLE_INTERVAL" default:"15m" description:"time between player count samples of watched games"`
+	CacheStaleFor        time.Duration `long:"cache-stale-for" env:"GOWON_STEAM_CACHE_STALE_FOR" default:"1h" description:"time an expired cached response is still served while it is refreshed in the background, 0 to always wait for a refresh"`
+	StaleTTL             time.Duration `long:"stale-ttl" env:"GOWON_STEAM_STALE_TTL" default:"24h" description:"how old a cached response can be and still be served while the steam api circuit breaker is open, 0 to disable"`
+	WarmCache            bool          `long:"warm-cache" env:"GOWON_STEAM_WARM_CACHE" description:"resolve ids and fetch summaries for all linked users on startup"`
+	DailyBudget          int           `long:"daily-budget" env:"GOWON_STEAM_DAILY_BUDGET" default:"90000" description:"maximum steam api requests per day per api key, 0 for no limit"`
+	KeyCooldown          time.Duration `long:"key-cooldown" env:"GOWON_STEAM_KEY_COOLDOWN" default:"5m" description:"time to skip an api key after it is rejected or rate limited, when rotating between several keys"`
+	AchievementGames     int           `long:"achievement-games" env:"GOWON_STEAM_ACHIEVEMENT_GAMES" default:"5" description:"maximum number of recent games checked for the last achievement, 0 for no limit"`
+
+	DialTimeout           time.Duration `long:"dial-timeout" env:"GOWON_STEAM_DIAL_TIMEOUT" default:"10s" description:"timeout for establishing outbound connections"`
+	TLSTimeout            time.Duration `long:"tls-timeout" env:"GOWON_STEAM_TLS_TIMEOUT" default:"10s" description:"timeout for outbound tls handshakes"`
+	ResponseHeaderTimeout time.Duration `long:"response-header-timeout" env:"GOWON_STEAM_RESPONSE_HEADER_TIMEOUT" default:"10s" description:"timeout waiting for response headers after sending a request"`
+	MaxIdleConns          int           `long:"max-idle-conns" env:"GOWON_STEAM_MAX_IDLE_CONNS" default:"100" description:"maximum idle connections kept open across all hosts, 0 for no limit"`
+	MaxIdleConnsPerHost   int           `long:"max-idle-conns-per-host" env:"GOWON_STEAM_MAX_IDLE_CONNS_PER_HOST" default:"10" description:"maximum idle connections kept open per host"`
+	MaxConnsPerHost       int           `long:"max-conns-per-host" env:"GOWON_STEAM_MAX_CONNS_PER_HOST" default:"0" description:"maximum connections per host, 0 for no limit"`
+	IdleConnTimeout       time.Duration `long:"idle-conn-timeout" env:"GOWON_STEAM_IDLE_CONN_TIMEOUT" default:"90s" description:"time an idle connection is kept open"`
+	NoCompression         bool          `long:"no-compression" env:"GOWON_STEAM_NO_COMPRESSION" description:"don't request gzip compressed responses"`
+	MaxBodySize           int64         `long:"max-body-size" env:"GOWON_STEAM_MAX_BODY_SIZE" default:"10485760" description:"maximum size in bytes of a response body, 0 for no limit"`
+	DebugHTTP             bool          `long:"debug-http" env:"GOWON_STEAM_DEBUG_HTTP" description:"log outbound requests with their status codes and latencies, api keys are redacted"`
+
+	APIURL          string   `long:"api-url" env:"GOWON_STEAM_API_URL" default:"https://api.steampowered.com" description:"base url of the steam web api"`
+	StoreURL        string   `long:"store-url" env:"GOWON_STEAM_STORE_URL" default:"https://store.steampowered.com" description:"base url of the steam store used in links"`
+	CommunityURL    string   `long:"community-url" env:"GOWON_STEAM_COMMUNITY_URL" default:"https://steamcommunity.com" description:"base url of the steam community site used in links"`
+	SteamSpyURL     string   `long:"steamspy-url" env:"GOWON_STEAM_STEAMSPY_URL" default:"https://steamspy.com" description:"base url of the steamspy api used for ownership estimates"`
+	ITADURL         string   `long:"itad-url" env:"GOWON_STEAM_ITAD_URL" default:"https://api.isthereanydeal.com" description:"base url of the isthereanydeal api"`
+	MockSteam       string   `long:"mock-steam" env:"GOWON_STEAM_MOCK_STEAM" description:"serve canned steam responses on this address and use them instead of steam, for local testing"`
+	MockSteamFaults []string `long:"mock-steam-fault" env:"GOWON_STEAM_MOCK_STEAM_FAULTS" env-delim:"," description:"fault to inject into the mock steam api as endpoint=fault, e.g. achievements=private, can be repeated"`
+
+	Timezone   string `long:"timezone" env:"GOWON_STEAM_TIMEZONE" default:"UTC" description:"default timezone for displayed times"`
+	DateFormat string `long:"date-format" env:"GOWON_STEAM_DATE_FORMAT" default:"iso" description:"default date format for displayed times (iso, eu, us, rfc)"`
+	Language   string `long:"language" env:"GOWON_STEAM_LANGUAGE" default:"en" description:"default language for steam api responses"`
+
+	NoLinks      bool   `long:"no-links" env:"GOWON_STEAM_NO_LINKS" description:"don't append store and community links to outputs"`
+	Shortener    string `long:"shortener" env:"GOWON_STEAM_SHORTENER" description:"url shortener endpoint, %s is replaced with the escaped url and the response body is used as the link"`
+	ITADKey      string `long:"itad-key" env:"GOWON_STEAM_ITAD_KEY" description:"isthereanydeal api key, enables the deal subcommand"`
+	ITADCountry  string `long:"itad-country" env:"GOWON_STEAM_ITAD_COUNTRY" default:"US" description:"two letter country code used for isthereanydeal prices"`
+	MaxList      int    `long:"max-list" env:"GOWON_STEAM_MAX_LIST" default:"10" description:"maximum number of items shown by list commands, 0 for no limit"`
+	AsciiBars    bool   `long:"ascii-bars" env:"GOWON_STEAM_ASCII_BARS" description:"draw progress bars with ascii characters only"`
+	MaskHidden   bool   `long:"mask-hidden" env:"GOWON_STEAM_MASK_HIDDEN" description:"mask the descriptions of hidden achievements"`
+	PersonaNames bool   `long:"persona-names" env:"GOWON_STEAM_PERSONA_NAMES" description:"show steam persona names alongside usernames"`
+	Verbose      bool   `long:"verbose" env:"GOWON_STEAM_VERBOSE" description:"include extra details in outputs, such as achievement progress for recent games (uses more api calls)"`
+
+	Messages     string            `long:"messages" env:"GOWON_STEAM_MESSAGES" default:"en" description:"default language for the module's own messages"`
+	DestMessages map[string]string `long:"dest-messages" env:"GOWON_STEAM_DEST_MESSAGES" env-delim:"," description:"message language for a destination, e.g. #german:de"`
+	MessagesFile string            `long:"messages-file" env:"GOWON_STEAM_MESSAGES_FILE" description:"json file of additional or overridden message catalogs"`
+
+	Format            string            `long:"format" env:"GOWON_STEAM_FORMAT" default:"irc" description:"default output format (irc, discord, html)"`
+	PositionalColours bool              `long:"positional-colours" env:"GOWON_STEAM_POSITIONAL_COLOURS" description:"colour list items by position instead of by name"`
+	DestFormats       map[string]string `long:"dest-format" env:"GOWON_STEAM_DEST_FORMATS" env-delim:"," description:"output format for a destination, e.g. #bridged:discord"`
+	DisabledCommands  []string          `long:"disable-command" env:"GOWON_STEAM_DISABLED_COMMANDS" env-delim:"," description:"subcommand to disable, everywhere or in one destination, e.g. selftest or #quiet:recent, can be repeated"`
 }
 
 const (
 	moduleName               = "steam"
 	mqttConnectRetryInternal = 5
 	mqttDisconnectTimeout    = 1000
+	maxCountArg              = 999
 )
 
-func setUser(kv *bolt.DB, nick, user []byte) error {
-	err := kv.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte("steam"))
+var usersBucket = []byte("steam")
+
+func setUser(kv Store, nick, user []byte) error {
+	err := kv.Update(usersBucket, func(b Bucket) error {
 		return b.Put([]byte(nick), []byte(user))
 	})
 	return err
 }
 
-func getUser(kv *bolt.DB, nick []byte) (user []byte, err error) {
-	err = kv.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte("steam"))
+func getUser(kv Store, nick []byte) (user []byte, err error) {
+	err = kv.View(usersBucket, func(b Bucket) error {
 		v := b.Get([]byte(nick))
 		user = v
 		return nil
@@ -47,140 +155,437 @@ func getUser(kv *bolt.DB, nick []byte) (user []byte, err error) {
 	return user, err
 }
 
-func parseArgs(msg string) (command, user string) {
-	fields := strings.Fields(msg)
+func setUserHandler(kv Store, s settings, m gowon.Message, user string) (string, error) {
+	if user == "" {
+		return s.msg("username_needed"), nil
+	}
+
+	nick := m.Nick
+
+	user = normaliseSteamUser(user)
+
+	old, err := getUser(kv, []byte(nick))
+	if err != nil {
+		return "", err
+	}
 
-	if len(fields) >= 1 {
-		command = fields[0]
+	err = setUser(kv, []byte(nick), []byte(user))
+	if err != nil {
+		return "", err
 	}
 
-	if len(fields) >= 2 {
-		user = fields[1]
+	err = recordAudit(kv, auditEntry{Time: wallClock.Now(), Actor: nick, Channel: m.Dest, Action: "set", Nick: nick, Old: string(old), New: user})
+	if err != nil {
+		log.Printf("couldn't record audit entry: %s\n", err)
 	}
 
-	return command, user
+	return s.msg("user_set", nick, user), nil
 }
 
-func setUserHandler(kv *bolt.DB, nick, user string) (string, error) {
-	if user == "" {
-		return "Error: username needed", nil
+func setTimezoneHandler(kv Store, s settings, nick, timezone string) (string, error) {
+	if timezone == "" {
+		return s.msg("timezone_needed"), nil
 	}
 
-	err := setUser(kv, []byte(nick), []byte(user))
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return s.msg("unknown_timezone", timezone), nil
+	}
+
+	err := setPref(kv, timezonePref, []byte(nick), []byte(timezone))
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("set %s's user to %s", nick, user), nil
+	return s.msg("timezone_set", nick, timezone), nil
 }
 
-type commandFunc func(string, string, *http.Client) (string, error)
+func setDateFormatHandler(kv Store, s settings, nick, dateFormat string) (string, error) {
+	if dateFormat == "" {
+		return s.msg("dateformat_needed", dateFormatNames()), nil
+	}
 
-func CommandHandler(kv *bolt.DB, nick, user, apiKey string, client *http.Client, f commandFunc) (string, error) {
-	if user != "" {
-		return f(apiKey, user, client)
+	if _, err := parseDateFormat(dateFormat); err != nil {
+		return s.msg("error", err), nil
 	}
 
-	userC, err := getUser(kv, []byte(nick))
+	err := setPref(kv, dateFormatPref, []byte(nick), []byte(strings.ToLower(dateFormat)))
 	if err != nil {
 		return "", err
 	}
 
-	if len(userC) == 0 {
-		return "Error: username needed", nil
-	}
-
-	return f(apiKey, string(userC), client)
+	return s.msg("dateformat_set", nick, strings.ToLower(dateFormat)), nil
 }
 
-func genSteamHandler(apiKey string, kv *bolt.DB, client *http.Client) func(m gowon.Message) (string, error) {
-	return func(m gowon.Message) (string, error) {
-		command, user := parseArgs(m.Args)
+func setLanguageHandler(kv Store, s settings, nick, language string) (string, error) {
+	if language == "" {
+		return s.msg("language_needed"), nil
+	}
 
-		switch command {
-		case "s", "set":
-			return setUserHandler(kv, m.Nick, user)
-		case "r", "recent":
-			return CommandHandler(kv, m.Nick, user, apiKey, client, steamLastGame)
-		case "a", "achievement":
-			return CommandHandler(kv, m.Nick, user, apiKey, client, steamLastAchievement)
-		}
+	lang, err := parseLanguage(language)
+	if err != nil {
+		return s.msg("error", err), nil
+	}
 
-		return "one of [s]et, [r]ecent or [a]chievements must be passed as a command", nil
+	err = setPref(kv, languagePref, []byte(nick), []byte(lang))
+	if err != nil {
+		return "", err
 	}
+
+	return s.msg("language_set", nick, lang), nil
 }
 
-func defaultPublishHandler(c mqtt.Client, msg mqtt.Message) {
-	log.Printf("unexpected message:  %s\n", msg)
+func setToggleHandler(kv Store, s settings, pref, nick, value string) (string, error) {
+	if value != "on" && value != "off" {
+		return s.msg("toggle_invalid", pref), nil
+	}
+
+	err := setPref(kv, pref, []byte(nick), []byte(value))
+	if err != nil {
+		return "", err
+	}
+
+	return s.msg("toggle_set", nick, pref, value), nil
 }
 
-func onConnectionLostHandler(c mqtt.Client, err error) {
-	log.Println("connection to broker lost")
+type commandFunc func(context.Context, string, string, *steamClient, settings) (string, error)
+
+var errorMessages = map[error]string{
+	circuitOpenErr:      "steam_down",
+	ErrInvalidKey:       "invalid_key",
+	ErrRateLimited:      "rate_limited",
+	ErrSteamAPI:         "steam_error",
+	responseTooLargeErr: "response_too_large",
 }
 
-func onRecconnectingHandler(c mqtt.Client, opts *mqtt.ClientOptions) {
-	log.Println("attempting to reconnect to broker")
+func genSteamHandler(apiKey string, kv Store, client *steamClient, defaults settings, timeout time.Duration, retryBudget int) func(m gowon.Message) (string, error) {
+	return func(m gowon.Message) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		ctx = withRetryBudget(ctx, retryBudget)
+		ctx = withStaleTracker(ctx)
+
+		command, args, modifiers := parseArgs(m.Args)
+
+		s, err := requestSettings(kv, defaults, m.Nick, m.Dest)
+		if err != nil {
+			return "", err
+		}
+		s = s.withModifiers(modifiers)
+
+		if wait, notified := cooldowns.Allow(m.Nick, m.Dest, wallClock.Now()); wait > 0 {
+			if notified {
+				return "", nil
+			}
+			return s.formatter.Render(s.msg("cooldown", shortDuration(wait))), nil
+		}
+
+		atomic.AddUint64(&commandsServed, 1)
+
+		u, err := recordUsage(kv, m.Nick, wallClock.Now())
+		if err != nil {
+			log.Printf("couldn't record usage for %s: %s\n", m.Nick, err)
+		} else if s.overDailyLimit(u) {
+			if u.Today == s.userDailyLimit+1 {
+				return s.formatter.Render(s.msg("usage_limit")), nil
+			}
+			return "", nil
+		}
+
+		out, err := routeCommand(ctx, command, args, apiKey, kv, client, s, m)
+		errorReports.Report(command, m.Nick, m.Dest, err)
+		errorAlerts.Record(err, wallClock.Now())
+		for e, id := range errorMessages {
+			if errors.Is(err, e) {
+				return s.formatter.Render(s.msg(id)), nil
+			}
+		}
+
+		if err != nil {
+			log.Printf("%s command failed: %s\n", command, sanitiseError(err))
+			errorHook.CaptureError(command, m.Nick, m.Dest, err)
+			return s.formatter.Render(s.msg("command_failed")), nil
+		}
+
+		if since, ok := staleSince(ctx); ok {
+			out = fmt.Sprintf("%s %s", out, s.msg("cached_ago", shortDuration(wallClock.Now().Sub(since))))
+		}
+
+		return s.overflow(m.Nick, m.Dest, s.formatter.Render(out)), nil
+	}
 }
 
-func onConnectHandler(c mqtt.Client) {
-	log.Println("connected to broker")
+func defaultPublishHandler(c mqtt.Client, msg mqtt.Message) {
+	log.Printf("unexpected message:  %s\n", msg)
 }
 
 func main() {
-	log.Printf("%s starting\n", moduleName)
+	log.Printf("%s %s starting\n", moduleName, moduleVersion())
 
 	opts := Options{}
 	if _, err := flags.Parse(&opts); err != nil {
 		log.Fatal(err)
 	}
 
+	if opts.Version {
+		fmt.Println(buildInfo())
+		return
+	}
+
+	if problems := validateOptions(opts); len(problems) > 0 {
+		for _, p := range problems {
+			log.Printf("config: %s\n", p)
+		}
+		log.Fatalf("found %d configuration problems", len(problems))
+	}
+
+	if opts.DebugAddr != "" {
+		serveDebug(opts.DebugAddr)
+	}
+
+	if opts.ErrorDSN != "" {
+		hook, err := parseSentryDSN(opts.ErrorDSN)
+		if err != nil {
+			log.Fatal(err)
+		}
+		errorHook = hook
+	}
+
 	mqttOpts := mqtt.NewClientOptions()
 	mqttOpts.AddBroker(fmt.Sprintf("tcp://%s", opts.Broker))
-	mqttOpts.SetClientID(fmt.Sprintf("gowon_%s", moduleName))
+	host, _ := os.Hostname()
+	mqttOpts.SetClientID(customClientID(opts.ClientID, opts.ClientIDSuffix, opts.ShareGroup, host))
 	mqttOpts.SetConnectRetry(true)
 	mqttOpts.SetConnectRetryInterval(mqttConnectRetryInternal * time.Second)
 	mqttOpts.SetAutoReconnect(true)
+	mqttOpts.SetMaxReconnectInterval(mqttMaxReconnectInterval)
+	mqttOpts.SetCleanSession(!opts.PersistentSession)
+	mqttOpts.SetOrderMatters(!opts.UnorderedMessages)
 
 	mqttOpts.DefaultPublishHandler = defaultPublishHandler
 	mqttOpts.OnConnectionLost = onConnectionLostHandler
 	mqttOpts.OnReconnecting = onRecconnectingHandler
 	mqttOpts.OnConnect = onConnectHandler
 
-	kv, err := bolt.Open(opts.KVPath, 0666, nil)
+	if opts.StatusTopic != "" {
+		setStatusTopic(mqttOpts, opts.StatusTopic, opts.PublishQoS)
+	}
+
+	kv, err := openStore(opts.KVDriver, opts.KVPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer kv.Close()
 
-	err = kv.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte("steam"))
-		return err
-	})
+	if opts.MessagesFile != "" {
+		if err := loadCatalogs(opts.MessagesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if opts.DiskCache {
+		diskCache, err = newBoltCache(kv, opts.DiskCacheTTL)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if n, err := diskCache.Prune(time.Now()); err == nil && n > 0 {
+			log.Printf("pruned %d expired disk cache entries\n", n)
+		}
+	}
+
+	if opts.MockSteam != "" {
+		ms, err := startMockSteam(opts.MockSteam, opts.MockSteamFaults)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer ms.Close()
+
+		opts.APIURL, opts.StoreURL, opts.SteamSpyURL, opts.ITADURL = ms.URL, ms.URL, ms.URL, ms.URL
+		log.Printf("using mock steam api at %s\n", ms.URL)
+	}
+
+	opts.APIKey, err = resolveAPIKey(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiKey := parseAPIKeys(opts.APIKey)[0]
+
+	client, err := newSteamClient(steamClientOptions(opts)...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schemaCacheTTL = opts.SchemaCacheTTL
+	percentagesCacheTTL = opts.RarityCacheTTL
+
+	if opts.ClaimDir != "" {
+		messageClaims, err = newClaimDir(opts.ClaimDir, opts.ClaimWindow)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go pruneClaims(messageClaims, opts.ClaimWindow)
+	}
+
+	if opts.UserCooldown > 0 || opts.ChannelLimit > 0 {
+		cooldowns = newCooldownTracker(opts.UserCooldown, opts.ChannelLimit)
+		go pruneCooldowns(cooldowns, channelCooldownWindow)
+	}
+
+	defaults, err := newSettings(opts)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defaults = client.settings(defaults)
+
+	keysValid := true
+	if opts.KeyCheck != "off" {
+		probeClient := *client
+		probeClient.httpClient = &http.Client{Transport: &userAgentTransport{next: newBaseTransport(opts), userAgent: userAgent()}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		err := checkAPIKeys(ctx, parseAPIKeys(opts.APIKey), &probeClient)
+		cancel()
+
+		keysValid = err == nil
+
+		if err != nil && opts.KeyCheck == "fail" {
+			log.Fatal(err)
+		}
+
+		if err != nil {
+			log.Printf("WARNING: %s, commands will fail until this is fixed\n", err)
+		}
+	}
+
+	if !opts.NoAppIndex {
+		names, err := loadAppNames(kv)
+		if err != nil {
+			log.Fatal(err)
+		}
+		apps = newAppIndex(names)
+
+		go refreshAppIndex(kv, apiKey, client, opts.AppIndexRefresh)
+	}
+
+	if len(opts.WatchGames) > 0 {
+		watched, err := parseWatchedGames(opts.WatchGames)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go runPlayerSampler(kv, watched, client, opts.PlayerSampleInterval)
+	}
 
-	httpClient := &http.Client{}
+	if opts.WarmCache && client.cache != nil {
+		go func() {
+			n, err := warmCache(context.Background(), kv, apiKey, client)
+			if err != nil {
+				log.Printf("cache warming stopped after %d users: %s\n", n, err)
+				return
+			}
+
+			log.Printf("warmed cache for %d users\n", n)
+		}()
+	}
 
 	mr := gowon.NewMessageRouter()
-	mr.AddCommand("steam", genSteamHandler(opts.APIKey, kv, httpClient))
-	mr.Subscribe(mqttOpts, moduleName)
+	steamHandler := recoverHandler("steam", defaults.msg("command_failed"), genSteamHandler(apiKey, kv, client, defaults, opts.Timeout, opts.RetryBudget))
+	mr.AddCommand("steam", steamHandler)
+
+	if opts.Once != "" {
+		if err := runOnce(steamHandler, opts.Once, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if !opts.NoAliases {
+		addAliases(mr, steamHandler)
+	}
+	if !opts.NoLinkPreviews {
+		mr.AddRegex(linkPreviewRegex, recoverHandler("link preview", "", genLinkPreviewHandler(apiKey, kv, client, defaults, opts.Timeout, opts.RetryBudget)))
+	}
+	if opts.AdminToken != "" {
+		a := &admin{token: opts.AdminToken, kv: kv, apiKey: apiKey, client: client, started: time.Now()}
+		subscribeAdmin(mqttOpts, a, opts.AdminTopic, opts.SubscribeQoS)
+	}
+
+	subscribeChannel(mqttOpts, mr, moduleName, inputTopic(opts.ShareGroup), gowonOutputTopic, opts.SubscribeQoS, opts.PublishQoS)
 
 	log.Print("connecting to broker")
 
 	c := mqtt.NewClient(mqttOpts)
+	replies = &replyPublisher{client: c, topic: gowonOutputTopic, qos: opts.PublishQoS}
+	if opts.AlertDest != "" {
+		errorAlerts = newErrorRateAlerter(opts.AlertThreshold, opts.AlertWindow, opts.AlertCooldown, func(count int, window time.Duration) {
+			replies.Send(opts.AlertDest, defaults.formatter.Render(defaults.msg("error_rate_alert", count, shortDuration(window))))
+		})
+	}
+	if opts.ErrorTopic != "" {
+		errorReports = &errorPublisher{client: c, topic: opts.ErrorTopic, qos: opts.PublishQoS}
+	}
+
+	var apiServer *http.Server
+	if opts.HTTPListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle(apiPrefix, newAPIHandler(apiKey, client, defaults, opts.Timeout, opts.RetryBudget))
+		mux.HandleFunc(healthzPath, healthzHandler)
+		mux.Handle(readyzPath, readiness{brokerConnected: c.IsConnectionOpen, kv: kv, keysValid: keysValid})
+		if opts.WebhookToken != "" {
+			mux.Handle(webhookPath, newWebhookHandler(opts.WebhookToken, defaults, func(payload []byte) {
+				c.Publish(gowonOutputTopic, opts.PublishQoS, false, payload)
+			}))
+		}
+		apiServer = serveAPI(opts.HTTPListen, mux)
+	}
+
+	var rpcServer *grpc.Server
+	if opts.GRPCListen != "" {
+		g := &grpcServer{token: opts.AdminToken, apiKey: apiKey, kv: kv, client: client, defaults: defaults, timeout: opts.Timeout, retryBudget: opts.RetryBudget}
+		if rpcServer, err = serveGRPC(opts.GRPCListen, g); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if token := c.Connect(); token.Wait() && token.Error() != nil {
 		panic(token.Error())
 	}
 
 	log.Print("connected to broker")
 
+	if _, err := sdNotify(sdReady); err != nil {
+		log.Println(err)
+	}
+
+	stopWatchdog := make(chan struct{})
+	if interval, ok := watchdogInterval(); ok {
+		go runWatchdog(interval, c.IsConnectionOpen, stopWatchdog)
+	}
+
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
 	<-sigs
 
 	log.Println("signal caught, exiting")
+	close(stopWatchdog)
+	if _, err := sdNotify(sdStopping); err != nil {
+		log.Println(err)
+	}
+
+	c.Unsubscribe(inputTopic(opts.ShareGroup)).WaitTimeout(mqttDisconnectTimeout * time.Millisecond)
+	if !inflight.drain(opts.DrainTimeout) {
+		log.Println("timed out waiting for in flight commands")
+	}
+	shutdownAPI(apiServer)
+	shutdownGRPC(rpcServer)
+
+	if opts.StatusTopic != "" {
+		publishOffline(c, opts.StatusTopic, opts.PublishQoS)
+	}
 	c.Disconnect(mqttDisconnectTimeout)
+	if client.cache != nil {
+		hits, misses := client.cache.Stats()
+		log.Printf("cache stats: %d hits, %d misses, %.2f hit ratio\n", hits, misses, client.cache.HitRatio())
+	}
 	log.Println("shutdown complete")
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var storeClosedErr = errors.New("store is closed")
+
+type memBucket struct {
+	values map[string][]byte
+	seq    uint64
+}
+
+func (mb *memBucket) Get(key []byte) []byte {
+	v, ok := mb.values[string(key)]
+	if !ok {
+		return nil
+	}
+
+	return append([]byte{}, v...)
+}
+
+func (mb *memBucket) Put(key, value []byte) error {
+	mb.values[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (mb *memBucket) Delete(key []byte) error {
+	delete(mb.values, string(key))
+	return nil
+}
+
+func (mb *memBucket) ForEach(fn func(k, v []byte) error) error {
+	keys := []string{}
+	for k := range mb.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := fn([]byte(k), mb.values[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mb *memBucket) NextSequence() (uint64, error) {
+	mb.seq++
+	return mb.seq, nil
+}
+
+func (mb *memBucket) Clear() (int, error) {
+	n := len(mb.values)
+	mb.values = make(map[string][]byte)
+
+	return n, nil
+}
+
+func (mb *memBucket) clone() *memBucket {
+	c := &memBucket{values: make(map[string][]byte, len(mb.values)), seq: mb.seq}
+	for k, v := range mb.values {
+		c.values[k] = v
+	}
+
+	return c
+}
+
+type memStore struct {
+	mu      sync.RWMutex
+	buckets map[string]*memBucket
+	closed  bool
+}
+
+func openMemStore(dsn string) (Store, error) {
+	return &memStore{buckets: make(map[string]*memBucket)}, nil
+}
+
+func (ms *memStore) View(bucket []byte, fn func(b Bucket) error) error {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	if ms.closed {
+		return storeClosedErr
+	}
+
+	b, ok := ms.buckets[string(bucket)]
+	if !ok {
+		return nil
+	}
+
+	return fn(b)
+}
+
+func (ms *memStore) Update(bucket []byte, fn func(b Bucket) error) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.closed {
+		return storeClosedErr
+	}
+
+	b := &memBucket{values: make(map[string][]byte)}
+	if current, ok := ms.buckets[string(bucket)]; ok {
+		b = current.clone()
+	}
+
+	if err := fn(b); err != nil {
+		return err
+	}
+
+	ms.buckets[string(bucket)] = b
+	return nil
+}
+
+func (ms *memStore) Ping() error {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	if ms.closed {
+		return storeClosedErr
+	}
+
+	return nil
+}
+
+func (ms *memStore) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.closed = true
+	return nil
+}
+
+func init() {
+	registerStoreDriver("memory", openMemStore)
+}
@@ -0,0 +1,22 @@
+package main
+
+import "github.com/gowon-irc/gowon-steam/testing/mocksteam"
+
+func startMockSteam(addr string, faults []string) (*mocksteam.Server, error) {
+	ms, err := mocksteam.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range faults {
+		name, fault, err := mocksteam.ParseFault(f)
+		if err != nil {
+			ms.Close()
+			return nil, err
+		}
+
+		ms.Inject(name, fault)
+	}
+
+	return ms, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func useMockSteam(t *testing.T) (*mocksteam.Server, *steamClient) {
+	ms := mocksteam.New()
+	t.Cleanup(ms.Close)
+
+	client, err := newSteamClient(
+		WithBaseURL("api", ms.URL),
+		WithBaseURL("store", ms.URL),
+		WithBaseURL("steamspy", ms.URL),
+		WithBaseURL("itad", ms.URL),
+	)
+	assert.Nil(t, err)
+
+	return ms, client
+}
+
+func TestMockSteamCommands(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint string
+		fault    mocksteam.Fault
+		command  commandFunc
+		user     string
+		out      string
+		err      error
+	}{
+		{
+			name:    "Recent games",
+			command: steamLastGame,
+			user:    "gaben",
+			out:     "gaben's recently played steam games: {green}Portal 2{clear} (12.6h), {red}Portal{clear} (2.0h)",
+		},
+		{
+			name:    "Last achievement",
+			command: steamLastAchievement,
+			user:    "gaben",
+			out:     "gaben's last steam achievement: Portal 2 - Wake Up Call (87.5% of players) (Survive the manual override of Aperture Science's relaxation center) ({yellow}2/3{clear}) (unlocked 2021-11-30 23:51 UTC)",
+		},
+		{
+			name:    "Unknown user",
+			command: steamLastGame,
+			user:    "nobody",
+			out:     "Error: no id found for nobody",
+		},
+		{
+			name:     "Private profile",
+			endpoint: "achievements",
+			fault:    mocksteam.Private,
+			command:  steamLastAchievement,
+			user:     "gaben",
+			out:      "Error: profile is not public",
+		},
+		{
+			name:     "Rate limited",
+			endpoint: "recent",
+			fault:    mocksteam.RateLimited,
+			command:  steamLastGame,
+			user:     "gaben",
+			err:      ErrRateLimited,
+		},
+		{
+			name:     "Truncated body",
+			endpoint: "recent",
+			fault:    mocksteam.Truncated,
+			command:  steamLastGame,
+			user:     "gaben",
+			out:      "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject(tc.endpoint, tc.fault)
+
+			out, err := tc.command(context.Background(), "key", tc.user, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			switch {
+			case tc.err != nil:
+				assert.ErrorIs(t, err, tc.err)
+			case tc.fault == mocksteam.Truncated:
+				assert.Error(t, err)
+			default:
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestStartMockSteam(t *testing.T) {
+	ms, err := startMockSteam("127.0.0.1:0", []string{"recent=ratelimit"})
+	assert.Nil(t, err)
+	defer ms.Close()
+
+	res, err := http.Get(ms.URL + "/IPlayerService/GetRecentlyPlayedGames/v1/")
+	assert.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+	_, err = startMockSteam("127.0.0.1:0", []string{"recent"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gowon-irc/go-gowon"
+)
+
+const (
+	gowonInputTopic  = "/gowon/input"
+	gowonOutputTopic = "/gowon/output"
+)
+
+func inputTopic(shareGroup string) string {
+	if shareGroup == "" {
+		return gowonInputTopic
+	}
+
+	return fmt.Sprintf("$share/%s/%s", shareGroup, gowonInputTopic)
+}
+
+func clientID(shareGroup, host string) string {
+	if shareGroup == "" {
+		return fmt.Sprintf("gowon_%s", moduleName)
+	}
+
+	return fmt.Sprintf("gowon_%s_%s_%d", moduleName, host, os.Getpid())
+}
+
+func customClientID(id, suffix, shareGroup, host string) string {
+	if id == "" {
+		id = clientID(shareGroup, host)
+	}
+
+	if suffix == "" {
+		return id
+	}
+
+	return fmt.Sprintf("%s_%s", id, suffix)
+}
+
+type moduleStatus struct {
+	Module string `json:"module"`
+	Status string `json:"status"`
+}
+
+func statusPayload(status string) []byte {
+	b, _ := json.Marshal(moduleStatus{Module: moduleName, Status: status})
+	return b
+}
+
+func setStatusTopic(opts *mqtt.ClientOptions, topic string, qos byte) {
+	opts.SetBinaryWill(topic, statusPayload("offline"), qos, true)
+
+	oldOnConnect := opts.OnConnect
+
+	opts.OnConnect = func(client mqtt.Client) {
+		if oldOnConnect != nil {
+			oldOnConnect(client)
+		}
+
+		client.Publish(topic, qos, true, statusPayload("online"))
+	}
+}
+
+func publishOffline(client mqtt.Client, topic string, qos byte) {
+	t := client.Publish(topic, qos, true, statusPayload("offline"))
+	t.WaitTimeout(mqttDisconnectTimeout * time.Millisecond)
+}
+
+type inflightTracker struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+}
+
+var inflight = &inflightTracker{}
+
+func (it *inflightTracker) start() bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if it.draining {
+		return false
+	}
+
+	it.wg.Add(1)
+	return true
+}
+
+func (it *inflightTracker) done() {
+	it.wg.Done()
+}
+
+func (it *inflightTracker) drain(timeout time.Duration) bool {
+	it.mu.Lock()
+	it.draining = true
+	it.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		it.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func routeMessage(mr *gowon.MessageRouter, module string, payload []byte) ([]byte, error) {
+	ms, err := gowon.CreateMessageStruct(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := mr.Route(ms)
+	if err != nil || out == "" {
+		return nil, err
+	}
+
+	ms.Module = module
+	ms.Msg = out
+
+	return json.Marshal(ms)
+}
+
+func subscribeChannel(opts *mqtt.ClientOptions, mr *gowon.MessageRouter, module, inTopic, outTopic string, subQos, pubQos byte) {
+	oldOnConnect := opts.OnConnect
+
+	opts.OnConnect = func(client mqtt.Client) {
+		if oldOnConnect != nil {
+			oldOnConnect(client)
+		}
+
+		client.Subscribe(inTopic, subQos, func(client mqtt.Client, msg mqtt.Message) {
+			if !inflight.start() {
+				return
+			}
+			defer inflight.done()
+
+			if !messageClaims.Claim(msg.Payload(), wallClock.Now()) {
+				return
+			}
+
+			mb, err := routeMessage(mr, module, msg.Payload())
+			if err != nil {
+				log.Print(err)
+				return
+			}
+
+			if mb != nil {
+				client.Publish(outTopic, pubQos, false, mb)
+			}
+		})
+
+		log.Printf("Subscription to %s complete", inTopic)
+	}
+}
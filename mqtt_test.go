@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputTopic(t *testing.T) {
+	assert.Equal(t, "/gowon/input", inputTopic(""))
+	assert.Equal(t, "$share/steam//gowon/input", inputTopic("steam"))
+}
+
+func TestClientID(t *testing.T) {
+	assert.Equal(t, "gowon_steam", clientID("", "host"))
+	assert.Equal(t, fmt.Sprintf("gowon_steam_host_%d", os.Getpid()), clientID("steam", "host"))
+}
+
+func TestRouteMessage(t *testing.T) {
+	mr := gowon.NewMessageRouter()
+	mr.AddCommand("steam", func(m gowon.Message) (string, error) {
+		if m.Args == "quiet" {
+			return "", nil
+		}
+		return "reply to " + m.Args, nil
+	})
+
+	cases := []struct {
+		name    string
+		payload string
+		out     string
+		errMsg  string
+	}{
+		{
+			name:    "Reply",
+			payload: `{"module":"gowon","msg":".steam r","nick":"n","dest":"#chan"}`,
+			out:     `{"module":"steam","nick":"n","code":"","raw":"","host":"","source":"","user":"","arguments":null,"tags":null,"msg":"reply to r","dest":"#chan","command":"steam","args":"r"}`,
+		},
+		{
+			name:    "No reply",
+			payload: `{"module":"gowon","msg":".steam quiet","nick":"n","dest":"#chan"}`,
+			out:     "",
+		},
+		{
+			name:    "Invalid message",
+			payload: `{}`,
+			errMsg:  "message body does not contain a module source",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := routeMessage(mr, "steam", []byte(tc.payload))
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tc.errMsg)
+			}
+
+			assert.Equal(t, tc.out, string(out))
+		})
+	}
+}
+
+func TestStatusPayload(t *testing.T) {
+	assert.Equal(t, `{"module":"steam","status":"online"}`, string(statusPayload("online")))
+	assert.Equal(t, `{"module":"steam","status":"offline"}`, string(statusPayload("offline")))
+}
+
+func TestInflightTrackerDrain(t *testing.T) {
+	it := &inflightTracker{}
+
+	assert.True(t, it.start())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		it.done()
+	}()
+
+	assert.True(t, it.drain(time.Second))
+	assert.False(t, it.start())
+}
+
+func TestInflightTrackerDrainTimeout(t *testing.T) {
+	it := &inflightTracker{}
+
+	assert.True(t, it.start())
+	assert.False(t, it.drain(10*time.Millisecond))
+
+	it.done()
+}
+
+func TestInflightTrackerDrainIdle(t *testing.T) {
+	it := &inflightTracker{}
+
+	assert.True(t, it.drain(time.Second))
+}
+
+func TestCustomClientID(t *testing.T) {
+	cases := []struct {
+		name       string
+		id         string
+		suffix     string
+		shareGroup string
+		expected   string
+	}{
+		{name: "Default", expected: "gowon_steam"},
+		{name: "Suffix", suffix: "prod", expected: "gowon_steam_prod"},
+		{name: "Custom", id: "steam-two", expected: "steam-two"},
+		{name: "Custom with suffix", id: "steam", suffix: "2", expected: "steam_2"},
+		{name: "Share group", shareGroup: "steam", suffix: "x", expected: fmt.Sprintf("gowon_steam_host_%d_x", os.Getpid())},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, customClientID(tc.id, tc.suffix, tc.shareGroup, "host"))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+func nextAchievement(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
+	if err != nil {
+		return "", err
+	}
+
+	ps, err := cachedPlayerSummary(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	name := s.displayName(ctx, apiKey, id, user, client)
+
+	appId, err := strconv.Atoi(ps.GameId)
+	if err != nil || appId <= 0 {
+		return s.msg("not_playing", name), nil
+	}
+
+	r, err := fetchEasyAchievements(ctx, apiKey, user, appId, client, s)
+	if err != nil {
+		return "", err
+	}
+
+	if r.Game == "" {
+		r.Game = ps.GameExtraInfo
+	}
+
+	if len(r.Achievements) == 0 {
+		return s.renderEasyAchievements(r), nil
+	}
+
+	return s.msg("next_achievement", name, r.Game, s.formatEasyAchievement(r.Achievements[0])), nil
+}
+
+func nextHandler(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	out, err := nextAchievement(ctx, apiKey, user, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("profile_not_public"), nil
+	}
+
+	return out, err
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		user     string
+		endpoint string
+		fault    mocksteam.Fault
+		out      string
+		err      error
+	}{
+		{
+			name: "Playing a game",
+			user: "gaben",
+			out:  "gaben is playing Portal 2, next up: Lunacy (12.3% of players) - That just happened",
+		},
+		{
+			name: "Not playing",
+			user: "76561197960287931",
+			out:  "76561197960287931 isn't playing anything right now",
+		},
+		{
+			name: "Unknown user",
+			user: "nobody",
+			out:  "Error: no id found for nobody",
+		},
+		{
+			name:     "Private achievements",
+			user:     "gaben",
+			endpoint: "achievements",
+			fault:    mocksteam.Private,
+			out:      "Error: profile is not public",
+		},
+		{
+			name:     "Rate limited",
+			user:     "gaben",
+			endpoint: "summaries",
+			fault:    mocksteam.RateLimited,
+			err:      ErrRateLimited,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject(tc.endpoint, tc.fault)
+
+			out, err := nextHandler(context.Background(), "key", tc.user, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gowon-irc/go-gowon"
+)
+
+const onceNick = "cli"
+
+func runOnce(handler func(m gowon.Message) (string, error), args string, w io.Writer) error {
+	out, err := handler(gowon.Message{
+		Module:  moduleName,
+		Nick:    onceNick,
+		Msg:     fmt.Sprintf(".%s %s", moduleName, args),
+		Command: moduleName,
+		Args:    args,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, out)
+	return err
+}
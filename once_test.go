@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunOnce(t *testing.T) {
+	var got gowon.Message
+	handler := func(m gowon.Message) (string, error) {
+		got = m
+		return "reply", nil
+	}
+
+	w := &bytes.Buffer{}
+	err := runOnce(handler, "r someone", w)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "reply\n", w.String())
+	assert.Equal(t, "r someone", got.Args)
+	assert.Equal(t, "steam", got.Command)
+	assert.Equal(t, "cli", got.Nick)
+}
+
+func TestRunOnceError(t *testing.T) {
+	handler := func(m gowon.Message) (string, error) {
+		return "", errors.New("boom")
+	}
+
+	w := &bytes.Buffer{}
+	err := runOnce(handler, "r someone", w)
+
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, "", w.String())
+}
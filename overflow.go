@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"unicode/utf8"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gowon-irc/go-gowon"
+)
+
+const maxReplyLength = 400
+
+type replyPublisher struct {
+	client mqtt.Client
+	topic  string
+	qos    byte
+}
+
+var replies *replyPublisher
+
+func (rp *replyPublisher) Send(dest, msg string) bool {
+	if rp == nil {
+		return false
+	}
+
+	b, err := json.Marshal(gowon.Message{Module: moduleName, Msg: msg, Dest: dest})
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	rp.client.Publish(rp.topic, rp.qos, false, b)
+	return true
+}
+
+func truncateReply(out string, max int) string {
+	if len(out) <= max {
+		return out
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(out[cut]) {
+		cut--
+	}
+
+	return out[:cut] + "…"
+}
+
+func (s settings) overflow(nick, dest, out string) string {
+	if !s.pmOverflow || len(out) <= maxReplyLength || dest == "" || dest == nick {
+		return out
+	}
+
+	if !replies.Send(nick, out) {
+		return out
+	}
+
+	return fmt.Sprintf("%s %s", truncateReply(out, maxReplyLength), s.formatter.Render(s.msg("sent_pm")))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+type publishRecorder struct {
+	mqtt.Client
+	published [][]byte
+}
+
+func (pr *publishRecorder) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	pr.published = append(pr.published, payload.([]byte))
+	return &mqtt.DummyToken{}
+}
+
+func TestTruncateReply(t *testing.T) {
+	cases := []struct {
+		name     string
+		out      string
+		max      int
+		expected string
+	}{
+		{name: "Short", out: "abc", max: 5, expected: "abc"},
+		{name: "Exact", out: "abcde", max: 5, expected: "abcde"},
+		{name: "Long", out: "abcdef", max: 5, expected: "abcde…"},
+		{name: "Multibyte boundary", out: "abcd▰▰", max: 5, expected: "abcd…"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, truncateReply(tc.out, tc.max))
+		})
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	long := strings.Repeat("a", maxReplyLength+10)
+
+	cases := []struct {
+		name      string
+		pm        bool
+		dest      string
+		out       string
+		expected  string
+		published bool
+	}{
+		{
+			name:     "Short reply",
+			pm:       true,
+			dest:     "#chan",
+			out:      "short",
+			expected: "short",
+		},
+		{
+			name:     "Preference off",
+			pm:       false,
+			dest:     "#chan",
+			out:      long,
+			expected: long,
+		},
+		{
+			name:     "Already private",
+			pm:       true,
+			dest:     "nick",
+			out:      long,
+			expected: long,
+		},
+		{
+			name:      "Sent by pm",
+			pm:        true,
+			dest:      "#chan",
+			out:       long,
+			expected:  strings.Repeat("a", maxReplyLength) + "… (full reply sent by pm)",
+			published: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &publishRecorder{}
+			replies = &replyPublisher{client: pr, topic: gowonOutputTopic}
+			defer func() { replies = nil }()
+
+			s := testSettings
+			s.pmOverflow = tc.pm
+
+			assert.Equal(t, tc.expected, s.overflow("nick", tc.dest, tc.out))
+
+			if !tc.published {
+				assert.Empty(t, pr.published)
+				return
+			}
+
+			assert.Len(t, pr.published, 1)
+			m := gowon.Message{}
+			assert.Nil(t, json.Unmarshal(pr.published[0], &m))
+			assert.Equal(t, "nick", m.Dest)
+			assert.Equal(t, tc.out, m.Msg)
+		})
+	}
+}
+
+func TestOverflowWithoutPublisher(t *testing.T) {
+	long := strings.Repeat("a", maxReplyLength+10)
+
+	s := testSettings
+	s.pmOverflow = true
+
+	assert.Equal(t, long, s.overflow("nick", "#chan", long))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ownedGamesPath     = "/IPlayerService/GetOwnedGames/v1/?key=%s&steamid=%s&include_appinfo=true&include_played_free_games=true"
+	ownedGamesCacheTTL = 10 * time.Minute
+)
+
+type ownedGamesRes struct {
+	Response struct {
+		GameCount *int `json:"game_count"`
+		Games     []ownedGame
+	}
+}
+
+type ownedGame struct {
+	AppId           int
+	Name            string
+	PlaytimeForever int `json:"playtime_forever"`
+}
+
+func (ogr ownedGamesRes) Game(appId int) (ownedGame, bool) {
+	for _, g := range ogr.Response.Games {
+		if g.AppId == appId {
+			return g, true
+		}
+	}
+
+	return ownedGame{}, false
+}
+
+func getOwnedGames(ctx context.Context, apiKey string, id SteamID, client *steamClient) (*ownedGamesRes, error) {
+	j, err := getJSON[ownedGamesRes](ctx, client.apiUrl(ownedGamesPath, apiKey, id), id.String(), client)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.Response.GameCount == nil {
+		return nil, ErrProfilePrivate
+	}
+
+	return j, nil
+}
+
+func cachedOwnedGames(ctx context.Context, apiKey string, id SteamID, client *steamClient) (*ownedGamesRes, error) {
+	key := fmt.Sprintf("owned:%s", id)
+
+	r, err := client.cache.GetOrFetch(ctx, key, ownedGamesCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return getOwnedGames(ctx, apiKey, id, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*ownedGamesRes), nil
+}
+
+func checkOwnership(ctx context.Context, apiKey, user string, appId int, name string, client *steamClient, s settings) (string, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
+	if err != nil {
+		return "", err
+	}
+
+	owned, err := cachedOwnedGames(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	app, ok, err := cachedStoreApp(ctx, appId, client)
+	if err != nil {
+		return "", err
+	}
+
+	if ok && app.Name != "" {
+		name = app.Name
+	}
+
+	if ok && app.Type == "dlc" && app.Fullgame != nil {
+		baseId, _ := strconv.Atoi(app.Fullgame.AppId)
+		base := app.Fullgame.Name
+
+		if _, ok := owned.Game(baseId); ok {
+			return s.msg("owns_dlc_unknown", user, s.formatter.Colour("green", base), name), nil
+		}
+
+		return s.msg("owns_dlc_no_base", user, s.formatter.Colour("red", base), name), nil
+	}
+
+	if _, ok := owned.Game(appId); ok {
+		return s.msg("owns", user, s.formatter.Colour("green", name)), nil
+	}
+
+	return s.msg("owns_not", user, s.formatter.Colour("red", name)), nil
+}
+
+func ownsHandler(ctx context.Context, apiKey string, kv Store, args []string, client *steamClient, s settings) (string, error) {
+	if len(args) < 2 {
+		return s.msg("owns_needed"), nil
+	}
+
+	user, err := nickSteamUser(kv, args[0])
+	if err != nil {
+		return "", err
+	}
+
+	query := strings.Join(args[1:], " ")
+	appId, name, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	if name == "" {
+		name = query
+	}
+
+	out, err := checkOwnership(ctx, apiKey, user, appId, name, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("profile_not_public"), nil
+	}
+
+	return out, err
+}
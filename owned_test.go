@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnsHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		args  []string
+		fault mocksteam.Fault
+		out   string
+		err   error
+	}{
+		{
+			name: "No game",
+			args: []string{"bob"},
+			out:  "Error: nick and game needed",
+		},
+		{
+			name: "Owned game via linked nick",
+			args: []string{"bob", "620"},
+			out:  "gaben owns {green}Portal 2{clear}",
+		},
+		{
+			name: "Game not owned",
+			args: []string{"gaben", "10"},
+			out:  "gaben doesn't own {red}10{clear}",
+		},
+		{
+			name: "Dlc with the base game",
+			args: []string{"gaben", "247120"},
+			out:  "gaben owns {green}Portal 2{clear}, but steam doesn't show whether they own the Portal 2 Sixense Perceptual Pack dlc",
+		},
+		{
+			name: "Dlc without the base game",
+			args: []string{"gaben", "323170"},
+			out:  "gaben doesn't own {red}Half-Life 2{clear}, which Half-Life 2: Update Soundtrack needs",
+		},
+		{
+			name: "Empty library",
+			args: []string{"76561197960287931", "620"},
+			out:  "76561197960287931 doesn't own {red}Portal 2{clear}",
+		},
+		{
+			name: "Unknown user",
+			args: []string{"nobody", "620"},
+			out:  "Error: no id found for nobody",
+		},
+		{
+			name:  "Private library",
+			args:  []string{"gaben", "620"},
+			fault: mocksteam.Private,
+			out:   "Error: profile is not public",
+		},
+		{
+			name:  "Rate limited",
+			args:  []string{"gaben", "620"},
+			fault: mocksteam.RateLimited,
+			err:   ErrRateLimited,
+		},
+	}
+
+	kv := openTestDB(t)
+	assert.Nil(t, setUser(kv, []byte("bob"), []byte("gaben")))
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("owned", tc.fault)
+
+			out, err := ownsHandler(context.Background(), "key", kv, tc.args, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	currentPlayersPath     = "/ISteamUserStats/GetNumberOfCurrentPlayers/v1/?appid=%d"
+	playerHistoryRetention = 8 * 24 * time.Hour
+	playerHistoryWeek      = 7 * 24 * time.Hour
+	playerTrendWindow      = 24 * time.Hour
+	playerTrendThreshold   = 0.05
+	playerSampleTimeout    = time.Minute
+)
+
+type currentPlayersRes struct {
+	Response struct {
+		PlayerCount int `json:"player_count"`
+		Result      int
+	}
+}
+
+type playerSample struct {
+	Time  time.Time
+	Count int
+}
+
+type playerHistory struct {
+	Latest    playerSample
+	TodayPeak int
+	WeekPeak  int
+	Trend     int
+}
+
+func playersBucket(appId int) []byte {
+	return []byte(fmt.Sprintf("players:%d", appId))
+}
+
+func getCurrentPlayers(ctx context.Context, appId int, client *steamClient) (int, error) {
+	j, err := getJSON[currentPlayersRes](ctx, client.apiUrl(currentPlayersPath, appId), "", client)
+	if err != nil {
+		return 0, err
+	}
+
+	if j.Response.Result != 1 {
+		return 0, wrapAPIError(client.apiUrl(currentPlayersPath, appId), "", fmt.Errorf("no player count for app %d", appId))
+	}
+
+	return j.Response.PlayerCount, nil
+}
+
+func recordPlayerCount(kv Store, appId int, now time.Time, count int) error {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(now.Unix()))
+
+	return kv.Update(playersBucket(appId), func(b Bucket) error {
+		expired := [][]byte{}
+		cutoff := uint64(now.Add(-playerHistoryRetention).Unix())
+
+		err := b.ForEach(func(k, v []byte) error {
+			if binary.BigEndian.Uint64(k) < cutoff {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, e := range expired {
+			if err := b.Delete(e); err != nil {
+				return err
+			}
+		}
+
+		return b.Put(k, []byte(strconv.Itoa(count)))
+	})
+}
+
+func playerSamples(kv Store, appId int, since time.Time) ([]playerSample, error) {
+	samples := []playerSample{}
+	from := uint64(since.Unix())
+
+	err := kv.View(playersBucket(appId), func(b Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			t := binary.BigEndian.Uint64(k)
+			if t < from {
+				return nil
+			}
+
+			count, err := strconv.Atoi(string(v))
+			if err != nil {
+				return err
+			}
+
+			samples = append(samples, playerSample{Time: time.Unix(int64(t), 0), Count: count})
+			return nil
+		})
+	})
+
+	return samples, err
+}
+
+func summarisePlayers(samples []playerSample, now time.Time, loc *time.Location) (playerHistory, bool) {
+	if len(samples) == 0 {
+		return playerHistory{}, false
+	}
+
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	weekAgo := now.Add(-playerHistoryWeek)
+	dayAgo := now.Add(-playerTrendWindow)
+
+	h := playerHistory{Latest: samples[len(samples)-1]}
+	previous := playerSample{}
+
+	for _, s := range samples {
+		if !s.Time.Before(today) && s.Count > h.TodayPeak {
+			h.TodayPeak = s.Count
+		}
+
+		if !s.Time.Before(weekAgo) && s.Count > h.WeekPeak {
+			h.WeekPeak = s.Count
+		}
+
+		if !s.Time.After(dayAgo) {
+			previous = s
+		}
+	}
+
+	if previous.Count > 0 {
+		change := float64(h.Latest.Count-previous.Count) / float64(previous.Count)
+		switch {
+		case change > playerTrendThreshold:
+			h.Trend = 1
+		case change < -playerTrendThreshold:
+			h.Trend = -1
+		}
+	}
+
+	return h, true
+}
+
+func (s settings) trendArrow(trend int) string {
+	arrows := []string{"↓", "→", "↑"}
+	if s.asciiBars {
+		arrows = []string{"v", "-", "^"}
+	}
+
+	return arrows[trend+1]
+}
+
+func playersHistoryHandler(kv Store, args []string, s settings, now time.Time) (string, error) {
+	query := strings.Join(args, " ")
+
+	appId, name, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	if name == "" {
+		name = query
+	}
+
+	samples, err := playerSamples(kv, appId, now.Add(-playerHistoryWeek))
+	if err != nil {
+		return "", err
+	}
+
+	h, ok := summarisePlayers(samples, now, s.location)
+	if !ok {
+		return s.msg("players_history_none", name), nil
+	}
+
+	return s.msg("players_history", s.formatter.Colour("green", name), h.Latest.Count, h.TodayPeak, h.WeekPeak, s.trendArrow(h.Trend)), nil
+}
+
+func parseWatchedGames(in []string) ([]int, error) {
+	ids := []int{}
+
+	for _, g := range in {
+		id, err := strconv.Atoi(strings.TrimSpace(g))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("watched game %s must be a steam app id", g)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func samplePlayers(ctx context.Context, kv Store, appIds []int, client *steamClient, now time.Time) (failed error) {
+	for _, id := range appIds {
+		count, err := getCurrentPlayers(ctx, id, client)
+		if err == nil {
+			err = recordPlayerCount(kv, id, now, count)
+		}
+
+		if err != nil {
+			failed = err
+		}
+	}
+
+	return failed
+}
+
+func runPlayerSampler(kv Store, appIds []int, client *steamClient, interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), playerSampleTimeout)
+		err := samplePlayers(ctx, kv, appIds, client, wallClock.Now())
+		cancel()
+
+		if err != nil {
+			log.Printf("player count sampling failed: %s\n", sanitiseError(err))
+		}
+
+		time.Sleep(interval)
+	}
+}
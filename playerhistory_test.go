@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordPlayerCount(t *testing.T) {
+	kv := openTestDB(t)
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, recordPlayerCount(kv, 620, now.Add(-9*24*time.Hour), 1))
+	assert.Nil(t, recordPlayerCount(kv, 620, now.Add(-time.Hour), 2))
+	assert.Nil(t, recordPlayerCount(kv, 400, now.Add(-time.Hour), 5))
+	assert.Nil(t, recordPlayerCount(kv, 620, now, 3))
+
+	samples, err := playerSamples(kv, 620, time.Unix(0, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, []playerSample{
+		{Time: time.Unix(now.Add(-time.Hour).Unix(), 0), Count: 2},
+		{Time: time.Unix(now.Unix(), 0), Count: 3},
+	}, samples, "old samples pruned and other games kept apart")
+
+	samples, err = playerSamples(kv, 620, now.Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Len(t, samples, 1)
+
+	samples, err = playerSamples(kv, 730, time.Unix(0, 0))
+	assert.Nil(t, err)
+	assert.Empty(t, samples)
+}
+
+func TestSummarisePlayers(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	sample := func(ago time.Duration, count int) playerSample {
+		return playerSample{Time: now.Add(-ago), Count: count}
+	}
+
+	cases := []struct {
+		name    string
+		samples []playerSample
+		out     playerHistory
+		ok      bool
+	}{
+		{
+			name: "No samples",
+		},
+		{
+			name:    "Rising",
+			samples: []playerSample{sample(3*24*time.Hour, 900), sample(25*time.Hour, 100), sample(13*time.Hour, 300), sample(time.Hour, 200)},
+			out:     playerHistory{Latest: sample(time.Hour, 200), TodayPeak: 200, WeekPeak: 900, Trend: 1},
+			ok:      true,
+		},
+		{
+			name:    "Falling",
+			samples: []playerSample{sample(24*time.Hour, 200), sample(0, 100)},
+			out:     playerHistory{Latest: sample(0, 100), TodayPeak: 100, WeekPeak: 200, Trend: -1},
+			ok:      true,
+		},
+		{
+			name:    "Steady",
+			samples: []playerSample{sample(30*time.Hour, 1000), sample(0, 1020)},
+			out:     playerHistory{Latest: sample(0, 1020), TodayPeak: 1020, WeekPeak: 1020},
+			ok:      true,
+		},
+		{
+			name:    "No sample a day ago",
+			samples: []playerSample{sample(2*time.Hour, 10), sample(0, 100)},
+			out:     playerHistory{Latest: sample(0, 100), TodayPeak: 100, WeekPeak: 100},
+			ok:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, ok := summarisePlayers(tc.samples, now, time.UTC)
+
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestPlayersHistoryHandler(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		args  []string
+		ascii bool
+		out   string
+	}{
+		{
+			name: "Not watched",
+			args: []string{"400"},
+			out:  "no player counts recorded for 400, is it watched?",
+		},
+		{
+			name: "Watched",
+			args: []string{"620"},
+			out:  "{green}620{clear} - 150 playing now, peak today 150, 7 day peak 300 ↓",
+		},
+		{
+			name:  "Ascii arrows",
+			args:  []string{"620"},
+			ascii: true,
+			out:   "{green}620{clear} - 150 playing now, peak today 150, 7 day peak 300 v",
+		},
+	}
+
+	kv := openTestDB(t)
+	assert.Nil(t, recordPlayerCount(kv, 620, now.Add(-26*time.Hour), 300))
+	assert.Nil(t, recordPlayerCount(kv, 620, now.Add(-time.Hour), 150))
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.asciiBars = tc.ascii
+
+			out, err := playersHistoryHandler(kv, tc.args, s, now)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestParseWatchedGames(t *testing.T) {
+	ids, err := parseWatchedGames([]string{"620", " 400"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{620, 400}, ids)
+
+	_, err = parseWatchedGames([]string{"0"})
+	assert.EqualError(t, err, "watched game 0 must be a steam app id")
+}
+
+func TestSamplePlayers(t *testing.T) {
+	ms, client := useMockSteam(t)
+	kv := openTestDB(t)
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	err := samplePlayers(context.Background(), kv, []int{1, 620}, client, now)
+	assert.ErrorContains(t, err, "no player count for app 1")
+
+	samples, err := playerSamples(kv, 620, now)
+	assert.Nil(t, err)
+	assert.Equal(t, []playerSample{{Time: time.Unix(now.Unix(), 0), Count: 4321}}, samples, "other games still sampled")
+
+	ms.Inject("players", mocksteam.RateLimited)
+	err = samplePlayers(context.Background(), kv, []int{620}, client, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
@@ -0,0 +1,31 @@
+package main
+
+const (
+	timezonePref   = "timezone"
+	dateFormatPref = "dateformat"
+	languagePref   = "language"
+	verbosePref    = "verbose"
+	personaPref    = "persona"
+	spoilersPref   = "spoilers"
+	pmPref         = "pm"
+)
+
+func prefBucket(pref string) []byte {
+	return []byte("pref_" + pref)
+}
+
+func setPref(kv Store, pref string, nick, value []byte) error {
+	err := kv.Update(prefBucket(pref), func(b Bucket) error {
+		return b.Put(nick, value)
+	})
+	return err
+}
+
+func getPref(kv Store, pref string, nick []byte) (value []byte, err error) {
+	value = []byte{}
+	err = kv.View(prefBucket(pref), func(b Bucket) error {
+		value = append(value, b.Get(nick)...)
+		return nil
+	})
+	return value, err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gowon-irc/go-gowon"
+)
+
+const maxLinkPreviews = 3
+
+var linkPreviewRegex = storeLinkRegex + "|" + communityLinkRegex + "|" + workshopLinkRegex
+
+func linkPreviews(ctx context.Context, apiKey, msg string, client *steamClient, s settings) (string, error) {
+	lines, err := storeLinkPreviews(ctx, msg, client, s)
+	if err != nil {
+		return "", err
+	}
+
+	profiles, err := profileLinkPreviews(ctx, apiKey, msg, client, s)
+	if err != nil {
+		return "", err
+	}
+
+	lines = append(lines, profiles...)
+
+	workshop, err := workshopLinkPreviews(ctx, apiKey, msg, client, s)
+	if err != nil {
+		return "", err
+	}
+
+	lines = append(lines, workshop...)
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	return s.formatter.Lines(lines), nil
+}
+
+func genLinkPreviewHandler(apiKey string, kv Store, client *steamClient, defaults settings, timeout time.Duration, retryBudget int) func(m gowon.Message) (string, error) {
+	return func(m gowon.Message) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		ctx = withRetryBudget(ctx, retryBudget)
+
+		s, err := requestSettings(kv, defaults, m.Nick, m.Dest)
+		if err != nil {
+			return "", err
+		}
+
+		out, err := linkPreviews(ctx, apiKey, m.Msg, client, s)
+		errorAlerts.Record(err, wallClock.Now())
+		if err != nil {
+			log.Printf("link preview failed: %s\n", err)
+			return "", nil
+		}
+
+		return s.formatter.Render(out), nil
+	}
+}
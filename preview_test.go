@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkPreviewRegex(t *testing.T) {
+	re := regexp.MustCompile(linkPreviewRegex)
+
+	assert.True(t, re.MatchString("https://store.steampowered.com/app/620/Portal_2/"))
+	assert.True(t, re.MatchString("https://steamcommunity.com/id/beefslayer99"))
+	assert.True(t, re.MatchString("https://steamcommunity.com/sharedfiles/filedetails/?id=42"))
+	assert.False(t, re.MatchString("https://example.com/app/620"))
+}
+
+func TestLinkPreviews(t *testing.T) {
+	client := NewConditionalTestClient(map[string]string{
+		testClient.storeUrl(appDetailsPath, 620):             testAppDetails,
+		testClient.storeUrl(appReviewsPath, 620):             testAppReviews,
+		testClient.apiUrl(playerSummariesPath, "key", "999"): `{"response":{"players":[{"steamid":"999","personaname":"Bob"}]}}`,
+		testClient.apiUrl(steamLevelPath, "key", "999"):      `{"response":{"player_level":12}}`,
+		testClient.apiUrl(playerBansPath, "key", "999"):      `{"players":[{"VACBanned":false}]}`,
+	})
+
+	out, err := linkPreviews(context.Background(), "key", "store.steampowered.com/app/620 steamcommunity.com/profiles/999", client, testSettings)
+	assert.Nil(t, err)
+	assert.Equal(t, "{green}Portal 2{clear} - £7.19 - Overwhelmingly Positive (98% of 1000 reviews positive) | {green}Bob{clear} - level 12 - not in game - no VAC bans", out)
+
+	out, err = linkPreviews(context.Background(), "key", "nothing to see", client, testSettings)
+	assert.Nil(t, err)
+	assert.Equal(t, "", out)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	steamLevelPath     = "/IPlayerService/GetSteamLevel/v1/?key=%s&steamid=%s"
+	playerBansPath     = "/ISteamUser/GetPlayerBans/v1/?key=%s&steamids=%s"
+	communityLinkRegex = `steamcommunity\.com/(id|profiles)/([^/\s?#]+)`
+	profileCacheTTL    = time.Hour
+)
+
+var communityLinkRe = regexp.MustCompile(communityLinkRegex)
+
+type steamLevelRes struct {
+	Response struct {
+		PlayerLevel int `json:"player_level"`
+	}
+}
+
+type playerBansRes struct {
+	Players []playerBans
+}
+
+type playerBans struct {
+	VACBanned        bool
+	NumberOfVACBans  int
+	DaysSinceLastBan int
+}
+
+type communityLink struct {
+	kind string
+	name string
+}
+
+func getSteamLevel(ctx context.Context, apiKey string, id SteamID, client *steamClient) (int, error) {
+	j, err := getJSON[steamLevelRes](ctx, client.apiUrl(steamLevelPath, apiKey, id), id.String(), client)
+	if err != nil {
+		return 0, err
+	}
+
+	return j.Response.PlayerLevel, nil
+}
+
+func getPlayerBans(ctx context.Context, apiKey string, id SteamID, client *steamClient) (playerBans, error) {
+	j, err := getJSON[playerBansRes](ctx, client.apiUrl(playerBansPath, apiKey, id), id.String(), client)
+	if err != nil {
+		return playerBans{}, err
+	}
+
+	if len(j.Players) == 0 {
+		return playerBans{}, ErrProfileNotFound
+	}
+
+	return j.Players[0], nil
+}
+
+func cachedSteamLevel(ctx context.Context, apiKey string, id SteamID, client *steamClient) (int, error) {
+	l, err := client.cache.GetOrFetch(ctx, fmt.Sprintf("level:%s", id), profileCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return getSteamLevel(ctx, apiKey, id, client)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return l.(int), nil
+}
+
+func cachedPlayerBans(ctx context.Context, apiKey string, id SteamID, client *steamClient) (playerBans, error) {
+	b, err := client.cache.GetOrFetch(ctx, fmt.Sprintf("bans:%s", id), profileCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return getPlayerBans(ctx, apiKey, id, client)
+	})
+	if err != nil {
+		return playerBans{}, err
+	}
+
+	return b.(playerBans), nil
+}
+
+func communityLinks(msg string) []communityLink {
+	links := []communityLink{}
+	seen := map[communityLink]bool{}
+
+	for _, m := range communityLinkRe.FindAllStringSubmatch(msg, -1) {
+		l := communityLink{kind: m[1], name: m[2]}
+		if seen[l] {
+			continue
+		}
+
+		seen[l] = true
+		links = append(links, l)
+
+		if len(links) == maxLinkPreviews {
+			break
+		}
+	}
+
+	return links
+}
+
+func resolveCommunityLink(ctx context.Context, apiKey string, l communityLink, client *steamClient) (SteamID, error) {
+	if l.kind == "profiles" {
+		return parseSteamID64(l.name)
+	}
+
+	return cachedSteamGetId(ctx, apiKey, l.name, client)
+}
+
+func (s settings) formatBans(b playerBans) string {
+	if !b.VACBanned && b.NumberOfVACBans == 0 {
+		return s.msg("vac_clean")
+	}
+
+	return s.msg("vac_banned", b.NumberOfVACBans, b.DaysSinceLastBan)
+}
+
+func (s settings) formatCurrentGame(ps playerSummary) string {
+	if ps.GameExtraInfo == "" {
+		return s.msg("profile_not_playing")
+	}
+
+	return s.msg("profile_playing", ps.GameExtraInfo)
+}
+
+func profilePreview(ctx context.Context, apiKey string, id SteamID, client *steamClient, s settings) (string, error) {
+	ps, err := cachedPlayerSummary(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	level, err := cachedSteamLevel(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	bans, err := cachedPlayerBans(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	return s.msg("profile_preview", s.formatter.Colour("green", ps.PersonaName), level, s.formatCurrentGame(ps), s.formatBans(bans)), nil
+}
+
+func profileLinkPreviews(ctx context.Context, apiKey, msg string, client *steamClient, s settings) ([]string, error) {
+	lines := []string{}
+
+	for _, l := range communityLinks(msg) {
+		id, err := resolveCommunityLink(ctx, apiKey, l, client)
+		if errors.Is(err, ErrProfileNotFound) {
+			continue
+		}
+		if err != nil {
+			return lines, err
+		}
+
+		out, err := profilePreview(ctx, apiKey, id, client, s)
+		if errors.Is(err, ErrProfileNotFound) {
+			continue
+		}
+		if err != nil {
+			return lines, err
+		}
+
+		lines = append(lines, out)
+	}
+
+	return lines, nil
+}
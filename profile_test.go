@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunityLinks(t *testing.T) {
+	cases := []struct {
+		name  string
+		msg   string
+		links []communityLink
+	}{
+		{
+			name:  "No links",
+			msg:   "hello",
+			links: []communityLink{},
+		},
+		{
+			name:  "Vanity link",
+			msg:   "https://steamcommunity.com/id/beefslayer99/",
+			links: []communityLink{{kind: "id", name: "beefslayer99"}},
+		},
+		{
+			name:  "Profile link",
+			msg:   "steamcommunity.com/profiles/76561197960287930?l=english",
+			links: []communityLink{{kind: "profiles", name: "76561197960287930"}},
+		},
+		{
+			name:  "Duplicate links",
+			msg:   "steamcommunity.com/id/a steamcommunity.com/id/a/games",
+			links: []communityLink{{kind: "id", name: "a"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.links, communityLinks(tc.msg))
+		})
+	}
+}
+
+func TestGetPlayerBans(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		out    playerBans
+		errMsg string
+	}{
+		{
+			name:   "Empty data returned",
+			body:   "",
+			errMsg: "unexpected end of JSON input",
+		},
+		{
+			name:   "No players",
+			body:   `{"players":[]}`,
+			errMsg: "id not found",
+		},
+		{
+			name: "Banned",
+			body: `{"players":[{"SteamId":"999","VACBanned":true,"NumberOfVACBans":2,"DaysSinceLastBan":30}]}`,
+			out:  playerBans{VACBanned: true, NumberOfVACBans: 2, DaysSinceLastBan: 30},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(200, tc.body)
+			b, err := getPlayerBans(context.Background(), "key", 999, client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.out, b)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestProfileLinkPreviews(t *testing.T) {
+	cases := []struct {
+		name     string
+		msg      string
+		summary  string
+		bans     string
+		expected []string
+	}{
+		{
+			name:     "Playing and clean",
+			msg:      "https://steamcommunity.com/id/beefslayer99",
+			summary:  `{"response":{"players":[{"steamid":"999","personaname":"Bob","gameextrainfo":"Portal 2"}]}}`,
+			bans:     `{"players":[{"VACBanned":false}]}`,
+			expected: []string{"{green}Bob{clear} - level 12 - playing Portal 2 - no VAC bans"},
+		},
+		{
+			name:     "Not playing and banned",
+			msg:      "https://steamcommunity.com/profiles/999",
+			summary:  `{"response":{"players":[{"steamid":"999","personaname":"Bob"}]}}`,
+			bans:     `{"players":[{"VACBanned":true,"NumberOfVACBans":1,"DaysSinceLastBan":5}]}`,
+			expected: []string{"{green}Bob{clear} - level 12 - not in game - 1 VAC bans, last 5 days ago"},
+		},
+		{
+			name:     "Unknown profile",
+			msg:      "https://steamcommunity.com/profiles/999",
+			summary:  `{"response":{"players":[]}}`,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewConditionalTestClient(map[string]string{
+				testClient.apiUrl(resolveVanityPath, "key", "beefslayer99"): `{"response":{"steamid":"999","success":1}}`,
+				testClient.apiUrl(playerSummariesPath, "key", "999"):        tc.summary,
+				testClient.apiUrl(steamLevelPath, "key", "999"):             `{"response":{"player_level":12}}`,
+				testClient.apiUrl(playerBansPath, "key", "999"):             tc.bans,
+			})
+
+			out, err := profileLinkPreviews(context.Background(), "key", tc.msg, client, testSettings)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
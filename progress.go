@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const progressBarWidth = 5
+
+func progressBar(achieved, total, width int, ascii bool) string {
+	if total == 0 {
+		return ""
+	}
+
+	filled := achieved * width / total
+	percent := achieved * 100 / total
+
+	full, empty := "▰", "▱"
+	if ascii {
+		full, empty = "#", "-"
+	}
+
+	bar := strings.Repeat(full, filled) + strings.Repeat(empty, width-filled)
+
+	if ascii {
+		bar = fmt.Sprintf("[%s]", bar)
+	}
+
+	return fmt.Sprintf("%s %d%%", bar, percent)
+}
+
+func (s settings) progressBar(achieved, total int) string {
+	return progressBar(achieved, total, progressBarWidth, s.asciiBars)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressBar(t *testing.T) {
+	cases := []struct {
+		name     string
+		achieved int
+		total    int
+		ascii    bool
+		out      string
+	}{
+		{
+			name:     "No achievements",
+			achieved: 0,
+			total:    0,
+			out:      "",
+		},
+		{
+			name:     "None achieved",
+			achieved: 0,
+			total:    3,
+			out:      "▱▱▱▱▱ 0%",
+		},
+		{
+			name:     "Partially achieved",
+			achieved: 7,
+			total:    12,
+			out:      "▰▰▱▱▱ 58%",
+		},
+		{
+			name:     "All achieved",
+			achieved: 3,
+			total:    3,
+			out:      "▰▰▰▰▰ 100%",
+		},
+		{
+			name:     "Ascii",
+			achieved: 7,
+			total:    12,
+			ascii:    true,
+			out:      "[##---] 58%",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, progressBar(tc.achieved, tc.total, 5, tc.ascii))
+		})
+	}
+}
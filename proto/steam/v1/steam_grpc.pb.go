@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: proto/steam/v1/steam.proto
+
+package steamv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SteamService_Recent_FullMethodName      = "/gowon.steam.v1.SteamService/Recent"
+	SteamService_Achievement_FullMethodName = "/gowon.steam.v1.SteamService/Achievement"
+	SteamService_GetUser_FullMethodName     = "/gowon.steam.v1.SteamService/GetUser"
+	SteamService_SetUser_FullMethodName     = "/gowon.steam.v1.SteamService/SetUser"
+)
+
+// SteamServiceClient is the client API for SteamService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SteamServiceClient interface {
+	Recent(ctx context.Context, in *RecentRequest, opts ...grpc.CallOption) (*RecentReply, error)
+	Achievement(ctx context.Context, in *AchievementRequest, opts ...grpc.CallOption) (*AchievementReply, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserReply, error)
+	SetUser(ctx context.Context, in *SetUserRequest, opts ...grpc.CallOption) (*SetUserReply, error)
+}
+
+type steamServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSteamServiceClient(cc grpc.ClientConnInterface) SteamServiceClient {
+	return &steamServiceClient{cc}
+}
+
+func (c *steamServiceClient) Recent(ctx context.Context, in *RecentRequest, opts ...grpc.CallOption) (*RecentReply, error) {
+	out := new(RecentReply)
+	err := c.cc.Invoke(ctx, SteamService_Recent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *steamServiceClient) Achievement(ctx context.Context, in *AchievementRequest, opts ...grpc.CallOption) (*AchievementReply, error) {
+	out := new(AchievementReply)
+	err := c.cc.Invoke(ctx, SteamService_Achievement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *steamServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserReply, error) {
+	out := new(GetUserReply)
+	err := c.cc.Invoke(ctx, SteamService_GetUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *steamServiceClient) SetUser(ctx context.Context, in *SetUserRequest, opts ...grpc.CallOption) (*SetUserReply, error) {
+	out := new(SetUserReply)
+	err := c.cc.Invoke(ctx, SteamService_SetUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SteamServiceServer is the server API for SteamService service.
+// All implementations must embed UnimplementedSteamServiceServer
+// for forward compatibility
+type SteamServiceServer interface {
+	Recent(context.Context, *RecentRequest) (*RecentReply, error)
+	Achievement(context.Context, *AchievementRequest) (*AchievementReply, error)
+	GetUser(context.Context, *GetUserRequest) (*GetUserReply, error)
+	SetUser(context.Context, *SetUserRequest) (*SetUserReply, error)
+	mustEmbedUnimplementedSteamServiceServer()
+}
+
+// UnimplementedSteamServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSteamServiceServer struct {
+}
+
+func (UnimplementedSteamServiceServer) Recent(context.Context, *RecentRequest) (*RecentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Recent not implemented")
+}
+func (UnimplementedSteamServiceServer) Achievement(context.Context, *AchievementRequest) (*AchievementReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Achievement not implemented")
+}
+func (UnimplementedSteamServiceServer) GetUser(context.Context, *GetUserRequest) (*GetUserReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedSteamServiceServer) SetUser(context.Context, *SetUserRequest) (*SetUserReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUser not implemented")
+}
+func (UnimplementedSteamServiceServer) mustEmbedUnimplementedSteamServiceServer() {}
+
+// UnsafeSteamServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SteamServiceServer will
+// result in compilation errors.
+type UnsafeSteamServiceServer interface {
+	mustEmbedUnimplementedSteamServiceServer()
+}
+
+func RegisterSteamServiceServer(s grpc.ServiceRegistrar, srv SteamServiceServer) {
+	s.RegisterService(&SteamService_ServiceDesc, srv)
+}
+
+func _SteamService_Recent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SteamServiceServer).Recent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SteamService_Recent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SteamServiceServer).Recent(ctx, req.(*RecentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SteamService_Achievement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AchievementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SteamServiceServer).Achievement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SteamService_Achievement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SteamServiceServer).Achievement(ctx, req.(*AchievementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SteamService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SteamServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SteamService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SteamServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SteamService_SetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SteamServiceServer).SetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SteamService_SetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SteamServiceServer).SetUser(ctx, req.(*SetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SteamService_ServiceDesc is the grpc.ServiceDesc for SteamService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SteamService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gowon.steam.v1.SteamService",
+	HandlerType: (*SteamServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Recent",
+			Handler:    _SteamService_Recent_Handler,
+		},
+		{
+			MethodName: "Achievement",
+			Handler:    _SteamService_Achievement_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _SteamService_GetUser_Handler,
+		},
+		{
+			MethodName: "SetUser",
+			Handler:    _SteamService_SetUser_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/steam/v1/steam.proto",
+}
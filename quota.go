@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+var apiKeyRing *keyRing
+
+type quotaUsage struct {
+	used  int
+	limit int
+}
+
+func (kr *keyRing) Usage(now time.Time) []quotaUsage {
+	out := []quotaUsage{}
+
+	for _, b := range kr.budgets {
+		used, limit := b.Used(now)
+		out = append(out, quotaUsage{used: used, limit: limit})
+	}
+
+	return out
+}
+
+func currentQuota(now time.Time) []quotaUsage {
+	if apiKeyRing != nil {
+		return apiKeyRing.Usage(now)
+	}
+
+	used, limit := apiBudget.Used(now)
+
+	return []quotaUsage{{used: used, limit: limit}}
+}
+
+func (q quotaUsage) percent() float64 {
+	if q.limit <= 0 {
+		return 0
+	}
+
+	return float64(q.used) * 100 / float64(q.limit)
+}
+
+func (s settings) quotaMessage(now time.Time) string {
+	out := []string{}
+
+	for n, q := range currentQuota(now) {
+		if q.limit <= 0 {
+			out = append(out, s.msg("quota_key", n+1, s.formatQuota(q.used, q.limit)))
+			continue
+		}
+
+		out = append(out, s.msg("quota_key_percent", n+1, s.formatQuota(q.used, q.limit), q.percent()))
+	}
+
+	return strings.Join(out, ", ")
+}
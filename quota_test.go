@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaMessage(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name     string
+		budget   *dailyBudget
+		ring     *keyRing
+		takes    int
+		expected string
+	}{
+		{
+			name:     "Single key",
+			budget:   &dailyBudget{limit: 200},
+			takes:    3,
+			expected: "key 1: 3/200 api requests today (1.5%)",
+		},
+		{
+			name:     "Single key unlimited",
+			budget:   &dailyBudget{},
+			takes:    3,
+			expected: "key 1: 3 api requests today",
+		},
+		{
+			name:     "Key ring",
+			ring:     newKeyRing([]string{"a", "b"}, 4, time.Minute),
+			takes:    3,
+			expected: "key 1: 2/4 api requests today (50.0%), key 2: 1/4 api requests today (25.0%)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiBudget, apiKeyRing = tc.budget, tc.ring
+			defer func() { apiBudget, apiKeyRing = nil, nil }()
+
+			for i := 0; i < tc.takes; i++ {
+				if tc.ring != nil {
+					tc.ring.take(now)
+				} else {
+					tc.budget.take(now)
+				}
+			}
+
+			assert.Equal(t, tc.expected, testSettings.quotaMessage(now))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	globalPercentagesPath = "/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v0002/?gameid=%d&format=json"
+)
+
+var percentagesCacheTTL = 24 * time.Hour
+
+type percentage float64
+
+func (p *percentage) UnmarshalJSON(b []byte) error {
+	var f float64
+	if err := json.Unmarshal(b, &f); err == nil {
+		*p = percentage(f)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	*p = percentage(f)
+	return nil
+}
+
+type globalPercentagesRes struct {
+	AchievementPercentages struct {
+		Achievements []struct {
+			Name    string
+			Percent percentage
+		}
+	}
+}
+
+func (gpr globalPercentagesRes) Map() map[string]float64 {
+	out := make(map[string]float64)
+
+	for _, a := range gpr.AchievementPercentages.Achievements {
+		out[a.Name] = float64(a.Percent)
+	}
+
+	return out
+}
+
+func getGlobalPercentages(ctx context.Context, appId int, client *steamClient) (*globalPercentagesRes, error) {
+	return getJSON[globalPercentagesRes](ctx, client.apiUrl(globalPercentagesPath, appId), "", client)
+}
+
+func cachedGlobalPercentages(ctx context.Context, appId int, client *steamClient) (map[string]float64, error) {
+	key := fmt.Sprintf("percentages:%d", appId)
+
+	p, err := client.cache.GetOrFetch(ctx, key, percentagesCacheTTL, func(ctx context.Context) (interface{}, error) {
+		p := map[string]float64{}
+		if diskCache.Get(key, &p) {
+			return p, nil
+		}
+
+		gpr, err := getGlobalPercentages(ctx, appId, client)
+		if err != nil {
+			return nil, err
+		}
+
+		p = gpr.Map()
+		diskCache.Set(key, p)
+
+		return p, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.(map[string]float64), nil
+}
+
+func achievementRarity(ctx context.Context, appId int, apiName string, client *steamClient) (float64, bool) {
+	p, err := cachedGlobalPercentages(ctx, appId, client)
+	if err != nil {
+		return 0, false
+	}
+
+	percent, ok := p[apiName]
+
+	return percent, ok
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGlobalPercentages(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		out    map[string]float64
+		errMsg string
+	}{
+		{
+			name:   "Empty data returned",
+			body:   "",
+			out:    nil,
+			errMsg: "unexpected end of JSON input",
+		},
+		{
+			name: "Numeric percentages",
+			body: `{"achievementpercentages":{"achievements":[{"name":"a","percent":4.3}]}}`,
+			out:  map[string]float64{"a": 4.3},
+		},
+		{
+			name: "Quoted percentages",
+			body: `{"achievementpercentages":{"achievements":[{"name":"a","percent":"4.3"}]}}`,
+			out:  map[string]float64{"a": 4.3},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(200, tc.body)
+
+			gpr, err := getGlobalPercentages(context.Background(), 1, client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.out, gpr.Map())
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestCachedGlobalPercentages(t *testing.T) {
+	calls := 0
+	body := `{"achievementpercentages":{"achievements":[{"name":"a","percent":4.3}]}}`
+	client := NewTestClient(200, body)
+	client.httpClient.Transport = countingTransport(client.httpClient.Transport, &calls)
+
+	client.cache = newLRUCache(10, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		p, err := cachedGlobalPercentages(context.Background(), 1, client)
+		assert.Nil(t, err)
+		assert.Equal(t, 4.3, p["a"])
+	}
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestCachedGlobalPercentagesTTL(t *testing.T) {
+	calls := 0
+	body := `{"achievementpercentages":{"achievements":[{"name":"a","percent":4.3}]}}`
+	client := NewTestClient(200, body)
+	client.httpClient.Transport = countingTransport(client.httpClient.Transport, &calls)
+
+	client.cache = newLRUCache(10, time.Nanosecond)
+
+	for i := 0; i < 2; i++ {
+		_, err := cachedGlobalPercentages(context.Background(), 1, client)
+		assert.Nil(t, err)
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, 1, calls)
+}
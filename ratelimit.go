@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var dailyBudgetErr = errors.New("daily steam api budget exhausted")
+
+const quotaWarnPercent = 90
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (tb *tokenBucket) reserve(now time.Time) time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+func (tb *tokenBucket) wait(ctx context.Context) error {
+	d := tb.reserve(time.Now())
+	if d == 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+type dailyBudget struct {
+	mu     sync.Mutex
+	name   string
+	limit  int
+	day    string
+	used   int
+	warned bool
+}
+
+var apiBudget *dailyBudget
+
+func (db *dailyBudget) reset(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if day != db.day {
+		db.day = day
+		db.used = 0
+		db.warned = false
+	}
+}
+
+func (db *dailyBudget) take(now time.Time) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.reset(now)
+
+	if db.limit > 0 && db.used >= db.limit {
+		return false
+	}
+
+	db.used++
+
+	if db.limit > 0 && !db.warned && db.used*100 >= db.limit*quotaWarnPercent {
+		db.warned = true
+		log.Printf("%s has used %d of %d daily steam api requests\n", db.name, db.used, db.limit)
+	}
+
+	return true
+}
+
+func (db *dailyBudget) Used(now time.Time) (used, limit int) {
+	if db == nil {
+		return 0, 0
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.reset(now)
+
+	return db.used, db.limit
+}
+
+type rateLimitTransport struct {
+	next   http.RoundTripper
+	hosts  map[string]bool
+	bucket *tokenBucket
+	budget *dailyBudget
+}
+
+func (rlt *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !rlt.hosts[req.URL.Host] {
+		return rlt.next.RoundTrip(req)
+	}
+
+	if rlt.budget != nil && !rlt.budget.take(time.Now()) {
+		return nil, dailyBudgetErr
+	}
+
+	if rlt.bucket != nil {
+		if err := rlt.bucket.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	return rlt.next.RoundTrip(req)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	start := time.Now()
+	tb := newTokenBucket(2, 2)
+	tb.last = start
+
+	assert.Equal(t, time.Duration(0), tb.reserve(start))
+	assert.Equal(t, time.Duration(0), tb.reserve(start))
+	assert.Equal(t, 500*time.Millisecond, tb.reserve(start))
+
+	assert.Equal(t, time.Duration(0), tb.reserve(start.Add(time.Second)))
+	assert.Equal(t, 500*time.Millisecond, tb.reserve(start.Add(time.Second)))
+}
+
+func TestTokenBucketWaitCancelled(t *testing.T) {
+	tb := newTokenBucket(0.001, 1)
+	tb.reserve(time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, tb.wait(ctx), context.Canceled)
+}
+
+func TestDailyBudget(t *testing.T) {
+	db := &dailyBudget{limit: 2}
+	day := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, db.take(day))
+	assert.False(t, db.warned)
+	assert.True(t, db.take(day))
+	assert.True(t, db.warned)
+	assert.False(t, db.take(day))
+	assert.True(t, db.take(day.Add(24*time.Hour)))
+	assert.False(t, db.warned)
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	st := &sequenceTransport{statuses: []int{200}}
+	rlt := &rateLimitTransport{
+		next:   st,
+		hosts:  map[string]bool{"api.steampowered.com": true},
+		budget: &dailyBudget{limit: 1},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/x", nil)
+	_, err := rlt.RoundTrip(req)
+	assert.Nil(t, err)
+
+	_, err = rlt.RoundTrip(req)
+	assert.ErrorIs(t, err, dailyBudgetErr)
+
+	other, _ := http.NewRequest(http.MethodGet, "https://example.com/x", nil)
+	_, err = rlt.RoundTrip(other)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, st.calls)
+}
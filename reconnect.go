@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	mqttInitialReconnectDelay = time.Second
+	mqttMaxReconnectInterval  = 10 * time.Minute
+)
+
+type reconnectLog struct {
+	mu         sync.Mutex
+	maxBackoff time.Duration
+	attempts   int
+	lostAt     time.Time
+}
+
+var brokerReconnects = &reconnectLog{maxBackoff: mqttMaxReconnectInterval}
+
+func sampledAttempt(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func (rl *reconnectLog) nextBackoff() time.Duration {
+	d := mqttInitialReconnectDelay
+	for i := 1; i < rl.attempts && d < rl.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > rl.maxBackoff {
+		return rl.maxBackoff
+	}
+
+	return d
+}
+
+func (rl *reconnectLog) lost(err error, now time.Time) string {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.attempts = 0
+	rl.lostAt = now
+
+	return fmt.Sprintf("connection to broker lost: error=%q", err)
+}
+
+func (rl *reconnectLog) attempt(now time.Time) (string, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.attempts++
+	if !sampledAttempt(rl.attempts) {
+		return "", false
+	}
+
+	return fmt.Sprintf("attempting to reconnect to broker: attempt=%d next_backoff=%s down_for=%s",
+		rl.attempts, rl.nextBackoff(), now.Sub(rl.lostAt).Round(time.Second)), true
+}
+
+func (rl *reconnectLog) connected(now time.Time) string {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.lostAt.IsZero() {
+		return "connected to broker"
+	}
+
+	line := fmt.Sprintf("reconnected to broker: attempts=%d down_for=%s", rl.attempts, now.Sub(rl.lostAt).Round(time.Second))
+	rl.attempts = 0
+	rl.lostAt = time.Time{}
+
+	return line
+}
+
+func onConnectionLostHandler(c mqtt.Client, err error) {
+	log.Println(brokerReconnects.lost(err, time.Now()))
+}
+
+func onRecconnectingHandler(c mqtt.Client, opts *mqtt.ClientOptions) {
+	if line, ok := brokerReconnects.attempt(time.Now()); ok {
+		log.Println(line)
+	}
+}
+
+func onConnectHandler(c mqtt.Client) {
+	log.Println(brokerReconnects.connected(time.Now()))
+}
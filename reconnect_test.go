@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampledAttempt(t *testing.T) {
+	sampled := []int{}
+	for n := 0; n <= 20; n++ {
+		if sampledAttempt(n) {
+			sampled = append(sampled, n)
+		}
+	}
+
+	assert.Equal(t, []int{1, 2, 4, 8, 16}, sampled)
+}
+
+func TestReconnectLog(t *testing.T) {
+	rl := &reconnectLog{maxBackoff: 10 * time.Second}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "connected to broker", rl.connected(now))
+	assert.Equal(t, `connection to broker lost: error="EOF"`, rl.lost(errors.New("EOF"), now))
+
+	lines := []string{}
+	for i := 0; i < 5; i++ {
+		if line, ok := rl.attempt(now.Add(time.Duration(i) * 30 * time.Second)); ok {
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"attempting to reconnect to broker: attempt=1 next_backoff=1s down_for=0s",
+		"attempting to reconnect to broker: attempt=2 next_backoff=2s down_for=30s",
+		"attempting to reconnect to broker: attempt=4 next_backoff=8s down_for=1m30s",
+	}, lines)
+
+	assert.Equal(t, "reconnected to broker: attempts=5 down_for=3m0s", rl.connected(now.Add(3*time.Minute)))
+	assert.Equal(t, "connected to broker", rl.connected(now))
+}
+
+func TestReconnectLogBackoffCap(t *testing.T) {
+	rl := &reconnectLog{maxBackoff: 10 * time.Second, attempts: 30}
+
+	assert.Equal(t, 10*time.Second, rl.nextBackoff())
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/gowon-irc/go-gowon"
+)
+
+var handlerPanics uint64
+
+func recoverHandler(name, fallback string, handler func(m gowon.Message) (string, error)) func(m gowon.Message) (string, error) {
+	return func(m gowon.Message) (out string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := debug.Stack()
+				atomic.AddUint64(&handlerPanics, 1)
+				log.Printf("recovered from panic in %s handler: %v\n%s", name, r, stack)
+				errorHook.CapturePanic(name, r, stack)
+				out, err = fallback, nil
+			}
+		}()
+
+		return handler(m)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		handler  func(m gowon.Message) (string, error)
+		expected string
+		err      error
+		panics   uint64
+	}{
+		{
+			name:     "No panic",
+			handler:  func(m gowon.Message) (string, error) { return "ok", nil },
+			expected: "ok",
+		},
+		{
+			name:    "Error",
+			handler: func(m gowon.Message) (string, error) { return "", errors.New("broken") },
+			err:     errors.New("broken"),
+		},
+		{
+			name: "Panic",
+			handler: func(m gowon.Message) (string, error) {
+				var ps []playerSummary
+				return ps[0].PersonaName, nil
+			},
+			expected: "Error: something went wrong",
+			panics:   1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := atomic.LoadUint64(&handlerPanics)
+
+			out, err := recoverHandler("steam", "Error: something went wrong", tc.handler)(gowon.Message{})
+
+			assert.Equal(t, tc.expected, out)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.panics, atomic.LoadUint64(&handlerPanics)-before)
+		})
+	}
+}
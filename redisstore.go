@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyPrefix  = "gowon-steam:"
+	redisTimeout    = 5 * time.Second
+	redisMaxRetries = 5
+)
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func openRedisStore(dsn string) (Store, error) {
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisStore{client: redis.NewClient(opts)}, nil
+}
+
+func redisBucketKey(bucket []byte) string {
+	return redisKeyPrefix + string(bucket)
+}
+
+func redisSeqKey(bucket []byte) string {
+	return redisBucketKey(bucket) + ":seq"
+}
+
+func (rs *redisStore) load(ctx context.Context, c redis.Cmdable, bucket []byte) (*memBucket, bool, error) {
+	values, err := c.HGetAll(ctx, redisBucketKey(bucket)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	seq, err := c.Get(ctx, redisSeqKey(bucket)).Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, false, err
+	}
+
+	mb := &memBucket{values: make(map[string][]byte, len(values)), seq: seq}
+	for k, v := range values {
+		mb.values[k] = []byte(v)
+	}
+
+	return mb, len(values) > 0 || seq > 0, nil
+}
+
+func (rs *redisStore) View(bucket []byte, fn func(b Bucket) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	mb, ok, err := rs.load(ctx, rs.client, bucket)
+	if err != nil || !ok {
+		return err
+	}
+
+	return fn(mb)
+}
+
+func (rs *redisStore) Update(bucket []byte, fn func(b Bucket) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	key, seqKey := redisBucketKey(bucket), redisSeqKey(bucket)
+
+	txf := func(tx *redis.Tx) error {
+		old, _, err := rs.load(ctx, tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		mb := old.clone()
+		if err := fn(mb); err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for k := range old.values {
+				if _, ok := mb.values[k]; !ok {
+					pipe.HDel(ctx, key, k)
+				}
+			}
+
+			for k, v := range mb.values {
+				if ov, ok := old.values[k]; !ok || !bytes.Equal(ov, v) {
+					pipe.HSet(ctx, key, k, v)
+				}
+			}
+
+			if mb.seq != old.seq {
+				pipe.Set(ctx, seqKey, strconv.FormatUint(mb.seq, 10), 0)
+			}
+
+			return nil
+		})
+
+		return err
+	}
+
+	for i := 0; i < redisMaxRetries; i++ {
+		err := rs.client.Watch(ctx, txf, key, seqKey)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return redis.TxFailedErr
+}
+
+func (rs *redisStore) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	return rs.client.Ping(ctx).Err()
+}
+
+func (rs *redisStore) Close() error {
+	return rs.client.Close()
+}
+
+func init() {
+	registerStoreDriver("redis", openRedisStore)
+}
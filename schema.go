@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	gameSchemaPath = "/ISteamUserStats/GetSchemaForGame/v2/?key=%s&appid=%d&l=%s"
+)
+
+var schemaCacheTTL = 24 * time.Hour
+
+type gameSchemaRes struct {
+	Game struct {
+		GameName           string
+		AvailableGameStats struct {
+			Achievements []schemaAchievement
+		}
+	}
+}
+
+type schemaAchievement struct {
+	Name        string
+	DisplayName string
+	Hidden      int
+	Description string
+	Icon        string
+	IconGray    string
+}
+
+func (gsr gameSchemaRes) Hidden() map[string]bool {
+	out := make(map[string]bool)
+
+	for _, a := range gsr.Game.AvailableGameStats.Achievements {
+		if a.Hidden == 1 {
+			out[a.Name] = true
+		}
+	}
+
+	return out
+}
+
+func (gsr gameSchemaRes) Achievement(apiName string) (schemaAchievement, bool) {
+	for _, a := range gsr.Game.AvailableGameStats.Achievements {
+		if a.Name == apiName {
+			return a, true
+		}
+	}
+
+	return schemaAchievement{}, false
+}
+
+func getGameSchema(ctx context.Context, apiKey string, appId int, lang string, client *steamClient) (*gameSchemaRes, error) {
+	return getJSON[gameSchemaRes](ctx, client.apiUrl(gameSchemaPath, apiKey, appId, lang), "", client)
+}
+
+func cachedGameSchema(ctx context.Context, apiKey string, appId int, lang string, client *steamClient) (*gameSchemaRes, error) {
+	key := fmt.Sprintf("schema:%d:%s", appId, lang)
+
+	gs, err := client.cache.GetOrFetch(ctx, key, schemaCacheTTL, func(ctx context.Context) (interface{}, error) {
+		gs := &gameSchemaRes{}
+		if diskCache.Get(key, gs) {
+			return gs, nil
+		}
+
+		gs, err := getGameSchema(ctx, apiKey, appId, lang, client)
+		if err != nil {
+			return nil, err
+		}
+
+		diskCache.Set(key, gs)
+
+		return gs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gs.(*gameSchemaRes), nil
+}
+
+func (s settings) withSchema(ctx context.Context, apiKey string, appId int, a playerAchievement, client *steamClient) playerAchievement {
+	if a.Name != "" && a.Description != "" {
+		return a
+	}
+
+	gs, err := cachedGameSchema(ctx, apiKey, appId, s.language, client)
+	if err != nil {
+		return a
+	}
+
+	sa, ok := gs.Achievement(a.ApiName)
+	if !ok {
+		return a
+	}
+
+	if a.Name == "" {
+		a.Name = sa.DisplayName
+	}
+
+	if a.Description == "" {
+		a.Description = sa.Description
+	}
+
+	if a.Icon == "" {
+		a.Icon = sa.Icon
+		if a.UnlockTime == 0 {
+			a.Icon = sa.IconGray
+		}
+	}
+
+	return a
+}
+
+func (s settings) achievementDescription(ctx context.Context, apiKey string, appId int, a playerAchievement, client *steamClient) string {
+	if !s.maskHidden {
+		return a.Description
+	}
+
+	gs, err := cachedGameSchema(ctx, apiKey, appId, s.language, client)
+	if err != nil {
+		return s.msg("hidden_achievement")
+	}
+
+	if gs.Hidden()[a.ApiName] {
+		return s.msg("hidden_achievement")
+	}
+
+	return a.Description
+}
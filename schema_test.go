@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{"game":{"gameName":"g","availableGameStats":{"achievements":[{"name":"a","displayName":"A","hidden":0,"description":"do a","icon":"a.jpg","icongray":"a_gray.jpg"},{"name":"b","displayName":"B","hidden":1,"description":"do b"}]}}}`
+
+func TestGameSchemaHidden(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		out    map[string]bool
+		errMsg string
+	}{
+		{
+			name:   "Empty data returned",
+			body:   "",
+			errMsg: "unexpected end of JSON input",
+		},
+		{
+			name: "No achievements",
+			body: `{"game":{}}`,
+			out:  map[string]bool{},
+		},
+		{
+			name: "One hidden achievement",
+			body: testSchema,
+			out:  map[string]bool{"b": true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(200, tc.body)
+
+			gs, err := getGameSchema(context.Background(), "key", 1, "en", client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.out, gs.Hidden())
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestSettingsAchievementDescription(t *testing.T) {
+	cases := []struct {
+		name       string
+		maskHidden bool
+		apiName    string
+		body       string
+		out        string
+	}{
+		{
+			name:       "Masking disabled",
+			maskHidden: false,
+			apiName:    "b",
+			body:       testSchema,
+			out:        "description",
+		},
+		{
+			name:       "Visible achievement",
+			maskHidden: true,
+			apiName:    "a",
+			body:       testSchema,
+			out:        "description",
+		},
+		{
+			name:       "Hidden achievement",
+			maskHidden: true,
+			apiName:    "b",
+			body:       testSchema,
+			out:        "<hidden achievement>",
+		},
+		{
+			name:       "Schema lookup fails",
+			maskHidden: true,
+			apiName:    "a",
+			body:       "",
+			out:        "<hidden achievement>",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.maskHidden = tc.maskHidden
+			client := NewTestClient(200, tc.body)
+			a := playerAchievement{ApiName: tc.apiName, Description: "description"}
+
+			assert.Equal(t, tc.out, s.achievementDescription(context.Background(), "key", 1, a, client))
+		})
+	}
+}
+
+func TestGameSchemaAchievement(t *testing.T) {
+	gs := gameSchemaRes{}
+	assert.Nil(t, json.Unmarshal([]byte(testSchema), &gs))
+
+	a, ok := gs.Achievement("b")
+	assert.True(t, ok)
+	assert.Equal(t, "B", a.DisplayName)
+
+	_, ok = gs.Achievement("c")
+	assert.False(t, ok)
+}
+
+func TestSettingsWithSchema(t *testing.T) {
+	cases := []struct {
+		name string
+		in   playerAchievement
+		body string
+		out  playerAchievement
+	}{
+		{
+			name: "Fills missing metadata",
+			in:   playerAchievement{ApiName: "a", UnlockTime: 1},
+			body: testSchema,
+			out:  playerAchievement{ApiName: "a", UnlockTime: 1, Name: "A", Description: "do a", Icon: "a.jpg"},
+		},
+		{
+			name: "Locked icon",
+			in:   playerAchievement{ApiName: "a", Name: "Ach"},
+			body: testSchema,
+			out:  playerAchievement{ApiName: "a", Name: "Ach", Description: "do a", Icon: "a_gray.jpg"},
+		},
+		{
+			name: "Unknown achievement",
+			in:   playerAchievement{ApiName: "c", Name: "C"},
+			body: testSchema,
+			out:  playerAchievement{ApiName: "c", Name: "C"},
+		},
+		{
+			name: "Schema lookup fails",
+			in:   playerAchievement{ApiName: "a"},
+			body: "",
+			out:  playerAchievement{ApiName: "a"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(200, tc.body)
+
+			out := testSettings.withSchema(context.Background(), "key", 1, tc.in, client)
+
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestSettingsWithSchemaSkipsCompleteAchievements(t *testing.T) {
+	st := &sequenceTransport{statuses: []int{200}}
+
+	in := playerAchievement{ApiName: "a", UnlockTime: 1, Name: "Ach", Description: "desc"}
+	out := testSettings.withSchema(context.Background(), "key", 1, in, newTestSteamClient(&http.Client{Transport: st}))
+
+	assert.Equal(t, in, out)
+	assert.Equal(t, 0, st.calls)
+}
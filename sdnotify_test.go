@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listenNotifySocket(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	return conn
+}
+
+func readNotify(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 64)
+
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	assert.Nil(t, err)
+
+	return string(buf[:n])
+}
+
+func TestSdNotifyNoSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := sdNotify(sdReady)
+	assert.Nil(t, err)
+	assert.False(t, sent)
+}
+
+func TestSdNotify(t *testing.T) {
+	conn := listenNotifySocket(t)
+
+	sent, err := sdNotify(sdReady)
+	assert.Nil(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, "READY=1", readNotify(t, conn))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	cases := []struct {
+		name     string
+		usec     string
+		pid      string
+		interval time.Duration
+		ok       bool
+	}{
+		{name: "Not set", usec: "", ok: false},
+		{name: "Invalid", usec: "x", ok: false},
+		{name: "Set", usec: "10000000", interval: 5 * time.Second, ok: true},
+		{name: "Our pid", usec: "10000000", pid: strconv.Itoa(os.Getpid()), interval: 5 * time.Second, ok: true},
+		{name: "Other pid", usec: "10000000", pid: "1", ok: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tc.usec)
+			t.Setenv("WATCHDOG_PID", tc.pid)
+
+			interval, ok := watchdogInterval()
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.interval, interval)
+		})
+	}
+}
+
+func TestRunWatchdog(t *testing.T) {
+	conn := listenNotifySocket(t)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go runWatchdog(time.Millisecond, func() bool { return true }, stop)
+
+	assert.Equal(t, "WATCHDOG=1", readNotify(t, conn))
+}
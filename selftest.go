@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	selftestTopic   = "/gowon/steam/selftest"
+	selftestTimeout = 5 * time.Second
+)
+
+var (
+	selftestBucket = []byte("selftest")
+
+	checkDisabledErr = errors.New("not configured")
+	checkTimeoutErr  = errors.New("timed out")
+	checkMismatchErr = errors.New("read back a different value")
+)
+
+type selfCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func kvRoundTrip(kv Store, value []byte) error {
+	if kv == nil {
+		return checkDisabledErr
+	}
+
+	return kv.Update(selftestBucket, func(b Bucket) error {
+		if err := b.Put([]byte("probe"), value); err != nil {
+			return err
+		}
+
+		if !bytes.Equal(b.Get([]byte("probe")), value) {
+			return checkMismatchErr
+		}
+
+		return b.Delete([]byte("probe"))
+	})
+}
+
+func brokerRoundTrip(rp *replyPublisher, value []byte, timeout time.Duration) error {
+	if rp == nil {
+		return checkDisabledErr
+	}
+
+	token := rp.client.Publish(selftestTopic, 1, false, value)
+	if !token.WaitTimeout(timeout) {
+		return checkTimeoutErr
+	}
+
+	return token.Error()
+}
+
+func cacheRoundTrip(c *lruCache, value string) error {
+	if c == nil {
+		return checkDisabledErr
+	}
+
+	key := "selftest:" + value
+	c.SetWithTTL(key, value, time.Minute)
+
+	v, ok := c.Get(key)
+	if !ok || v != value {
+		return checkMismatchErr
+	}
+
+	return nil
+}
+
+func selfChecks(apiKey string, kv Store, client *steamClient, now time.Time) []selfCheck {
+	probe := fmt.Sprint(now.UnixNano())
+
+	return []selfCheck{
+		{name: "api", run: func(ctx context.Context) error {
+			return checkAPIKeys(ctx, []string{apiKey}, client)
+		}},
+		{name: "kv", run: func(ctx context.Context) error {
+			return kvRoundTrip(kv, []byte(probe))
+		}},
+		{name: "broker", run: func(ctx context.Context) error {
+			return brokerRoundTrip(replies, []byte(probe), selftestTimeout)
+		}},
+		{name: "cache", run: func(ctx context.Context) error {
+			return cacheRoundTrip(client.cache, probe)
+		}},
+	}
+}
+
+func (s settings) runSelfChecks(ctx context.Context, checks []selfCheck) string {
+	results := []string{}
+
+	for _, c := range checks {
+		if err := c.run(ctx); err != nil {
+			results = append(results, s.msg("selftest_fail", c.name, sanitiseError(err)))
+			continue
+		}
+
+		results = append(results, s.msg("selftest_pass", c.name))
+	}
+
+	return strings.Join(results, ", ")
+}
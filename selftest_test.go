@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKvRoundTrip(t *testing.T) {
+	assert.ErrorIs(t, kvRoundTrip(nil, []byte("x")), checkDisabledErr)
+	assert.Nil(t, kvRoundTrip(openTestDB(t), []byte("x")))
+}
+
+func TestBrokerRoundTrip(t *testing.T) {
+	assert.ErrorIs(t, brokerRoundTrip(nil, []byte("x"), time.Second), checkDisabledErr)
+
+	pr := &publishRecorder{}
+	assert.Nil(t, brokerRoundTrip(&replyPublisher{client: pr}, []byte("x"), time.Second))
+	assert.Equal(t, [][]byte{[]byte("x")}, pr.published)
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	assert.ErrorIs(t, cacheRoundTrip(nil, "x"), checkDisabledErr)
+	assert.Nil(t, cacheRoundTrip(newLRUCache(10, time.Minute), "x"))
+}
+
+func TestRunSelfChecks(t *testing.T) {
+	checks := []selfCheck{
+		{name: "ok", run: func(ctx context.Context) error { return nil }},
+		{name: "broken", run: func(ctx context.Context) error { return errors.New("get /x?key=abc&n=1: nope") }},
+	}
+
+	assert.Equal(t, "ok: pass, broken: fail (get /x?key=REDACTED&n=1: nope)", testSettings.runSelfChecks(context.Background(), checks))
+}
+
+func TestSelfChecks(t *testing.T) {
+	client := NewTestClient(200, `{"response":{"players":[]}}`)
+
+	cases := []struct {
+		name     string
+		client   *steamClient
+		broker   mqtt.Client
+		expected string
+	}{
+		{
+			name:     "All pass",
+			client:   client,
+			broker:   &publishRecorder{},
+			expected: "api: pass, kv: pass, broker: pass, cache: pass",
+		},
+		{
+			name:     "Failures",
+			client:   NewTestClient(403, ""),
+			expected: "api: fail (api key 1 of 1 failed validation: ISteamUser/GetPlayerSummaries/v2 for 76561197960435530: invalid API key), kv: pass, broker: fail (not configured), cache: pass",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.client.cache = newLRUCache(10, time.Minute)
+
+			if tc.broker != nil {
+				replies = &replyPublisher{client: tc.broker}
+				defer func() { replies = nil }()
+			}
+
+			checks := selfChecks("key", openTestDB(t), tc.client, time.Now())
+
+			assert.Equal(t, tc.expected, testSettings.runSelfChecks(context.Background(), checks))
+		})
+	}
+}
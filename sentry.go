@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const sentryTimeout = 10 * time.Second
+
+type sentryEvent struct {
+	EventID   string            `json:"event_id"`
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"`
+	Platform  string            `json:"platform"`
+	Logger    string            `json:"logger"`
+	Release   string            `json:"release"`
+	Message   string            `json:"message"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+type sentryReporter struct {
+	storeURL string
+	auth     string
+	client   *http.Client
+}
+
+var errorHook *sentryReporter
+
+func parseSentryDSN(dsn string) (*sentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid error reporting dsn: %w", err)
+	}
+
+	project := strings.TrimPrefix(u.Path, "/")
+	if u.Scheme == "" || u.Host == "" || u.User == nil || u.User.Username() == "" || project == "" {
+		return nil, fmt.Errorf("invalid error reporting dsn %s, must look like https://key@host/project", u.Redacted())
+	}
+
+	prefix := ""
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		prefix, project = "/"+project[:i], project[i+1:]
+	}
+
+	return &sentryReporter{
+		storeURL: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s/%s, sentry_key=%s", moduleName, moduleVersion(), u.User.Username()),
+		client:   &http.Client{Timeout: sentryTimeout},
+	}, nil
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strings.Repeat("0", 32)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func newSentryEvent(level, message string, tags, extra map[string]string, now time.Time) sentryEvent {
+	return sentryEvent{
+		EventID:   newEventID(),
+		Timestamp: now.UTC().Format(time.RFC3339),
+		Level:     level,
+		Platform:  "go",
+		Logger:    moduleName,
+		Release:   moduleVersion(),
+		Message:   message,
+		Tags:      tags,
+		Extra:     extra,
+	}
+}
+
+func (sr *sentryReporter) send(ctx context.Context, event sentryEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sr.storeURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", sr.auth)
+
+	res, err := sr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("error reporting service returned %s", res.Status)
+	}
+
+	return nil
+}
+
+func (sr *sentryReporter) capture(level, message string, tags, extra map[string]string) {
+	if sr == nil {
+		return
+	}
+
+	event := newSentryEvent(level, message, tags, extra, time.Now())
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sentryTimeout)
+		defer cancel()
+
+		if err := sr.send(ctx, event); err != nil {
+			log.Printf("couldn't send error report: %s\n", err)
+		}
+	}()
+}
+
+func (sr *sentryReporter) CaptureError(command, nick, dest string, err error) {
+	sr.capture("error", sanitiseError(err), map[string]string{"command": command}, map[string]string{"nick": nick, "dest": dest})
+}
+
+func (sr *sentryReporter) CapturePanic(handler string, r interface{}, stack []byte) {
+	sr.capture("fatal", fmt.Sprintf("panic in %s handler: %v", handler, r), map[string]string{"handler": handler}, map[string]string{"stack": string(stack)})
+}
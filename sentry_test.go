@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSentryDSN(t *testing.T) {
+	cases := []struct {
+		name     string
+		dsn      string
+		expected string
+		errMsg   string
+	}{
+		{
+			name:     "Sentry",
+			dsn:      "https://abc123@o1.ingest.sentry.io/42",
+			expected: "https://o1.ingest.sentry.io/api/42/store/",
+		},
+		{
+			name:     "Path prefix",
+			dsn:      "http://abc123@glitchtip.local:8000/errors/7",
+			expected: "http://glitchtip.local:8000/errors/api/7/store/",
+		},
+		{
+			name:   "Missing key",
+			dsn:    "https://o1.ingest.sentry.io/42",
+			errMsg: "invalid error reporting dsn https://o1.ingest.sentry.io/42, must look like https://key@host/project",
+		},
+		{
+			name:   "Missing project",
+			dsn:    "https://abc123@o1.ingest.sentry.io",
+			errMsg: "invalid error reporting dsn https://abc123@o1.ingest.sentry.io, must look like https://key@host/project",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr, err := parseSentryDSN(tc.dsn)
+
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, sr.storeURL)
+			assert.Contains(t, sr.auth, "sentry_key=abc123")
+		})
+	}
+}
+
+func TestSentrySend(t *testing.T) {
+	var auth string
+	event := sentryEvent{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/store/", r.URL.Path)
+		auth = r.Header.Get("X-Sentry-Auth")
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&event))
+	}))
+	defer ts.Close()
+
+	sr, err := parseSentryDSN(strings.Replace(ts.URL, "://", "://abc123@", 1) + "/42")
+	assert.Nil(t, err)
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	err = sr.send(context.Background(), newSentryEvent("error", "broken", map[string]string{"command": "recent"}, nil, now))
+	assert.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(auth, "Sentry sentry_version=7"))
+	assert.Len(t, event.EventID, 32)
+	assert.Equal(t, "2022-01-01T12:00:00Z", event.Timestamp)
+	assert.Equal(t, "error", event.Level)
+	assert.Equal(t, "broken", event.Message)
+	assert.Equal(t, map[string]string{"command": "recent"}, event.Tags)
+}
+
+func TestSentrySendRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	sr, err := parseSentryDSN(strings.Replace(ts.URL, "://", "://abc123@", 1) + "/42")
+	assert.Nil(t, err)
+
+	err = sr.send(context.Background(), sentryEvent{})
+	assert.EqualError(t, err, "error reporting service returned 429 Too Many Requests")
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dateFormats = map[string]string{
+	"iso": "2006-01-02 15:04 MST",
+	"eu":  "02/01/2006 15:04 MST",
+	"us":  "01/02/2006 3:04PM MST",
+	"rfc": time.RFC1123,
+}
+
+var languages = []string{
+	"ar", "bg", "cs", "da", "de", "el", "en", "es", "es-419", "fi", "fr",
+	"hu", "id", "it", "ja", "ko", "nl", "no", "pl", "pt", "pt-BR", "ro",
+	"ru", "sv", "th", "tr", "uk", "vn", "zh-CN", "zh-TW",
+}
+
+type settings struct {
+	location         *time.Location
+	dateFormat       string
+	language         string
+	links            bool
+	shortener        string
+	itadKey          string
+	itadCountry      string
+	verbose          bool
+	personaNames     bool
+	pmOverflow       bool
+	maskHidden       bool
+	asciiBars        bool
+	sortBy           string
+	maxList          int
+	listLength       int
+	achievementGames int
+	gatherBudget     time.Duration
+	hashColours      bool
+	admins           map[string]bool
+	disabledCommands map[string]map[string]bool
+	userDailyLimit   int
+	messages         catalog
+	messageSets      map[string]catalog
+	formatter        formatter
+	formatters       map[string]formatter
+}
+
+func dateFormatNames() string {
+	names := []string{}
+	for n := range dateFormats {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func parseDateFormat(name string) (string, error) {
+	layout, ok := dateFormats[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown date format %s, must be one of %s", name, dateFormatNames())
+	}
+
+	return layout, nil
+}
+
+func parseLanguage(lang string) (string, error) {
+	for _, l := range languages {
+		if strings.EqualFold(l, lang) {
+			return l, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown language %s, must be one of %s", lang, strings.Join(languages, ", "))
+}
+
+func newSettings(opts Options) (settings, error) {
+	loc, err := time.LoadLocation(opts.Timezone)
+	if err != nil {
+		return settings{}, err
+	}
+
+	layout, err := parseDateFormat(opts.DateFormat)
+	if err != nil {
+		return settings{}, err
+	}
+
+	lang, err := parseLanguage(opts.Language)
+	if err != nil {
+		return settings{}, err
+	}
+
+	f, err := parseFormatter(opts.Format)
+	if err != nil {
+		return settings{}, err
+	}
+
+	fs, err := parseDestFormatters(opts.DestFormats)
+	if err != nil {
+		return settings{}, err
+	}
+
+	c, err := parseCatalog(opts.Messages)
+	if err != nil {
+		return settings{}, err
+	}
+
+	cs, err := parseDestCatalogs(opts.DestMessages)
+	if err != nil {
+		return settings{}, err
+	}
+
+	dc, err := parseDisabledCommands(opts.DisabledCommands)
+	if err != nil {
+		return settings{}, err
+	}
+
+	return settings{
+		location:         loc,
+		dateFormat:       layout,
+		language:         lang,
+		links:            !opts.NoLinks,
+		shortener:        opts.Shortener,
+		itadKey:          opts.ITADKey,
+		itadCountry:      opts.ITADCountry,
+		verbose:          opts.Verbose,
+		personaNames:     opts.PersonaNames,
+		maskHidden:       opts.MaskHidden,
+		asciiBars:        opts.AsciiBars,
+		sortBy:           "recency",
+		maxList:          opts.MaxList,
+		listLength:       opts.MaxList,
+		achievementGames: opts.AchievementGames,
+		gatherBudget:     opts.GatherBudget,
+		hashColours:      !opts.PositionalColours,
+		messages:         c,
+		messageSets:      cs,
+		formatter:        f,
+		formatters:       fs,
+		admins:           adminSet(opts.AdminNicks),
+		disabledCommands: dc,
+		userDailyLimit:   opts.UserDailyLimit,
+	}, nil
+}
+
+func adminSet(nicks []string) map[string]bool {
+	admins := make(map[string]bool)
+	for _, n := range nicks {
+		admins[strings.ToLower(n)] = true
+	}
+
+	return admins
+}
+
+func (s settings) isAdmin(nick string) bool {
+	return s.admins[strings.ToLower(nick)]
+}
+
+func requestSettings(kv Store, defaults settings, nick, dest string) (settings, error) {
+	s := defaults
+
+	if f, ok := s.formatters[dest]; ok {
+		s.formatter = f
+	}
+
+	if c, ok := s.messageSets[dest]; ok {
+		s.messages = c
+	}
+
+	tz, err := getPref(kv, timezonePref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(tz) > 0 {
+		if loc, err := time.LoadLocation(string(tz)); err == nil {
+			s.location = loc
+		}
+	}
+
+	df, err := getPref(kv, dateFormatPref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(df) > 0 {
+		if layout, err := parseDateFormat(string(df)); err == nil {
+			s.dateFormat = layout
+		}
+	}
+
+	lang, err := getPref(kv, languagePref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(lang) > 0 {
+		if l, err := parseLanguage(string(lang)); err == nil {
+			s.language = l
+		}
+	}
+
+	persona, err := getPref(kv, personaPref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(persona) > 0 {
+		s.personaNames = string(persona) == "on"
+	}
+
+	spoilers, err := getPref(kv, spoilersPref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(spoilers) > 0 && s.maskHidden {
+		s.maskHidden = string(spoilers) != "on"
+	}
+
+	verbose, err := getPref(kv, verbosePref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(verbose) > 0 {
+		s.verbose = string(verbose) == "on"
+	}
+
+	pm, err := getPref(kv, pmPref, []byte(nick))
+	if err != nil {
+		return s, err
+	}
+
+	if len(pm) > 0 {
+		s.pmOverflow = string(pm) == "on"
+	}
+
+	return s, nil
+}
+
+var valueModifiers = map[string]bool{
+	"--by": true,
+	"--n":  true,
+}
+
+var modifierAliases = map[string]string{
+	"-b": "--by",
+	"-n": "--n",
+}
+
+func splitModifiers(fields []string) (rest, modifiers []string) {
+	rest = []string{}
+	modifiers = []string{}
+
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+
+		if !strings.HasPrefix(f, "-") {
+			rest = append(rest, f)
+			continue
+		}
+
+		if long, ok := modifierAliases[f]; ok {
+			f = long
+		}
+
+		if valueModifiers[f] && i+1 < len(fields) {
+			f = fmt.Sprintf("%s=%s", f, fields[i+1])
+			i++
+		}
+
+		modifiers = append(modifiers, f)
+	}
+
+	return rest, modifiers
+}
+
+func (s settings) withModifiers(modifiers []string) settings {
+	for _, m := range modifiers {
+		switch m {
+		case "-v", "--verbose":
+			s.verbose = true
+		case "-c", "--compact":
+			s.verbose = false
+		case "--by=playtime", "--by=recency":
+			s.sortBy = strings.TrimPrefix(m, "--by=")
+		}
+
+		if strings.HasPrefix(m, "--n=") {
+			n, err := strconv.Atoi(strings.TrimPrefix(m, "--n="))
+			if err == nil && n > 0 && (s.maxList == 0 || n <= s.maxList) {
+				s.listLength = n
+			}
+		}
+	}
+
+	return s
+}
+
+func (s settings) limit(n int) int {
+	if s.listLength > 0 && s.listLength < n {
+		return s.listLength
+	}
+
+	return n
+}
+
+func (s settings) gatherContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.gatherBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, s.gatherBudget)
+}
+
+func (s settings) formatTime(t time.Time) string {
+	return t.In(s.location).Format(s.dateFormat)
+}
+
+func (s settings) formatUnix(sec int) string {
+	return s.formatTime(time.Unix(int64(sec), 0))
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSettings(t *testing.T) {
+	cases := []struct {
+		name       string
+		timezone   string
+		dateFormat string
+		language   string
+		errMsg     string
+	}{
+		{
+			name:       "Valid settings",
+			timezone:   "Europe/London",
+			dateFormat: "iso",
+			language:   "en",
+			errMsg:     "",
+		},
+		{
+			name:       "Date format is case insensitive",
+			timezone:   "UTC",
+			dateFormat: "US",
+			language:   "en",
+			errMsg:     "",
+		},
+		{
+			name:       "Unknown timezone",
+			timezone:   "Nowhere/Special",
+			dateFormat: "iso",
+			language:   "en",
+			errMsg:     "unknown time zone",
+		},
+		{
+			name:       "Unknown date format",
+			timezone:   "UTC",
+			dateFormat: "roman",
+			language:   "en",
+			errMsg:     "unknown date format roman",
+		},
+		{
+			name:       "Language is case insensitive",
+			timezone:   "UTC",
+			dateFormat: "iso",
+			language:   "PT-br",
+			errMsg:     "",
+		},
+		{
+			name:       "Unknown language",
+			timezone:   "UTC",
+			dateFormat: "iso",
+			language:   "xx",
+			errMsg:     "unknown language xx",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newSettings(Options{
+				Timezone:   tc.timezone,
+				DateFormat: tc.dateFormat,
+				Language:   tc.language,
+				Format:     "irc",
+				Messages:   "en",
+			})
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestSettingsFormatUnix(t *testing.T) {
+	cases := []struct {
+		name       string
+		timezone   string
+		dateFormat string
+		out        string
+	}{
+		{
+			name:       "UTC iso",
+			timezone:   "UTC",
+			dateFormat: "iso",
+			out:        "2021-11-30 23:51 UTC",
+		},
+		{
+			name:       "New York us",
+			timezone:   "America/New_York",
+			dateFormat: "us",
+			out:        "11/30/2021 6:51PM EST",
+		},
+		{
+			name:       "Berlin eu",
+			timezone:   "Europe/Berlin",
+			dateFormat: "eu",
+			out:        "01/12/2021 00:51 CET",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := newSettings(Options{
+				Timezone:   tc.timezone,
+				DateFormat: tc.dateFormat,
+				Language:   "en",
+				Format:     "irc",
+				Messages:   "en",
+			})
+			assert.Nil(t, err)
+
+			assert.Equal(t, tc.out, s.formatUnix(1638316294))
+		})
+	}
+}
+
+func TestSettingsFormatTimeDefault(t *testing.T) {
+	tm := time.Date(2022, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	assert.Equal(t, "2022-01-02 03:04 UTC", testSettings.formatTime(tm))
+}
+
+func TestSplitModifiers(t *testing.T) {
+	cases := []struct {
+		name      string
+		fields    []string
+		rest      []string
+		modifiers []string
+	}{
+		{
+			name:      "No fields",
+			fields:    []string{},
+			rest:      []string{},
+			modifiers: []string{},
+		},
+		{
+			name:      "No modifiers",
+			fields:    []string{"r", "bob"},
+			rest:      []string{"r", "bob"},
+			modifiers: []string{},
+		},
+		{
+			name:      "Modifier with separate value",
+			fields:    []string{"r", "--by", "playtime", "bob"},
+			rest:      []string{"r", "bob"},
+			modifiers: []string{"--by=playtime"},
+		},
+		{
+			name:      "Modifier with joined value",
+			fields:    []string{"r", "--by=playtime"},
+			rest:      []string{"r"},
+			modifiers: []string{"--by=playtime"},
+		},
+		{
+			name:      "Value modifier without a value",
+			fields:    []string{"r", "--by"},
+			rest:      []string{"r"},
+			modifiers: []string{"--by"},
+		},
+		{
+			name:      "Short aliases",
+			fields:    []string{"top", "-n", "10", "-b", "playtime", "bob"},
+			rest:      []string{"top", "bob"},
+			modifiers: []string{"--n=10", "--by=playtime"},
+		},
+		{
+			name:      "Modifiers between arguments",
+			fields:    []string{"r", "-v", "bob", "--compact"},
+			rest:      []string{"r", "bob"},
+			modifiers: []string{"-v", "--compact"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rest, modifiers := splitModifiers(tc.fields)
+
+			assert.Equal(t, tc.rest, rest)
+			assert.Equal(t, tc.modifiers, modifiers)
+		})
+	}
+}
+
+func TestSettingsWithModifiers(t *testing.T) {
+	cases := []struct {
+		name      string
+		verbose   bool
+		modifiers []string
+		out       bool
+	}{
+		{
+			name:      "No modifiers keeps default",
+			verbose:   true,
+			modifiers: []string{},
+			out:       true,
+		},
+		{
+			name:      "Verbose",
+			verbose:   false,
+			modifiers: []string{"-v"},
+			out:       true,
+		},
+		{
+			name:      "Compact",
+			verbose:   true,
+			modifiers: []string{"--compact"},
+			out:       false,
+		},
+		{
+			name:      "Last modifier wins",
+			verbose:   false,
+			modifiers: []string{"--verbose", "-c"},
+			out:       false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.verbose = tc.verbose
+
+			assert.Equal(t, tc.out, s.withModifiers(tc.modifiers).verbose)
+		})
+	}
+}
+
+func TestSettingsWithSortModifier(t *testing.T) {
+	cases := []struct {
+		name      string
+		modifiers []string
+		out       string
+	}{
+		{
+			name:      "Default",
+			modifiers: []string{},
+			out:       "recency",
+		},
+		{
+			name:      "Playtime",
+			modifiers: []string{"--by=playtime"},
+			out:       "playtime",
+		},
+		{
+			name:      "Unknown order is ignored",
+			modifiers: []string{"--by=name"},
+			out:       "recency",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, testSettings.withModifiers(tc.modifiers).sortBy)
+		})
+	}
+}
+
+func TestSettingsLimit(t *testing.T) {
+	cases := []struct {
+		name      string
+		maxList   int
+		modifiers []string
+		n         int
+		out       int
+	}{
+		{
+			name:      "No limit",
+			maxList:   0,
+			modifiers: []string{},
+			n:         20,
+			out:       20,
+		},
+		{
+			name:      "Max list applies",
+			maxList:   10,
+			modifiers: []string{},
+			n:         20,
+			out:       10,
+		},
+		{
+			name:      "Fewer items than the limit",
+			maxList:   10,
+			modifiers: []string{},
+			n:         3,
+			out:       3,
+		},
+		{
+			name:      "Requested count",
+			maxList:   10,
+			modifiers: []string{"--n=3"},
+			n:         20,
+			out:       3,
+		},
+		{
+			name:      "Requested count above max list is ignored",
+			maxList:   10,
+			modifiers: []string{"--n=15"},
+			n:         20,
+			out:       10,
+		},
+		{
+			name:      "Requested count without max list",
+			maxList:   0,
+			modifiers: []string{"--n=15"},
+			n:         20,
+			out:       15,
+		},
+		{
+			name:      "Invalid count is ignored",
+			maxList:   10,
+			modifiers: []string{"--n=lots"},
+			n:         20,
+			out:       10,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.maxList = tc.maxList
+			s.listLength = tc.maxList
+
+			assert.Equal(t, tc.out, s.withModifiers(tc.modifiers).limit(tc.n))
+		})
+	}
+}
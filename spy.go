@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	steamSpyPath     = "/api.php?request=appdetails&appid=%d"
+	steamSpyCacheTTL = 6 * time.Hour
+)
+
+type steamSpyRes struct {
+	AppId          int
+	Name           string
+	Owners         string
+	AverageForever int `json:"average_forever"`
+	Average2Weeks  int `json:"average_2weeks"`
+	CCU            int
+}
+
+func getSteamSpy(ctx context.Context, appId int, client *steamClient) (*steamSpyRes, error) {
+	return getJSON[steamSpyRes](ctx, client.steamSpyUrl(steamSpyPath, appId), "", client)
+}
+
+func cachedSteamSpy(ctx context.Context, appId int, client *steamClient) (*steamSpyRes, error) {
+	key := fmt.Sprintf("spy:%d", appId)
+
+	r, err := client.cache.GetOrFetch(ctx, key, steamSpyCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return getSteamSpy(ctx, appId, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*steamSpyRes), nil
+}
+
+func (s settings) formatSteamSpy(r *steamSpyRes) string {
+	return s.msg("spy_app", s.formatter.Colour("green", r.Name), r.Owners, float64(r.AverageForever)/60, float64(r.Average2Weeks)/60, r.CCU)
+}
+
+func spyHandler(ctx context.Context, args []string, client *steamClient, s settings) (string, error) {
+	query := strings.Join(args, " ")
+
+	appId, _, ok := resolveGame(query)
+	if !ok {
+		return s.msg("unknown_game", query), nil
+	}
+
+	r, err := cachedSteamSpy(ctx, appId, client)
+	if err != nil {
+		return "", err
+	}
+
+	if r.Name == "" {
+		return s.msg("spy_no_data", query), nil
+	}
+
+	return s.formatSteamSpy(r), nil
+}
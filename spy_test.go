@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpyHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		args  []string
+		fault mocksteam.Fault
+		out   string
+		err   error
+	}{
+		{
+			name: "Unknown game",
+			args: []string{"half", "life", "3"},
+			out:  "Error: unknown game half life 3",
+		},
+		{
+			name: "Known game",
+			args: []string{"620"},
+			out:  "{green}Portal 2{clear} - 10,000,000 .. 20,000,000 owners - 18.3h average playtime (2.1h in the last 2 weeks) - peak of 4517 players yesterday",
+		},
+		{
+			name: "No data",
+			args: []string{"999999"},
+			out:  "no steamspy data for 999999",
+		},
+		{
+			name:  "Rate limited",
+			args:  []string{"620"},
+			fault: mocksteam.RateLimited,
+			err:   ErrRateLimited,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("spy", tc.fault)
+
+			out, err := spyHandler(context.Background(), tc.args, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS buckets (name BLOB PRIMARY KEY, seq INTEGER NOT NULL DEFAULT 0);
+CREATE TABLE IF NOT EXISTS kv (bucket BLOB NOT NULL, key BLOB NOT NULL, value BLOB NOT NULL, PRIMARY KEY (bucket, key));
+`
+
+type sqliteBucket struct {
+	tx   *sql.Tx
+	name []byte
+}
+
+func (sb sqliteBucket) Get(key []byte) []byte {
+	var v []byte
+	if err := sb.tx.QueryRow(`SELECT value FROM kv WHERE bucket = ? AND key = ?`, sb.name, key).Scan(&v); err != nil {
+		return nil
+	}
+
+	return v
+}
+
+func (sb sqliteBucket) Put(key, value []byte) error {
+	_, err := sb.tx.Exec(`INSERT INTO kv (bucket, key, value) VALUES (?, ?, ?) ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value`, sb.name, key, value)
+	return err
+}
+
+func (sb sqliteBucket) Delete(key []byte) error {
+	_, err := sb.tx.Exec(`DELETE FROM kv WHERE bucket = ? AND key = ?`, sb.name, key)
+	return err
+}
+
+func (sb sqliteBucket) ForEach(fn func(k, v []byte) error) error {
+	rows, err := sb.tx.Query(`SELECT key, value FROM kv WHERE bucket = ? ORDER BY key`, sb.name)
+	if err != nil {
+		return err
+	}
+
+	type pair struct{ k, v []byte }
+	pairs := []pair{}
+	for rows.Next() {
+		p := pair{}
+		if err := rows.Scan(&p.k, &p.v); err != nil {
+			rows.Close()
+			return err
+		}
+		pairs = append(pairs, p)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range pairs {
+		if err := fn(p.k, p.v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sb sqliteBucket) NextSequence() (uint64, error) {
+	var seq uint64
+	err := sb.tx.QueryRow(`UPDATE buckets SET seq = seq + 1 WHERE name = ? RETURNING seq`, sb.name).Scan(&seq)
+	return seq, err
+}
+
+func (sb sqliteBucket) Clear() (int, error) {
+	res, err := sb.tx.Exec(`DELETE FROM kv WHERE bucket = ?`, sb.name)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSqliteStore(path string) (Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (ss *sqliteStore) tx(readOnly bool, fn func(tx *sql.Tx) error) error {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil || readOnly {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (ss *sqliteStore) View(bucket []byte, fn func(b Bucket) error) error {
+	return ss.tx(true, func(tx *sql.Tx) error {
+		var name []byte
+		err := tx.QueryRow(`SELECT name FROM buckets WHERE name = ?`, bucket).Scan(&name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return fn(sqliteBucket{tx: tx, name: bucket})
+	})
+}
+
+func (ss *sqliteStore) Update(bucket []byte, fn func(b Bucket) error) error {
+	return ss.tx(false, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO buckets (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, bucket); err != nil {
+			return err
+		}
+
+		return fn(sqliteBucket{tx: tx, name: bucket})
+	})
+}
+
+func (ss *sqliteStore) Ping() error {
+	return ss.db.Ping()
+}
+
+func (ss *sqliteStore) Close() error {
+	return ss.db.Close()
+}
+
+func init() {
+	registerStoreDriver("sqlite", openSqliteStore)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type staleTrackerKey struct{}
+
+type staleTracker struct {
+	mu     sync.Mutex
+	oldest time.Time
+}
+
+func withStaleTracker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleTrackerKey{}, &staleTracker{})
+}
+
+func markStale(ctx context.Context, fetched time.Time) {
+	st, ok := ctx.Value(staleTrackerKey{}).(*staleTracker)
+	if !ok {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.oldest.IsZero() || fetched.Before(st.oldest) {
+		st.oldest = fetched
+	}
+}
+
+func staleSince(ctx context.Context) (time.Time, bool) {
+	st, ok := ctx.Value(staleTrackerKey{}).(*staleTracker)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return st.oldest, !st.oldest.IsZero()
+}
+
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortDuration(t *testing.T) {
+	cases := []struct {
+		name string
+		in   time.Duration
+		out  string
+	}{
+		{
+			name: "Seconds",
+			in:   42 * time.Second,
+			out:  "42s",
+		},
+		{
+			name: "Minutes",
+			in:   12*time.Minute + 30*time.Second,
+			out:  "12m",
+		},
+		{
+			name: "Hours",
+			in:   30 * time.Hour,
+			out:  "30h",
+		},
+		{
+			name: "Days",
+			in:   72 * time.Hour,
+			out:  "3d",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, shortDuration(tc.in))
+		})
+	}
+}
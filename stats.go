@@ -0,0 +1,31 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	startTime      = time.Now()
+	commandsServed uint64
+)
+
+func (s settings) formatQuota(used, limit int) string {
+	if limit <= 0 {
+		return s.msg("stats_quota_unlimited", used)
+	}
+
+	return s.msg("stats_quota", used, limit)
+}
+
+func (s settings) statsMessage(now time.Time, cache *lruCache) string {
+	used, limit := apiBudget.Used(now)
+
+	return s.msg("stats",
+		shortDuration(now.Sub(startTime)),
+		atomic.LoadUint64(&commandsServed),
+		s.formatQuota(used, limit),
+		cache.HitRatio()*100,
+		atomic.LoadUint64(&handlerPanics),
+	)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsMessage(t *testing.T) {
+	oldStart, oldServed := startTime, atomic.LoadUint64(&commandsServed)
+	defer func() {
+		startTime = oldStart
+		atomic.StoreUint64(&commandsServed, oldServed)
+	}()
+
+	now := time.Now()
+	startTime = now.Add(-3 * time.Hour)
+	atomic.StoreUint64(&commandsServed, 42)
+
+	oldPanics := atomic.LoadUint64(&handlerPanics)
+	defer atomic.StoreUint64(&handlerPanics, oldPanics)
+	atomic.StoreUint64(&handlerPanics, 2)
+
+	cache := newLRUCache(10, time.Minute)
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+
+	cases := []struct {
+		name     string
+		budget   *dailyBudget
+		expected string
+	}{
+		{
+			name:     "With limit",
+			budget:   &dailyBudget{limit: 100},
+			expected: "up 3h, 42 commands served, 1/100 api requests today, cache hit ratio 75%, 2 panics recovered",
+		},
+		{
+			name:     "Unlimited",
+			budget:   &dailyBudget{},
+			expected: "up 3h, 42 commands served, 1 api requests today, cache hit ratio 75%, 2 panics recovered",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiBudget = tc.budget
+			defer func() { apiBudget = nil }()
+			apiBudget.take(now)
+
+			s := testSettings
+			s.admins = adminSet([]string{"Boss"})
+
+			assert.Equal(t, tc.expected, s.statsMessage(now, cache))
+			assert.True(t, s.isAdmin("boss"))
+		})
+	}
+}
@@ -1,71 +1,201 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"hash/fnv"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
-
-	"gopkg.in/errgo.v2/fmt/errors"
 )
 
 const (
-	resolveVanityUrl      = "https://api.steampowered.com/ISteamUser/ResolveVanityURL/v1/?key=%s&vanityurl=%s"
-	recentlyPlayedUrl     = "https://api.steampowered.com/IPlayerService/GetRecentlyPlayedGames/v1/?key=%s&steamid=%s"
-	playerAchievementsUrl = "https://api.steampowered.com/ISteamUserStats/GetPlayerAchievements/v0001/?key=%s&steamid=%s&appid=%d&format=json&l=en"
+	resolveVanityPath      = "/ISteamUser/ResolveVanityURL/v1/?key=%s&vanityurl=%s"
+	recentlyPlayedPath     = "/IPlayerService/GetRecentlyPlayedGames/v1/?key=%s&steamid=%s&count=%d"
+	playerAchievementsPath = "/ISteamUserStats/GetPlayerAchievements/v0001/?key=%s&steamid=%s&appid=%d&format=json&l=%s"
 )
 
 var (
-	profileNotFoundErr  = errors.New("id not found")
-	profileNotPublicErr = errors.New("profile is not public")
+	ErrProfileNotFound = errors.New("id not found")
+	ErrProfilePrivate  = errors.New("profile is not public")
+	ErrInvalidKey      = errors.New("invalid API key")
+	ErrRateLimited     = errors.New("rate limited by Steam")
+	ErrSteamAPI        = errors.New("Steam API error")
+	ErrEmptyResponse   = errors.New("unexpected end of JSON input")
 )
 
-type resolveVanityURLRes struct {
-	Response struct {
-		SteamId string
-		Success int
+type apiError struct {
+	endpoint string
+	user     string
+	err      error
+}
+
+func (e *apiError) Error() string {
+	if e.user == "" {
+		return fmt.Sprintf("%s: %s", e.endpoint, e.err)
+	}
+
+	return fmt.Sprintf("%s for %s: %s", e.endpoint, e.user, e.err)
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+func apiEndpoint(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "steam api"
+	}
+
+	return strings.Trim(u.Path, "/")
+}
+
+func wrapAPIError(rawUrl, user string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return err
+	}
+
+	return &apiError{endpoint: apiEndpoint(rawUrl), user: user, err: err}
+}
+
+func checkStatus(res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil
+	case res.StatusCode == http.StatusUnauthorized, res.StatusCode == http.StatusForbidden:
+		return ErrInvalidKey
+	case res.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case res.StatusCode >= 500:
+		return ErrSteamAPI
+	}
+
+	return fmt.Errorf("steam api returned status %d", res.StatusCode)
+}
+
+func decodeJSON(r io.Reader, v interface{}) error {
+	err := json.NewDecoder(r).Decode(v)
+	if err == io.EOF {
+		return ErrEmptyResponse
 	}
+
+	return err
 }
 
-func steamGetId(apiKey, user string, client *http.Client) (string, error) {
-	url := fmt.Sprintf(resolveVanityUrl, apiKey, user)
+func getJSON[T any](ctx context.Context, url, user string, client *steamClient) (*T, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, wrapAPIError(url, user, err)
+	}
 
-	j := &resolveVanityURLRes{}
+	return doJSON[T](req, user, client)
+}
 
-	res, err := client.Get(url)
+func postJSON[T any](ctx context.Context, url, user string, body interface{}, client *steamClient) (*T, error) {
+	b, err := json.Marshal(body)
 	if err != nil {
-		return "", err
+		return nil, wrapAPIError(url, user, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, wrapAPIError(url, user, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doJSON[T](req, user, client)
+}
+
+func doJSON[T any](req *http.Request, user string, client *steamClient) (j *T, err error) {
+	defer func() { err = wrapAPIError(req.URL.String(), user, err) }()
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
 	}
 
 	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	err = checkStatus(res)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	err = json.Unmarshal(body, &j)
+	j = new(T)
+
+	err = decodeJSON(res.Body, j)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return j, nil
+}
+
+type resolveVanityURLRes struct {
+	Response struct {
+		SteamId string
+		Success int
+	}
+}
+
+func cachedSteamGetId(ctx context.Context, apiKey, user string, client *steamClient) (SteamID, error) {
+	key := fmt.Sprintf("vanity:%s", strings.ToLower(user))
+
+	id, err := client.cache.GetOrFetch(ctx, key, 0, func(ctx context.Context) (interface{}, error) {
+		return steamGetId(ctx, apiKey, user, client)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id.(SteamID), nil
+}
+
+func steamGetId(ctx context.Context, apiKey, user string, client *steamClient) (SteamID, error) {
+	url := client.apiUrl(resolveVanityPath, apiKey, user)
+
+	j, err := getJSON[resolveVanityURLRes](ctx, url, user, client)
+	if err != nil {
+		return 0, err
 	}
 
 	if j.Response.Success != 1 {
-		return "", profileNotFoundErr
+		return 0, wrapAPIError(url, user, ErrProfileNotFound)
 	}
 
-	return j.Response.SteamId, nil
+	id, err := parseSteamID64(j.Response.SteamId)
+
+	return id, wrapAPIError(url, user, err)
 }
 
 type recentlyPlayedRes struct {
 	Response struct {
-		Games []struct {
-			AppId int
-			Name  string
-		}
+		Games []recentGame
 	}
 }
 
+type recentGame struct {
+	AppId           int
+	Name            string
+	Playtime2Weeks  int `json:"playtime_2weeks"`
+	RtimeLastPlayed int `json:"rtime_last_played"`
+}
+
+func (g recentGame) Hours() float64 {
+	return float64(g.Playtime2Weeks) / 60
+}
+
 func (rpr recentlyPlayedRes) Names() (out []string) {
 	out = []string{}
 
@@ -84,72 +214,181 @@ func (rpr recentlyPlayedRes) Ids() (out []int) {
 	return out
 }
 
-func getRecentlyPlayed(apiKey, id string, client *http.Client) (*recentlyPlayedRes, error) {
-	url := fmt.Sprintf(recentlyPlayedUrl, apiKey, id)
-
-	j := &recentlyPlayedRes{}
+func (rpr *recentlyPlayedRes) SortByPlaytime() {
+	games := rpr.Response.Games
 
-	res, err := client.Get(url)
-	if err != nil {
-		return j, err
-	}
-
-	defer res.Body.Close()
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].Playtime2Weeks > games[j].Playtime2Weeks
+	})
+}
 
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return j, err
-	}
+func (rpr *recentlyPlayedRes) SortByRecency() {
+	games := rpr.Response.Games
 
-	err = json.Unmarshal(body, &j)
-	if err != nil {
-		return j, err
-	}
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].RtimeLastPlayed > games[j].RtimeLastPlayed
+	})
+}
 
-	return j, nil
+func getRecentlyPlayed(ctx context.Context, apiKey string, id SteamID, count int, client *steamClient) (*recentlyPlayedRes, error) {
+	return getJSON[recentlyPlayedRes](ctx, client.apiUrl(recentlyPlayedPath, apiKey, id, count), id.String(), client)
 }
 
-func colourList(in []string) (out []string) {
+var colours = []string{"green", "red", "blue", "orange", "magenta", "cyan", "yellow"}
+
+func colourList(f formatter, in []string) (out []string) {
 	out = []string{}
 
-	colours := []string{"green", "red", "blue", "orange", "magenta", "cyan", "yellow"}
 	cl := len(colours)
 
 	for n, i := range in {
 		c := colours[n%cl]
-		o := fmt.Sprintf("{%s}%s{clear}", c, i)
+		o := f.Colour(c, i)
 		out = append(out, o)
 	}
 
 	return out
 }
 
-func steamLastGame(apiKey, user string, client *http.Client) (string, error) {
-	id, err := steamGetId(apiKey, user, client)
+func hashColour(s string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(s)))
 
-	if errors.Is(profileNotFoundErr)(err) {
-		return fmt.Sprintf("Error: no id found for %s", user), nil
+	return colours[h.Sum32()%uint32(len(colours))]
+}
+
+func hashColourList(f formatter, in []string) (out []string) {
+	out = []string{}
+
+	for _, i := range in {
+		out = append(out, f.Colour(hashColour(i), i))
+	}
+
+	return out
+}
+
+func (s settings) colourList(in []string) []string {
+	if s.hashColours {
+		return hashColourList(s.formatter, in)
 	}
 
+	return colourList(s.formatter, in)
+}
+
+type recentGamesResult struct {
+	User    string             `json:"user"`
+	Link    string             `json:"link"`
+	Games   []recentGameResult `json:"games"`
+	Partial bool               `json:"partial,omitempty"`
+}
+
+type recentGameResult struct {
+	AppId    int     `json:"appid"`
+	Name     string  `json:"name"`
+	Hours    float64 `json:"hours"`
+	Achieved int     `json:"achieved,omitempty"`
+	Total    int     `json:"total,omitempty"`
+}
+
+func fetchRecentGames(ctx context.Context, apiKey, user string, client *steamClient, s settings) (*recentGamesResult, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	recentlyPlayed, err := getRecentlyPlayed(apiKey, id, client)
+	count := s.listLength
+	if s.sortBy == "playtime" {
+		count = 0
+	}
+
+	recentlyPlayed, err := getRecentlyPlayed(ctx, apiKey, id, count, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	r := &recentGamesResult{User: user, Link: client.communityUrl(communityRecentPath, id), Games: []recentGameResult{}}
 	if len(recentlyPlayed.Response.Games) == 0 {
-		return fmt.Sprintf("%s has no recently played steam games", user), nil
+		return r, nil
+	}
+
+	r.User = s.displayName(ctx, apiKey, id, user, client)
+
+	if s.sortBy == "playtime" {
+		recentlyPlayed.SortByPlaytime()
+	}
+
+	games := recentlyPlayed.Response.Games
+	games = games[:s.limit(len(games))]
+
+	gatherCtx, cancel := s.gatherContext(ctx)
+	defer cancel()
+
+	for _, g := range games {
+		rg := recentGameResult{AppId: g.AppId, Name: g.Name, Hours: g.Hours()}
+
+		if s.verbose && gatherCtx.Err() == nil {
+			as, err := getAchievements(gatherCtx, apiKey, id, g.AppId, s.language, client)
+			if err == nil {
+				rg.Achieved, rg.Total = as.Progress()
+			}
+		}
+
+		r.Games = append(r.Games, rg)
+	}
+
+	r.Partial = s.verbose && gatherCtx.Err() != nil && ctx.Err() == nil
+
+	return r, nil
+}
+
+func (s settings) renderRecentGames(ctx context.Context, r *recentGamesResult, client *steamClient) string {
+	if len(r.Games) == 0 {
+		return s.msg("no_recent_games", r.User)
+	}
+
+	names := []string{}
+	for _, g := range r.Games {
+		names = append(names, g.Name)
 	}
 
-	cl := colourList(recentlyPlayed.Names())
+	cl := s.colourList(names)
+	for n, g := range r.Games {
+		cl[n] = fmt.Sprintf("%s (%.1fh)", cl[n], g.Hours)
 
-	return fmt.Sprintf("%s's recently played steam games: %s", user, strings.Join(cl, ", ")), nil
+		if g.Total > 0 {
+			cl[n] = fmt.Sprintf("%s %s", cl[n], achievementStat(s.formatter, g.Achieved, g.Total))
+		}
+	}
+
+	if s.verbose {
+		lines := append([]string{s.msg("recent_games_header", r.User)}, cl...)
+		if r.Partial {
+			lines = append(lines, s.msg("partial_results"))
+		}
+		return s.formatter.Lines(s.withLinkLine(ctx, lines, r.Link, client))
+	}
+
+	out := s.msg("recent_games", r.User, strings.Join(cl, ", "))
+
+	return s.withLink(ctx, out, r.Link, client)
+}
+
+func steamLastGame(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	r, err := fetchRecentGames(ctx, apiKey, user, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return s.renderRecentGames(ctx, r, client), nil
 }
 
 type playerAchievementsRes struct {
+	AppId       int `json:"-"`
 	PlayerStats struct {
 		GameName     string
 		Achievements []playerAchievement
@@ -158,35 +397,48 @@ type playerAchievementsRes struct {
 }
 
 type playerAchievement struct {
+	ApiName     string
 	UnlockTime  int
 	Name        string
 	Description string
+	Icon        string `json:"-"`
 }
 
-func getAchievements(apiKey, id string, appId int, client *http.Client) (*playerAchievementsRes, error) {
-	url := fmt.Sprintf(playerAchievementsUrl, apiKey, id, appId)
+func getAchievements(ctx context.Context, apiKey string, id SteamID, appId int, lang string, client *steamClient) (j *playerAchievementsRes, err error) {
+	url := client.apiUrl(playerAchievementsPath, apiKey, id, appId, lang)
+	defer func() { err = wrapAPIError(url, id.String(), err) }()
 
-	j := &playerAchievementsRes{}
+	j = &playerAchievementsRes{}
 
-	res, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return j, err
 	}
 
-	defer res.Body.Close()
-
-	body, err := ioutil.ReadAll(res.Body)
+	res, err := client.Do(req)
 	if err != nil {
 		return j, err
 	}
 
-	err = json.Unmarshal(body, &j)
-	if err != nil {
-		return j, err
+	defer res.Body.Close()
+
+	statusErr := checkStatus(res)
+
+	err = decodeJSON(res.Body, &j)
+	switch j.PlayerStats.Error {
+	case "Profile is not public":
+		return j, ErrProfilePrivate
+	case "Requested app has no stats":
+		j.PlayerStats.Achievements = nil
+		return j, nil
 	}
 
-	if j.PlayerStats.Error == "Profile is not public" {
-		return j, profileNotPublicErr
+	if statusErr != nil {
+		return j, statusErr
+	}
+
+	if err != nil {
+		return j, err
 	}
 
 	return j, nil
@@ -210,71 +462,169 @@ func newestAchievement(am map[string]*playerAchievementsRes) (*playerAchievement
 	return game, newest
 }
 
-func getAchievementCount(as *playerAchievementsRes) string {
-	total := len(as.PlayerStats.Achievements)
+func (par playerAchievementsRes) Progress() (achieved, total int) {
+	total = len(par.PlayerStats.Achievements)
 
-	achieved := 0
-	for _, a := range as.PlayerStats.Achievements {
+	for _, a := range par.PlayerStats.Achievements {
 		if a.UnlockTime > 0 {
 			achieved += 1
 		}
 	}
 
-	c := func(a, t int) string {
-		if a == t {
-			return "green"
-		}
+	return achieved, total
+}
 
-		return "yellow"
+func achievementStat(f formatter, achieved, total int) string {
+	colour := "yellow"
+	if achieved == total {
+		colour = "green"
 	}
 
-	colour := c(total, achieved)
-
-	return fmt.Sprintf("{%s}%d/%d{clear}", colour, achieved, total)
+	return f.Stat(colour, fmt.Sprintf("%d/%d", achieved, total))
 }
 
-func steamLastAchievement(apiKey, user string, client *http.Client) (string, error) {
-	id, err := steamGetId(apiKey, user, client)
+func getAchievementCount(f formatter, as *playerAchievementsRes) string {
+	achieved, total := as.Progress()
 
-	if errors.Is(profileNotFoundErr)(err) {
-		return fmt.Sprintf("Error: no id found for %s", user), nil
-	}
+	return achievementStat(f, achieved, total)
+}
 
+type lastAchievementResult struct {
+	User        string  `json:"user"`
+	Found       bool    `json:"found"`
+	Game        string  `json:"game,omitempty"`
+	AppId       int     `json:"appid,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Rarity      float64 `json:"rarity,omitempty"`
+	HasRarity   bool    `json:"-"`
+	Achieved    int     `json:"achieved,omitempty"`
+	Total       int     `json:"total,omitempty"`
+	UnlockTime  int     `json:"unlock_time,omitempty"`
+	Link        string  `json:"link,omitempty"`
+	Partial     bool    `json:"partial,omitempty"`
+}
+
+func fetchLastAchievement(ctx context.Context, apiKey, user string, client *steamClient, s settings) (*lastAchievementResult, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	recentlyPlayed, err := getRecentlyPlayed(apiKey, id, client)
+	recentlyPlayed, err := getRecentlyPlayed(ctx, apiKey, id, s.achievementGames, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	gatherCtx, cancel := s.gatherContext(ctx)
+	defer cancel()
+
+	r := &lastAchievementResult{User: user}
+
+	recentlyPlayed.SortByRecency()
+
+	latest := 0
 	achievementsMap := make(map[string]*playerAchievementsRes)
-	for _, i := range recentlyPlayed.Ids() {
-		as, err := getAchievements(apiKey, id, i, client)
+	for _, g := range recentlyPlayed.Response.Games {
+		if latest > 0 && g.RtimeLastPlayed > 0 && g.RtimeLastPlayed < latest {
+			break
+		}
+
+		i := g.AppId
+		as, err := getAchievements(gatherCtx, apiKey, id, i, s.language, client)
 
-		if errors.Is(profileNotPublicErr)(err) {
-			return "Error: profile is not public", nil
+		if gatherCtx.Err() != nil && ctx.Err() == nil {
+			r.Partial = true
+			break
 		}
 
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
+		as.AppId = i
 		game := as.PlayerStats.GameName
 		achievementsMap[game] = as
+
+		for _, a := range as.PlayerStats.Achievements {
+			if a.UnlockTime > latest {
+				latest = a.UnlockTime
+			}
+		}
 	}
 
-	if err != nil {
-		return "", err
+	if r.Partial && len(achievementsMap) == 0 {
+		return nil, gatherCtx.Err()
 	}
 
 	game, newest := newestAchievement(achievementsMap)
-	count := getAchievementCount(game)
-
 	if newest.UnlockTime == 0 {
-		return fmt.Sprintf("%s has no recently unlocked steam achievements", user), nil
+		return r, nil
+	}
+
+	newest = s.withSchema(ctx, apiKey, game.AppId, newest, client)
+
+	r.Found = true
+	r.Game = game.PlayerStats.GameName
+	r.AppId = game.AppId
+	r.Name = newest.Name
+	r.Description = s.achievementDescription(ctx, apiKey, game.AppId, newest, client)
+	r.Rarity, r.HasRarity = achievementRarity(ctx, game.AppId, newest.ApiName, client)
+	r.Achieved, r.Total = game.Progress()
+	r.UnlockTime = newest.UnlockTime
+	r.User = s.displayName(ctx, apiKey, id, user, client)
+	r.Link = client.storeUrl(storeAppPath, game.AppId)
+
+	return r, nil
+}
+
+func (s settings) renderLastAchievement(ctx context.Context, r *lastAchievementResult, client *steamClient) string {
+	if !r.Found {
+		return s.msg("no_recent_achievements", r.User)
+	}
+
+	count := achievementStat(s.formatter, r.Achieved, r.Total)
+	unlocked := s.formatUnix(r.UnlockTime)
+
+	name := r.Name
+	if r.HasRarity {
+		name = fmt.Sprintf("%s (%s)", name, s.msg("rarity", r.Rarity))
+	}
+
+	if s.verbose {
+		lines := []string{s.msg("last_achievement_header", r.User, r.Game, name)}
+		if r.Description != "" {
+			lines = append(lines, r.Description)
+		}
+		lines = append(lines, s.msg("progress", count, s.progressBar(r.Achieved, r.Total)), s.msg("unlocked", unlocked))
+		if r.Partial {
+			lines = append(lines, s.msg("partial_results"))
+		}
+		return s.formatter.Lines(s.withLinkLine(ctx, lines, r.Link, client))
+	}
+
+	out := s.msg("last_achievement", r.User, r.Game, name, r.Description, count, unlocked)
+	if r.Partial {
+		out = fmt.Sprintf("%s %s", out, s.msg("partial_results"))
+	}
+
+	return s.withLink(ctx, out, r.Link, client)
+}
+
+func steamLastAchievement(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	r, err := fetchLastAchievement(ctx, apiKey, user, client, s)
+
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	if errors.Is(err, ErrProfilePrivate) {
+		return s.msg("profile_not_public"), nil
+	}
+
+	if err != nil {
+		return "", err
 	}
 
-	return fmt.Sprintf("%s's last steam achievement: %s - %s (%s) (%s)", user, game.PlayerStats.GameName, newest.Name, newest.Description, count), nil
+	return s.renderLastAchievement(ctx, r, client), nil
 }
@@ -2,23 +2,42 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+var testSettings = settings{
+	location:   time.UTC,
+	dateFormat: dateFormats["iso"],
+	language:   "en",
+	sortBy:     "recency",
+	messages:   catalogs["en"],
+	formatter:  ircFormatter{},
+}
+
 type RoundTripFunc func(req *http.Request) *http.Response
 
 func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return f(req), nil
 }
 
-func NewTestClient(statusCode int, body string) *http.Client {
+var testClient = newTestSteamClient(http.DefaultClient)
+
+func newTestSteamClient(hc *http.Client) *steamClient {
+	c, _ := newSteamClient(WithHTTPClient(hc))
+	return c
+}
+
+func NewTestClient(statusCode int, body string) *steamClient {
 	f := func(req *http.Request) *http.Response {
 		return &http.Response{
 			StatusCode: statusCode,
@@ -27,12 +46,12 @@ func NewTestClient(statusCode int, body string) *http.Client {
 		}
 	}
 
-	return &http.Client{
+	return newTestSteamClient(&http.Client{
 		Transport: RoundTripFunc(f),
-	}
+	})
 }
 
-func NewConditionalTestClient(bodies map[string]string) *http.Client {
+func NewConditionalTestClient(bodies map[string]string) *steamClient {
 	f := func(req *http.Request) *http.Response {
 		body := bodies[req.URL.String()]
 
@@ -43,9 +62,19 @@ func NewConditionalTestClient(bodies map[string]string) *http.Client {
 		}
 	}
 
-	return &http.Client{
+	return newTestSteamClient(&http.Client{
 		Transport: RoundTripFunc(f),
+	})
+}
+
+func countingTransport(rt http.RoundTripper, calls *int) http.RoundTripper {
+	f := func(req *http.Request) *http.Response {
+		*calls++
+		res, _ := rt.RoundTrip(req)
+		return res
 	}
+
+	return RoundTripFunc(f)
 }
 
 func openTestFile(t *testing.T, test, filename string) []byte {
@@ -63,25 +92,25 @@ func TestSteamGetId(t *testing.T) {
 	cases := []struct {
 		name     string
 		testFile string
-		id       string
+		id       SteamID
 		errMsg   string
 	}{
 		{
 			name:     "Empty data returned",
 			testFile: "empty",
-			id:       "",
+			id:       0,
 			errMsg:   "unexpected end of JSON input",
 		},
 		{
 			name:     "No match",
 			testFile: "no_match.json",
-			id:       "",
-			errMsg:   profileNotFoundErr.Error(),
+			id:       0,
+			errMsg:   ErrProfileNotFound.Error(),
 		},
 		{
 			name:     "Success",
 			testFile: "success.json",
-			id:       "999",
+			id:       999,
 			errMsg:   "",
 		},
 	}
@@ -91,7 +120,7 @@ func TestSteamGetId(t *testing.T) {
 			body := openTestFile(t, "TestSteamGetId", tc.testFile)
 			client := NewTestClient(200, string(body))
 
-			id, err := steamGetId("key", "user", client)
+			id, err := steamGetId(context.Background(), "key", "user", client)
 
 			if tc.errMsg == "" {
 				assert.Nil(t, err)
@@ -104,14 +133,98 @@ func TestSteamGetId(t *testing.T) {
 	}
 }
 
+func TestCheckStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		errMsg     string
+	}{
+		{
+			name:       "Ok",
+			statusCode: 200,
+			errMsg:     "",
+		},
+		{
+			name:       "Forbidden",
+			statusCode: 403,
+			errMsg:     ErrInvalidKey.Error(),
+		},
+		{
+			name:       "Too many requests",
+			statusCode: 429,
+			errMsg:     ErrRateLimited.Error(),
+		},
+		{
+			name:       "Server error",
+			statusCode: 500,
+			errMsg:     ErrSteamAPI.Error(),
+		},
+		{
+			name:       "Other",
+			statusCode: 404,
+			errMsg:     "steam api returned status 404",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkStatus(&http.Response{StatusCode: tc.statusCode})
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		out    string
+		errMsg string
+	}{
+		{
+			name: "Success",
+			body: `{"response":{"steamid":"999","success":1}}`,
+			out:  "999",
+		},
+		{
+			name:   "Empty",
+			body:   "",
+			errMsg: "unexpected end of JSON input",
+		},
+		{
+			name:   "Html error page",
+			body:   "<html></html>",
+			errMsg: "invalid character '<'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			j := resolveVanityURLRes{}
+
+			err := decodeJSON(strings.NewReader(tc.body), &j)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+
+			assert.Equal(t, tc.out, j.Response.SteamId)
+		})
+	}
+}
+
 func generateRecentlyPlayedRes(count int) recentlyPlayedRes {
 	r := recentlyPlayedRes{}
 
 	for i := 0; i < count; i++ {
-		g := struct {
-			AppId int
-			Name  string
-		}{
+		g := recentGame{
 			AppId: 1,
 			Name:  "game",
 		}
@@ -188,6 +301,38 @@ func TestRecentlyPlayedResIds(t *testing.T) {
 	}
 }
 
+func TestRecentGameHours(t *testing.T) {
+	cases := []struct {
+		name    string
+		minutes int
+		out     float64
+	}{
+		{
+			name:    "No playtime",
+			minutes: 0,
+			out:     0,
+		},
+		{
+			name:    "Whole hours",
+			minutes: 120,
+			out:     2,
+		},
+		{
+			name:    "Partial hours",
+			minutes: 372,
+			out:     6.2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := recentGame{Playtime2Weeks: tc.minutes}
+
+			assert.InDelta(t, tc.out, g.Hours(), 0.001)
+		})
+	}
+}
+
 func TestGetRecentlyPlayed(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -216,7 +361,7 @@ func TestGetRecentlyPlayed(t *testing.T) {
 			body := openTestFile(t, "TestGetRecentlyPlayed", tc.testFile)
 			client := NewTestClient(200, string(body))
 
-			_, err := getRecentlyPlayed("key", "id", client)
+			_, err := getRecentlyPlayed(context.Background(), "key", 999, 0, client)
 
 			if tc.errMsg == "" {
 				assert.Nil(t, err)
@@ -252,7 +397,7 @@ func TestColourList(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			cl := colourList(tc.in)
+			cl := colourList(ircFormatter{}, tc.in)
 			assert.Equal(t, tc.out, cl)
 		})
 	}
@@ -260,11 +405,31 @@ func TestColourList(t *testing.T) {
 
 func TestColourLoop(t *testing.T) {
 	in := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
-	out := colourList(in)
+	out := colourList(ircFormatter{}, in)
 
 	assert.Equal(t, out[7], "{green}h{clear}")
 }
 
+func TestHashColourList(t *testing.T) {
+	in := []string{"Hades", "Celeste", "hades", "Hades"}
+	out := hashColourList(ircFormatter{}, in)
+
+	assert.Equal(t, 4, len(out))
+	assert.Equal(t, out[0], out[3])
+	assert.Equal(t, fmt.Sprintf("{%s}hades{clear}", hashColour("Hades")), out[2])
+	assert.Contains(t, colours, hashColour("Celeste"))
+}
+
+func TestSettingsColourList(t *testing.T) {
+	s := testSettings
+
+	s.hashColours = false
+	assert.Equal(t, []string{"{green}a{clear}", "{red}b{clear}"}, s.colourList([]string{"a", "b"}))
+
+	s.hashColours = true
+	assert.Equal(t, hashColourList(ircFormatter{}, []string{"a", "b"}), s.colourList([]string{"a", "b"}))
+}
+
 func TestSteamLastGame(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -293,13 +458,13 @@ func TestSteamLastGame(t *testing.T) {
 		{
 			name:      "three games",
 			testFiles: [2]string{"id_found.json", "three_games.json"},
-			out:       "id's recently played steam games: {green}1{clear}, {red}2{clear}, {blue}3{clear}",
+			out:       "id's recently played steam games: {green}1{clear} (95.2h), {red}2{clear} (13.8h), {blue}3{clear} (0.0h)",
 			errMsg:    "",
 		},
 	}
 
-	rvu := fmt.Sprintf(resolveVanityUrl, "key", "id")
-	rpu := fmt.Sprintf(recentlyPlayedUrl, "key", "999")
+	rvu := testClient.apiUrl(resolveVanityPath, "key", "id")
+	rpu := testClient.apiUrl(recentlyPlayedPath, "key", "999", 0)
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -311,7 +476,7 @@ func TestSteamLastGame(t *testing.T) {
 			}
 			client := NewConditionalTestClient(bodies)
 
-			out, err := steamLastGame("key", "id", client)
+			out, err := steamLastGame(context.Background(), "key", "id", client, testSettings)
 
 			assert.Equal(t, out, tc.out)
 
@@ -324,6 +489,64 @@ func TestSteamLastGame(t *testing.T) {
 	}
 }
 
+func TestSteamLastGameByPlaytime(t *testing.T) {
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"):      string(openTestFile(t, "TestSteamLastGame", "id_found.json")),
+		testClient.apiUrl(recentlyPlayedPath, "key", "999", 0): `{"response":{"games":[{"name":"1","playtime_2weeks":60},{"name":"2","playtime_2weeks":120},{"name":"3","playtime_2weeks":30}]}}`,
+	}
+	client := NewConditionalTestClient(bodies)
+
+	s := testSettings
+	s.sortBy = "playtime"
+
+	out, err := steamLastGame(context.Background(), "key", "id", client, s)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id's recently played steam games: {green}2{clear} (2.0h), {red}1{clear} (1.0h), {blue}3{clear} (0.5h)", out)
+}
+
+func TestSteamLastGameVerbose(t *testing.T) {
+	cases := []struct {
+		name     string
+		testFile string
+		out      string
+	}{
+		{
+			name:     "achievements found",
+			testFile: "achievements.json",
+			out:      "id's recently played steam games | {green}1{clear} (95.2h) {yellow}1/14{clear}",
+		},
+		{
+			name:     "no stats",
+			testFile: "no_stats.json",
+			out:      "id's recently played steam games | {green}1{clear} (95.2h)",
+		},
+	}
+
+	rvu := testClient.apiUrl(resolveVanityPath, "key", "id")
+	rpu := testClient.apiUrl(recentlyPlayedPath, "key", "999", 0)
+	pau := testClient.apiUrl(playerAchievementsPath, "key", "999", 999, "en")
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bodies := map[string]string{
+				rvu: string(openTestFile(t, "TestSteamLastGameVerbose", "id_found.json")),
+				rpu: string(openTestFile(t, "TestSteamLastGameVerbose", "one_game.json")),
+				pau: string(openTestFile(t, "TestSteamLastGameVerbose", tc.testFile)),
+			}
+			client := NewConditionalTestClient(bodies)
+
+			s := testSettings
+			s.verbose = true
+
+			out, err := steamLastGame(context.Background(), "key", "id", client, s)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
 func TestGetAchievements(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -347,7 +570,7 @@ func TestGetAchievements(t *testing.T) {
 			body := openTestFile(t, "TestGetAchievements", tc.testFile)
 			client := NewTestClient(200, string(body))
 
-			_, err := getRecentlyPlayed("key", "id", client)
+			_, err := getRecentlyPlayed(context.Background(), "key", 999, 0, client)
 
 			if tc.errMsg == "" {
 				assert.Nil(t, err)
@@ -358,6 +581,117 @@ func TestGetAchievements(t *testing.T) {
 	}
 }
 
+func TestRecentlyPlayedCount(t *testing.T) {
+	cases := []struct {
+		name    string
+		command commandFunc
+		s       settings
+		count   string
+	}{
+		{
+			name:    "Recent games by recency",
+			command: steamLastGame,
+			s:       settings{sortBy: "recency", listLength: 3},
+			count:   "3",
+		},
+		{
+			name:    "Recent games by playtime",
+			command: steamLastGame,
+			s:       settings{sortBy: "playtime", listLength: 3},
+			count:   "0",
+		},
+		{
+			name:    "Last achievement",
+			command: steamLastAchievement,
+			s:       settings{achievementGames: 5},
+			count:   "5",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			count := ""
+			f := func(req *http.Request) *http.Response {
+				body := `{"response":{"steamid":"999","success":1}}`
+				if strings.Contains(req.URL.Path, "GetRecentlyPlayedGames") {
+					count = req.URL.Query().Get("count")
+					body = "{}"
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+					Header:     make(http.Header),
+				}
+			}
+			client := newTestSteamClient(&http.Client{Transport: RoundTripFunc(f)})
+
+			s := testSettings
+			s.sortBy = tc.s.sortBy
+			s.listLength = tc.s.listLength
+			s.achievementGames = tc.s.achievementGames
+
+			_, err := tc.command(context.Background(), "key", "id", client, s)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.count, count)
+		})
+	}
+}
+
+func TestGetAchievementsStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		err        error
+	}{
+		{
+			name:       "Private profile",
+			statusCode: 403,
+			body:       `{"playerstats":{"error":"Profile is not public","success":false}}`,
+			err:        ErrProfilePrivate,
+		},
+		{
+			name:       "Game without stats",
+			statusCode: 400,
+			body:       `{"playerstats":{"error":"Requested app has no stats","success":false}}`,
+		},
+		{
+			name:       "Invalid key",
+			statusCode: 403,
+			body:       "<html><body>Forbidden</body></html>",
+			err:        ErrInvalidKey,
+		},
+		{
+			name:       "Server error",
+			statusCode: 500,
+			body:       "<html><body>Internal Server Error</body></html>",
+			err:        ErrSteamAPI,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(tc.statusCode, tc.body)
+
+			_, err := getAchievements(context.Background(), "key", 999, 1, "en", client)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.err)
+
+			var ae *apiError
+			assert.ErrorAs(t, err, &ae)
+			assert.Equal(t, "ISteamUserStats/GetPlayerAchievements/v0001", ae.endpoint)
+			assert.Equal(t, "999", ae.user)
+		})
+	}
+}
+
 func TestNewestAchievement(t *testing.T) {
 	makeResMap := func(ids ...int) map[string]*playerAchievementsRes {
 		rm := make(map[string]*playerAchievementsRes)
@@ -467,7 +801,7 @@ func TestGetAchievementCount(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			r := makeRes(tc.id)
-			out := getAchievementCount(r)
+			out := getAchievementCount(ircFormatter{}, r)
 
 			assert.Equal(t, tc.out, out)
 		})
@@ -477,65 +811,74 @@ func TestGetAchievementCount(t *testing.T) {
 func TestSteamLastAchievement(t *testing.T) {
 	cases := []struct {
 		name      string
-		testFiles [3]string
+		testFiles [4]string
 		out       string
 		errMsg    string
 	}{
 		{
 			name:      "get id empty",
-			testFiles: [3]string{"empty", "empty", "empty"},
+			testFiles: [4]string{"empty", "empty", "empty", "empty"},
 			out:       "",
 			errMsg:    "unexpected end of JSON input",
 		},
 		{
 			name:      "id not found",
-			testFiles: [3]string{"id_not_found.json", "empty", "empty"},
+			testFiles: [4]string{"id_not_found.json", "empty", "empty", "empty"},
 			out:       "Error: no id found for id",
 			errMsg:    "",
 		},
 		{
 			name:      "id found, recently played empty",
-			testFiles: [3]string{"id_found.json", "empty", "empty"},
+			testFiles: [4]string{"id_found.json", "empty", "empty", "empty"},
 			out:       "",
 			errMsg:    "unexpected end of JSON input",
 		},
 		{
 			name:      "no recently played games",
-			testFiles: [3]string{"id_found.json", "no_games.json", "empty"},
+			testFiles: [4]string{"id_found.json", "no_games.json", "empty", "empty"},
 			out:       "id has no recently unlocked steam achievements",
 			errMsg:    "",
 		},
 		{
 			name:      "get achievements empty",
-			testFiles: [3]string{"id_found.json", "one_game.json", "empty"},
+			testFiles: [4]string{"id_found.json", "one_game.json", "empty", "empty"},
 			out:       "",
 			errMsg:    "unexpected end of JSON input",
 		},
 		{
 			name:      "achievements found",
-			testFiles: [3]string{"id_found.json", "one_game.json", "achievements.json"},
-			out:       "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE () ({yellow}1/14{clear})",
+			testFiles: [4]string{"id_found.json", "one_game.json", "achievements.json", "empty"},
+			out:       "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE () ({yellow}1/14{clear}) (unlocked 2021-11-30 23:51 UTC)",
+			errMsg:    "",
+		},
+		{
+			name:      "achievements found with rarity",
+			testFiles: [4]string{"id_found.json", "one_game.json", "achievements.json", "percentages.json"},
+			out:       "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE (43.3% of players) () ({yellow}1/14{clear}) (unlocked 2021-11-30 23:51 UTC)",
 			errMsg:    "",
 		},
 	}
 
-	rvu := fmt.Sprintf(resolveVanityUrl, "key", "id")
-	rpu := fmt.Sprintf(recentlyPlayedUrl, "key", "999")
-	pau := fmt.Sprintf(playerAchievementsUrl, "key", "999", 999)
+	rvu := testClient.apiUrl(resolveVanityPath, "key", "id")
+	rpu := testClient.apiUrl(recentlyPlayedPath, "key", "999", 0)
+	pau := testClient.apiUrl(playerAchievementsPath, "key", "999", 999, "en")
+	gpu := testClient.apiUrl(globalPercentagesPath, 999)
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			rvub := openTestFile(t, "TestSteamLastAchievement", tc.testFiles[0])
 			rpub := openTestFile(t, "TestSteamLastAchievement", tc.testFiles[1])
 			paub := openTestFile(t, "TestSteamLastAchievement", tc.testFiles[2])
+			gpub := openTestFile(t, "TestSteamLastAchievement", tc.testFiles[3])
 			bodies := map[string]string{
 				rvu: string(rvub),
 				rpu: string(rpub),
 				pau: string(paub),
+				gpu: string(gpub),
 			}
 			client := NewConditionalTestClient(bodies)
 
-			out, err := steamLastAchievement("key", "id", client)
+			out, err := steamLastAchievement(context.Background(), "key", "id", client, testSettings)
 
 			assert.Equal(t, out, tc.out)
 
@@ -547,3 +890,371 @@ func TestSteamLastAchievement(t *testing.T) {
 		})
 	}
 }
+
+func TestSteamLastAchievementVerbose(t *testing.T) {
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"):                  string(openTestFile(t, "TestSteamLastAchievement", "id_found.json")),
+		testClient.apiUrl(recentlyPlayedPath, "key", "999", 0):             string(openTestFile(t, "TestSteamLastAchievement", "one_game.json")),
+		testClient.apiUrl(playerAchievementsPath, "key", "999", 999, "en"): string(openTestFile(t, "TestSteamLastAchievement", "achievements.json")),
+		testClient.apiUrl(globalPercentagesPath, 999):                      string(openTestFile(t, "TestSteamLastAchievement", "percentages.json")),
+	}
+	client := NewConditionalTestClient(bodies)
+
+	s := testSettings
+	s.verbose = true
+	s.links = true
+
+	out, err := steamLastAchievement(context.Background(), "key", "id", client, s)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE (43.3% of players) | progress: {yellow}1/14{clear} ▱▱▱▱▱ 7% | unlocked: 2021-11-30 23:51 UTC | https://store.steampowered.com/app/999", out)
+}
+
+type blockingTransport struct {
+	next  http.RoundTripper
+	block string
+}
+
+func (bt blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.String(), bt.block) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+
+	return bt.next.RoundTrip(req)
+}
+
+func TestSteamLastAchievementPartial(t *testing.T) {
+	cases := []struct {
+		name    string
+		block   string
+		verbose bool
+		out     string
+		errMsg  string
+	}{
+		{
+			name:  "Second game too slow",
+			block: "appid=1000",
+			out:   "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE () ({yellow}1/14{clear}) (unlocked 2021-11-30 23:51 UTC) (partial results, Steam was slow to respond)",
+		},
+		{
+			name:    "Second game too slow verbose",
+			block:   "appid=1000",
+			verbose: true,
+			out:     "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE | progress: {yellow}1/14{clear} ▱▱▱▱▱ 7% | unlocked: 2021-11-30 23:51 UTC | (partial results, Steam was slow to respond)",
+		},
+		{
+			name:   "Every game too slow",
+			block:  "GetPlayerAchievements",
+			errMsg: context.DeadlineExceeded.Error(),
+		},
+	}
+
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"):                  string(openTestFile(t, "TestSteamLastAchievement", "id_found.json")),
+		testClient.apiUrl(recentlyPlayedPath, "key", "999", 0):             `{"response":{"games":[{"appid":999,"name":"1"},{"appid":1000,"name":"2"}]}}`,
+		testClient.apiUrl(playerAchievementsPath, "key", "999", 999, "en"): string(openTestFile(t, "TestSteamLastAchievement", "achievements.json")),
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newTestSteamClient(&http.Client{
+				Transport: blockingTransport{next: NewConditionalTestClient(bodies).httpClient.Transport, block: tc.block},
+			})
+
+			s := testSettings
+			s.verbose = tc.verbose
+			s.gatherBudget = 20 * time.Millisecond
+
+			out, err := steamLastAchievement(context.Background(), "key", "id", client, s)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestSteamLastAchievementStopsEarly(t *testing.T) {
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"):                  string(openTestFile(t, "TestSteamLastAchievement", "id_found.json")),
+		testClient.apiUrl(recentlyPlayedPath, "key", "999", 0):             `{"response":{"games":[{"appid":1000,"name":"2","rtime_last_played":1600000000},{"appid":999,"name":"1","rtime_last_played":1638400000}]}}`,
+		testClient.apiUrl(playerAchievementsPath, "key", "999", 999, "en"): string(openTestFile(t, "TestSteamLastAchievement", "achievements.json")),
+	}
+
+	client := newTestSteamClient(&http.Client{
+		Transport: blockingTransport{next: NewConditionalTestClient(bodies).httpClient.Transport, block: "appid=1000"},
+	})
+
+	s := testSettings
+	s.gatherBudget = 20 * time.Millisecond
+
+	out, err := steamLastAchievement(context.Background(), "key", "id", client, s)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE () ({yellow}1/14{clear}) (unlocked 2021-11-30 23:51 UTC)", out)
+}
+
+func TestSteamLastAchievementSkipsGamesWithoutStats(t *testing.T) {
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"):                  string(openTestFile(t, "TestSteamLastAchievement", "id_found.json")),
+		testClient.apiUrl(recentlyPlayedPath, "key", "999", 0):             `{"response":{"games":[{"appid":1000,"name":"2"},{"appid":999,"name":"1"}]}}`,
+		testClient.apiUrl(playerAchievementsPath, "key", "999", 999, "en"): string(openTestFile(t, "TestSteamLastAchievement", "achievements.json")),
+	}
+
+	f := func(req *http.Request) *http.Response {
+		if strings.Contains(req.URL.RawQuery, "appid=1000") {
+			return NewTestClient(400, `{"playerstats":{"error":"Requested app has no stats","success":false}}`).httpClient.Transport.(RoundTripFunc)(req)
+		}
+
+		return NewConditionalTestClient(bodies).httpClient.Transport.(RoundTripFunc)(req)
+	}
+	client := newTestSteamClient(&http.Client{Transport: RoundTripFunc(f)})
+
+	out, err := steamLastAchievement(context.Background(), "key", "id", client, testSettings)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id's last steam achievement: SUPERHOT: MIND CONTROL DELETE - MORE () ({yellow}1/14{clear}) (unlocked 2021-11-30 23:51 UTC)", out)
+}
+
+func TestRecentlyPlayedResSortByRecency(t *testing.T) {
+	rpr := recentlyPlayedRes{}
+	rpr.Response.Games = []recentGame{
+		{Name: "old", RtimeLastPlayed: 10, Playtime2Weeks: 100},
+		{Name: "unknown quiet", Playtime2Weeks: 1},
+		{Name: "new", RtimeLastPlayed: 20},
+		{Name: "unknown busy", Playtime2Weeks: 50},
+	}
+
+	rpr.SortByRecency()
+
+	assert.Equal(t, []string{"new", "old", "unknown quiet", "unknown busy"}, rpr.Names())
+}
+
+func TestRecentlyPlayedResSortByRecencyWithoutTimes(t *testing.T) {
+	rpr := recentlyPlayedRes{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"response":{"games":[{"appid":1,"name":"latest","playtime_2weeks":5},{"appid":2,"name":"earlier","playtime_2weeks":500},{"appid":3,"name":"earliest","playtime_2weeks":50}]}}`), &rpr))
+
+	rpr.SortByRecency()
+
+	assert.Equal(t, []string{"latest", "earlier", "earliest"}, rpr.Names(), "keeps steam's order")
+}
+
+func TestWrapAPIError(t *testing.T) {
+	cases := []struct {
+		name     string
+		url      string
+		user     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "With user",
+			url:      "https://api.steampowered.com/ISteamUser/ResolveVanityURL/v1/?key=secret&vanityurl=bob",
+			user:     "bob",
+			err:      ErrProfileNotFound,
+			expected: "ISteamUser/ResolveVanityURL/v1 for bob: id not found",
+		},
+		{
+			name:     "Without user",
+			url:      "https://store.steampowered.com/api/appdetails?appids=10",
+			err:      ErrRateLimited,
+			expected: "api/appdetails: rate limited by Steam",
+		},
+		{
+			name:     "Already wrapped",
+			url:      "https://api.steampowered.com/IPlayerService/GetRecentlyPlayedGames/v1/",
+			err:      &apiError{endpoint: "ISteamUser/GetPlayerSummaries/v2", user: "1", err: ErrSteamAPI},
+			expected: "ISteamUser/GetPlayerSummaries/v2 for 1: Steam API error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := wrapAPIError(tc.url, tc.user, tc.err)
+
+			assert.EqualError(t, err, tc.expected)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	assert.Nil(t, wrapAPIError("https://api.steampowered.com/", "bob", nil))
+}
+
+func TestFetchRecentGames(t *testing.T) {
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"):      string(openTestFile(t, "TestSteamLastGame", "id_found.json")),
+		testClient.apiUrl(recentlyPlayedPath, "key", "999", 0): string(openTestFile(t, "TestSteamLastGame", "three_games.json")),
+	}
+	client := NewConditionalTestClient(bodies)
+
+	r, err := fetchRecentGames(context.Background(), "key", "id", client, testSettings)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id", r.User)
+	assert.Equal(t, []string{"1", "2", "3"}, []string{r.Games[0].Name, r.Games[1].Name, r.Games[2].Name})
+	assert.InDelta(t, 95.2, r.Games[0].Hours, 0.05)
+	assert.False(t, r.Partial)
+}
+
+func TestFetchRecentGamesNotFound(t *testing.T) {
+	bodies := map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "id"): string(openTestFile(t, "TestSteamLastGame", "id_not_found.json")),
+	}
+	client := NewConditionalTestClient(bodies)
+
+	_, err := fetchRecentGames(context.Background(), "key", "id", client, testSettings)
+
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+}
+
+func TestRenderRecentGames(t *testing.T) {
+	cases := []struct {
+		name   string
+		result recentGamesResult
+		out    string
+	}{
+		{
+			name:   "no games",
+			result: recentGamesResult{User: "id"},
+			out:    "id has no recently played steam games",
+		},
+		{
+			name: "with achievements",
+			result: recentGamesResult{
+				User: "id",
+				Games: []recentGameResult{
+					{Name: "1", Hours: 1.5, Achieved: 2, Total: 2},
+					{Name: "2", Hours: 0.5},
+				},
+			},
+			out: "id's recently played steam games: {green}1{clear} (1.5h) {green}2/2{clear}, {red}2{clear} (0.5h)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := testSettings.renderRecentGames(context.Background(), &tc.result, testClient)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestRenderLastAchievement(t *testing.T) {
+	cases := []struct {
+		name   string
+		result lastAchievementResult
+		out    string
+	}{
+		{
+			name:   "not found",
+			result: lastAchievementResult{User: "id"},
+			out:    "id has no recently unlocked steam achievements",
+		},
+		{
+			name: "found",
+			result: lastAchievementResult{
+				User:       "id",
+				Found:      true,
+				Game:       "game",
+				Name:       "ach",
+				Rarity:     12.5,
+				HasRarity:  true,
+				Achieved:   1,
+				Total:      4,
+				UnlockTime: 0,
+			},
+			out: "id's last steam achievement: game - ach (12.5% of players) () ({yellow}1/4{clear}) (unlocked 1970-01-01 00:00 UTC)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := testSettings.renderLastAchievement(context.Background(), &tc.result, testClient)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	type res struct {
+		Name string
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		out        *res
+		err        error
+	}{
+		{
+			name:       "ok",
+			statusCode: 200,
+			body:       `{"name":"a"}`,
+			out:        &res{Name: "a"},
+		},
+		{
+			name:       "empty",
+			statusCode: 200,
+			body:       "",
+			err:        ErrEmptyResponse,
+		},
+		{
+			name:       "forbidden",
+			statusCode: 403,
+			body:       `{"name":"a"}`,
+			err:        ErrInvalidKey,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(tc.statusCode, tc.body)
+
+			out, err := getJSON[res](context.Background(), "https://api.steampowered.com/a/b/?key=key", "user", client)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.err)
+			assert.ErrorContains(t, err, "a/b for user")
+		})
+	}
+}
+
+func TestPostJSON(t *testing.T) {
+	type res struct {
+		Name string
+	}
+
+	var method, contentType, body string
+	client := newTestSteamClient(&http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			b, _ := ioutil.ReadAll(req.Body)
+			method, contentType, body = req.Method, req.Header.Get("Content-Type"), string(b)
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"name":"a"}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	})
+
+	out, err := postJSON[res](context.Background(), "https://example.com/a", "", []string{"id"}, client)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &res{Name: "a"}, out)
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, `["id"]`, body)
+}
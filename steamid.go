@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const steamID64Base = 76561197960265728
+
+var (
+	ErrInvalidSteamID = errors.New("invalid steam id")
+
+	steam2Re     = regexp.MustCompile(`^STEAM_[0-5]:([01]):(\d+)$`)
+	steam3Re     = regexp.MustCompile(`^\[?U:1:(\d+)\]?$`)
+	steamID64Re  = regexp.MustCompile(`^\d{17}$`)
+	profileUrlRe = regexp.MustCompile(`steamcommunity\.com/profiles/(\d+)`)
+	vanityUrlRe  = regexp.MustCompile(`steamcommunity\.com/id/([^/\s?#]+)`)
+)
+
+type SteamID uint64
+
+func parseSteamID64(s string) (SteamID, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %s", ErrInvalidSteamID, s)
+	}
+
+	return SteamID(n), nil
+}
+
+func accountSteamID(account uint64) (SteamID, error) {
+	if account == 0 || account > 0xffffffff {
+		return 0, ErrInvalidSteamID
+	}
+
+	return SteamID(steamID64Base + account), nil
+}
+
+func parseSteamID(s string) (SteamID, error) {
+	s = strings.TrimSpace(s)
+
+	if m := profileUrlRe.FindStringSubmatch(s); m != nil {
+		s = m[1]
+	}
+
+	if m := steam2Re.FindStringSubmatch(s); m != nil {
+		y, _ := strconv.ParseUint(m[1], 10, 64)
+		z, err := strconv.ParseUint(m[2], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%w %s", ErrInvalidSteamID, s)
+		}
+
+		return accountSteamID(z*2 + y)
+	}
+
+	if m := steam3Re.FindStringSubmatch(s); m != nil {
+		account, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%w %s", ErrInvalidSteamID, s)
+		}
+
+		return accountSteamID(account)
+	}
+
+	if steamID64Re.MatchString(s) {
+		id, err := parseSteamID64(s)
+		if err != nil || !id.Valid() {
+			return 0, fmt.Errorf("%w %s", ErrInvalidSteamID, s)
+		}
+
+		return id, nil
+	}
+
+	return 0, fmt.Errorf("%w %s", ErrInvalidSteamID, s)
+}
+
+func parseSteamUser(s string) (SteamID, string) {
+	if id, err := parseSteamID(s); err == nil {
+		return id, ""
+	}
+
+	if m := vanityUrlRe.FindStringSubmatch(s); m != nil {
+		return 0, m[1]
+	}
+
+	return 0, strings.TrimSpace(s)
+}
+
+func (id SteamID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func (id SteamID) AccountID() uint32 {
+	return uint32(id)
+}
+
+func (id SteamID) Valid() bool {
+	universe := uint64(id) >> 56
+	accountType := (uint64(id) >> 52) & 0xf
+	instance := (uint64(id) >> 32) & 0xfffff
+
+	return universe == 1 && accountType == 1 && instance == 1 && id.AccountID() != 0
+}
+
+func (id SteamID) Steam2() string {
+	a := id.AccountID()
+	return fmt.Sprintf("STEAM_1:%d:%d", a&1, a>>1)
+}
+
+func (id SteamID) Steam3() string {
+	return fmt.Sprintf("[U:1:%d]", id.AccountID())
+}
+
+func (id SteamID) ProfileURL() string {
+	return communityBaseUrl + fmt.Sprintf(communityProfilePath, id)
+}
+
+func normaliseSteamUser(s string) string {
+	id, vanity := parseSteamUser(s)
+	if id != 0 {
+		return id.String()
+	}
+
+	return vanity
+}
+
+func resolveSteamID(ctx context.Context, apiKey, user string, client *steamClient) (SteamID, error) {
+	id, vanity := parseSteamUser(user)
+	if id != 0 {
+		return id, nil
+	}
+
+	return cachedSteamGetId(ctx, apiKey, vanity, client)
+}
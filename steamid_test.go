@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSteamID(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		expected SteamID
+		ok       bool
+	}{
+		{name: "SteamID64", in: "76561197960287930", expected: 76561197960287930, ok: true},
+		{name: "Steam3", in: "[U:1:22202]", expected: 76561197960287930, ok: true},
+		{name: "Steam3 without brackets", in: "U:1:22202", expected: 76561197960287930, ok: true},
+		{name: "Steam2", in: "STEAM_0:0:11101", expected: 76561197960287930, ok: true},
+		{name: "Profile url", in: "https://steamcommunity.com/profiles/76561197960287930/", expected: 76561197960287930, ok: true},
+		{name: "Not an individual account", in: "12345678901234567"},
+		{name: "Short number", in: "999"},
+		{name: "Vanity", in: "gabelogannewell"},
+		{name: "Zero account", in: "[U:1:0]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := parseSteamID(tc.in)
+
+			if !tc.ok {
+				assert.ErrorIs(t, err, ErrInvalidSteamID)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, id)
+		})
+	}
+}
+
+func TestParseSteamUser(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		id     SteamID
+		vanity string
+	}{
+		{name: "SteamID64", in: "76561197960287930", id: 76561197960287930},
+		{name: "Vanity", in: "beefslayer99", vanity: "beefslayer99"},
+		{name: "Vanity url", in: "https://steamcommunity.com/id/beefslayer99/", vanity: "beefslayer99"},
+		{name: "Numeric vanity", in: "999", vanity: "999"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, vanity := parseSteamUser(tc.in)
+
+			assert.Equal(t, tc.id, id)
+			assert.Equal(t, tc.vanity, vanity)
+		})
+	}
+}
+
+func TestSteamIDFormats(t *testing.T) {
+	id := SteamID(76561197960287930)
+
+	assert.True(t, id.Valid())
+	assert.Equal(t, "76561197960287930", id.String())
+	assert.Equal(t, uint32(22202), id.AccountID())
+	assert.Equal(t, "STEAM_1:0:11101", id.Steam2())
+	assert.Equal(t, "[U:1:22202]", id.Steam3())
+	assert.Equal(t, "https://steamcommunity.com/profiles/76561197960287930", id.ProfileURL())
+	assert.False(t, SteamID(999).Valid())
+}
+
+func TestNormaliseSteamUser(t *testing.T) {
+	assert.Equal(t, "76561197960287930", normaliseSteamUser("[U:1:22202]"))
+	assert.Equal(t, "bob", normaliseSteamUser("steamcommunity.com/id/bob"))
+	assert.Equal(t, "bob", normaliseSteamUser("bob"))
+}
+
+func TestResolveSteamID(t *testing.T) {
+	client := NewTestClient(200, `{"response":{"steamid":"76561197960287930","success":1}}`)
+
+	client.cache = newLRUCache(10, 0)
+
+	id, err := resolveSteamID(context.Background(), "key", "STEAM_0:0:11101", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, SteamID(76561197960287930), id)
+
+	id, err = resolveSteamID(context.Background(), "key", "steamcommunity.com/id/bob", client)
+	assert.Nil(t, err)
+	assert.Equal(t, SteamID(76561197960287930), id)
+}
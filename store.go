@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	appDetailsPath = "/api/appdetails?appids=%d"
+	appReviewsPath = "/appreviews/%d?json=1&language=all&purchase_type=all&num_per_page=0"
+	storeLinkRegex = `store\.steampowered\.com/app/(\d+)`
+	storeAppTTL    = time.Hour
+)
+
+var storeLinkRe = regexp.MustCompile(storeLinkRegex)
+
+type appDetails struct {
+	Name     string
+	Type     string
+	IsFree   bool `json:"is_free"`
+	Fullgame *struct {
+		AppId string
+		Name  string
+	}
+	PriceOverview *struct {
+		FinalFormatted  string `json:"final_formatted"`
+		DiscountPercent int    `json:"discount_percent"`
+	} `json:"price_overview"`
+	Packages []int
+}
+
+type appDetailsRes map[string]struct {
+	Success bool
+	Data    appDetails
+}
+
+type appReviewsRes struct {
+	QuerySummary struct {
+		ReviewScoreDesc string `json:"review_score_desc"`
+		TotalPositive   int    `json:"total_positive"`
+		TotalReviews    int    `json:"total_reviews"`
+	} `json:"query_summary"`
+}
+
+type storeApp struct {
+	appDetails
+	Reviews appReviewsRes
+}
+
+func getAppDetails(ctx context.Context, appId int, client *steamClient) (appDetails, bool, error) {
+	j, err := getJSON[appDetailsRes](ctx, client.storeUrl(appDetailsPath, appId), "", client)
+	if err != nil {
+		return appDetails{}, false, err
+	}
+
+	d, ok := (*j)[strconv.Itoa(appId)]
+	if !ok || !d.Success {
+		return appDetails{}, false, nil
+	}
+
+	return d.Data, true, nil
+}
+
+func getAppReviews(ctx context.Context, appId int, client *steamClient) (appReviewsRes, error) {
+	j, err := getJSON[appReviewsRes](ctx, client.storeUrl(appReviewsPath, appId), "", client)
+	if err != nil {
+		return appReviewsRes{}, err
+	}
+
+	return *j, nil
+}
+
+func cachedStoreApp(ctx context.Context, appId int, client *steamClient) (storeApp, bool, error) {
+	key := fmt.Sprintf("store:%d", appId)
+
+	a, err := client.cache.GetOrFetch(ctx, key, storeAppTTL, func(ctx context.Context) (interface{}, error) {
+		d, ok, err := getAppDetails(ctx, appId, client)
+		if err != nil || !ok {
+			return (*storeApp)(nil), err
+		}
+
+		app := &storeApp{appDetails: d}
+
+		if r, err := getAppReviews(ctx, appId, client); err == nil {
+			app.Reviews = r
+		}
+
+		return app, nil
+	})
+	if err != nil {
+		return storeApp{}, false, err
+	}
+
+	app := a.(*storeApp)
+	if app == nil {
+		return storeApp{}, false, nil
+	}
+
+	return *app, true, nil
+}
+
+func storeLinkIds(msg string) []int {
+	ids := []int{}
+	seen := map[int]bool{}
+
+	for _, m := range storeLinkRe.FindAllStringSubmatch(msg, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		ids = append(ids, id)
+
+		if len(ids) == maxLinkPreviews {
+			break
+		}
+	}
+
+	return ids
+}
+
+func (s settings) formatPrice(d appDetails) string {
+	switch {
+	case d.IsFree:
+		return s.msg("store_free")
+	case d.PriceOverview == nil:
+		return s.msg("store_no_price")
+	case d.PriceOverview.DiscountPercent > 0:
+		return s.msg("store_discount", d.PriceOverview.FinalFormatted, d.PriceOverview.DiscountPercent)
+	}
+
+	return d.PriceOverview.FinalFormatted
+}
+
+func (s settings) formatReviews(r appReviewsRes) string {
+	q := r.QuerySummary
+	if q.TotalReviews == 0 {
+		return s.msg("store_no_reviews")
+	}
+
+	return s.msg("store_reviews", q.ReviewScoreDesc, q.TotalPositive*100/q.TotalReviews, q.TotalReviews)
+}
+
+func (s settings) formatStoreApp(app storeApp) string {
+	return s.msg("store_app", s.formatter.Colour("green", app.Name), s.formatPrice(app.appDetails), s.formatReviews(app.Reviews))
+}
+
+func storeLinkPreviews(ctx context.Context, msg string, client *steamClient, s settings) ([]string, error) {
+	lines := []string{}
+
+	for _, id := range storeLinkIds(msg) {
+		app, ok, err := cachedStoreApp(ctx, id, client)
+		if err != nil {
+			return lines, err
+		}
+
+		if ok {
+			lines = append(lines, s.formatStoreApp(app))
+		}
+	}
+
+	return lines, nil
+}
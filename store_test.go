@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAppDetails = `{"620":{"success":true,"data":{"name":"Portal 2","is_free":false,"price_overview":{"final_formatted":"£7.19","discount_percent":0}}}}`
+	testAppReviews = `{"query_summary":{"review_score_desc":"Overwhelmingly Positive","total_positive":980,"total_reviews":1000}}`
+)
+
+func TestStoreLinkIds(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  string
+		ids  []int
+	}{
+		{
+			name: "No links",
+			msg:  "hello",
+			ids:  []int{},
+		},
+		{
+			name: "One link",
+			msg:  "check out https://store.steampowered.com/app/620/Portal_2/",
+			ids:  []int{620},
+		},
+		{
+			name: "Duplicate links",
+			msg:  "store.steampowered.com/app/620 store.steampowered.com/app/620/",
+			ids:  []int{620},
+		},
+		{
+			name: "Too many links",
+			msg:  "store.steampowered.com/app/1 store.steampowered.com/app/2 store.steampowered.com/app/3 store.steampowered.com/app/4",
+			ids:  []int{1, 2, 3},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.ids, storeLinkIds(tc.msg))
+		})
+	}
+}
+
+func TestGetAppDetails(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		out    appDetails
+		ok     bool
+		errMsg string
+	}{
+		{
+			name:   "Empty data returned",
+			body:   "",
+			errMsg: "unexpected end of JSON input",
+		},
+		{
+			name: "Unknown app",
+			body: `{"620":{"success":false}}`,
+		},
+		{
+			name: "Free app",
+			body: `{"620":{"success":true,"data":{"name":"Dota 2","is_free":true}}}`,
+			out:  appDetails{Name: "Dota 2", IsFree: true},
+			ok:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(200, tc.body)
+			d, ok, err := getAppDetails(context.Background(), 620, client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.ok, ok)
+				assert.Equal(t, tc.out, d)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestFormatStoreApp(t *testing.T) {
+	cases := []struct {
+		name     string
+		details  string
+		reviews  string
+		expected []string
+	}{
+		{
+			name:     "Full price",
+			details:  testAppDetails,
+			reviews:  testAppReviews,
+			expected: []string{"{green}Portal 2{clear} - £7.19 - Overwhelmingly Positive (98% of 1000 reviews positive)"},
+		},
+		{
+			name:     "Discounted",
+			details:  `{"620":{"success":true,"data":{"name":"Portal 2","price_overview":{"final_formatted":"£1.79","discount_percent":75}}}}`,
+			reviews:  testAppReviews,
+			expected: []string{"{green}Portal 2{clear} - £1.79 (-75%) - Overwhelmingly Positive (98% of 1000 reviews positive)"},
+		},
+		{
+			name:     "Free with no reviews",
+			details:  `{"620":{"success":true,"data":{"name":"Portal 2","is_free":true}}}`,
+			reviews:  `{"query_summary":{"total_reviews":0}}`,
+			expected: []string{"{green}Portal 2{clear} - free to play - no reviews"},
+		},
+		{
+			name:     "Unknown app",
+			details:  `{"620":{"success":false}}`,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewConditionalTestClient(map[string]string{
+				testClient.storeUrl(appDetailsPath, 620): tc.details,
+				testClient.storeUrl(appReviewsPath, 620): tc.reviews,
+			})
+
+			out, err := storeLinkPreviews(context.Background(), "https://store.steampowered.com/app/620/", client, testSettings)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
+
+func TestCachedStoreApp(t *testing.T) {
+	calls := 0
+	client := NewConditionalTestClient(map[string]string{
+		testClient.storeUrl(appDetailsPath, 620): testAppDetails,
+		testClient.storeUrl(appReviewsPath, 620): testAppReviews,
+	})
+	client.httpClient.Transport = countingTransport(client.httpClient.Transport, &calls)
+
+	client.cache = newLRUCache(10, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		app, ok, err := cachedStoreApp(context.Background(), 620, client)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "Portal 2", app.Name)
+	}
+
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	playerSummariesPath = "/ISteamUser/GetPlayerSummaries/v2/?key=%s&steamids=%s"
+	summaryCacheTTL     = time.Minute
+)
+
+type playerSummariesRes struct {
+	Response struct {
+		Players []playerSummary
+	}
+}
+
+type playerSummary struct {
+	SteamId       string
+	PersonaName   string
+	ProfileUrl    string
+	PersonaState  int
+	GameId        string
+	GameExtraInfo string
+}
+
+func getPlayerSummaries(ctx context.Context, apiKey, ids string, client *steamClient) (*playerSummariesRes, error) {
+	return getJSON[playerSummariesRes](ctx, client.apiUrl(playerSummariesPath, apiKey, ids), ids, client)
+}
+
+func summaryCacheKey(id string) string {
+	return fmt.Sprintf("summary:%s", id)
+}
+
+func cachedPlayerSummary(ctx context.Context, apiKey string, id SteamID, client *steamClient) (playerSummary, error) {
+	key := summaryCacheKey(id.String())
+
+	ps, err := client.cache.GetOrFetch(ctx, key, summaryCacheTTL, func(ctx context.Context) (interface{}, error) {
+		return playerSummaries.Get(ctx, apiKey, id.String(), client)
+	})
+	if err != nil {
+		return playerSummary{}, err
+	}
+
+	return ps.(playerSummary), nil
+}
+
+func (s settings) displayName(ctx context.Context, apiKey string, id SteamID, user string, client *steamClient) string {
+	if !s.personaNames {
+		return user
+	}
+
+	ps, err := cachedPlayerSummary(ctx, apiKey, id, client)
+	if err != nil {
+		return user
+	}
+
+	persona := ps.PersonaName
+	if persona == "" || persona == user {
+		return user
+	}
+
+	return fmt.Sprintf("%s (%s)", persona, user)
+}
+
+func nowPlaying(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	id, err := resolveSteamID(ctx, apiKey, user, client)
+	if err != nil {
+		return "", err
+	}
+
+	ps, err := cachedPlayerSummary(ctx, apiKey, id, client)
+	if err != nil {
+		return "", err
+	}
+
+	name := s.displayName(ctx, apiKey, id, user, client)
+	if ps.GameExtraInfo == "" {
+		return s.msg("not_playing", name), nil
+	}
+
+	return s.msg("now_playing", name, s.formatter.Colour("green", ps.GameExtraInfo)), nil
+}
+
+func nowPlayingHandler(ctx context.Context, apiKey, user string, client *steamClient, s settings) (string, error) {
+	out, err := nowPlaying(ctx, apiKey, user, client, s)
+	if errors.Is(err, ErrProfileNotFound) {
+		return s.msg("no_id", user), nil
+	}
+
+	return out, err
+}
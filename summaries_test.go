@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPlayerSummaries(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		players int
+		errMsg  string
+	}{
+		{
+			name:   "Empty data returned",
+			body:   "",
+			errMsg: "unexpected end of JSON input",
+		},
+		{
+			name:    "No players",
+			body:    `{"response":{"players":[]}}`,
+			players: 0,
+		},
+		{
+			name:    "One player",
+			body:    `{"response":{"players":[{"steamid":"999","personaname":"Bob"}]}}`,
+			players: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewTestClient(200, tc.body)
+
+			ps, err := getPlayerSummaries(context.Background(), "key", "999", client)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.players, len(ps.Response.Players))
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestSettingsDisplayName(t *testing.T) {
+	cases := []struct {
+		name         string
+		personaNames bool
+		body         string
+		out          string
+	}{
+		{
+			name:         "Persona names disabled",
+			personaNames: false,
+			body:         `{"response":{"players":[{"steamid":"999","personaname":"Bob"}]}}`,
+			out:          "beefslayer99",
+		},
+		{
+			name:         "Persona name found",
+			personaNames: true,
+			body:         `{"response":{"players":[{"steamid":"999","personaname":"Bob"}]}}`,
+			out:          "Bob (beefslayer99)",
+		},
+		{
+			name:         "Persona name matches user",
+			personaNames: true,
+			body:         `{"response":{"players":[{"steamid":"999","personaname":"beefslayer99"}]}}`,
+			out:          "beefslayer99",
+		},
+		{
+			name:         "Lookup fails",
+			personaNames: true,
+			body:         "",
+			out:          "beefslayer99",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.personaNames = tc.personaNames
+			client := NewTestClient(200, tc.body)
+
+			assert.Equal(t, tc.out, s.displayName(context.Background(), "key", 999, "beefslayer99", client))
+		})
+	}
+}
+
+func TestNowPlayingHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		user  string
+		fault mocksteam.Fault
+		out   string
+		err   error
+	}{
+		{
+			name: "Playing a game",
+			user: "gaben",
+			out:  "gaben is playing {green}Portal 2{clear}",
+		},
+		{
+			name: "Not playing",
+			user: "76561197960287931",
+			out:  "76561197960287931 isn't playing anything right now",
+		},
+		{
+			name: "Unknown user",
+			user: "nobody",
+			out:  "Error: no id found for nobody",
+		},
+		{
+			name:  "Rate limited",
+			user:  "gaben",
+			fault: mocksteam.RateLimited,
+			err:   ErrRateLimited,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, client := useMockSteam(t)
+			ms.Inject("summaries", tc.fault)
+
+			out, err := nowPlayingHandler(context.Background(), "key", tc.user, client, testSettings)
+
+			assert.Equal(t, tc.out, out)
+
+			if tc.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
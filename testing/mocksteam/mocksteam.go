@@ -0,0 +1,243 @@
+package mocksteam
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+type Fault string
+
+const (
+	NoFault     Fault = ""
+	RateLimited Fault = "ratelimit"
+	Truncated   Fault = "truncated"
+	Private     Fault = "private"
+	ServerError Fault = "error"
+	Empty       Fault = "empty"
+)
+
+const AllEndpoints = "*"
+
+type endpoint struct {
+	name string
+	key  string
+}
+
+var endpoints = map[string]endpoint{
+	"/ISteamUser/ResolveVanityURL/v1/":                              {name: "vanity", key: "vanityurl"},
+	"/IPlayerService/GetRecentlyPlayedGames/v1/":                    {name: "recent"},
+	"/IPlayerService/GetOwnedGames/v1/":                             {name: "owned", key: "steamid"},
+	"/ISteamUserStats/GetPlayerAchievements/v0001/":                 {name: "achievements", key: "appid"},
+	"/ISteamUser/GetFriendList/v1/":                                 {name: "friends", key: "steamid"},
+	"/ISteamUser/GetPlayerSummaries/v2/":                            {name: "summaries", key: "steamids"},
+	"/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v0002/": {name: "percentages", key: "gameid"},
+	"/ISteamUserStats/GetSchemaForGame/v2/":                         {name: "schema", key: "appid"},
+	"/ISteamUserStats/GetNumberOfCurrentPlayers/v1/":                {name: "players", key: "appid"},
+	"/IPlayerService/GetSteamLevel/v1/":                             {name: "level"},
+	"/ISteamUser/GetPlayerBans/v1/":                                 {name: "bans"},
+	"/IStoreService/GetAppList/v1/":                                 {name: "applist"},
+	"/IPublishedFileService/GetDetails/v1/":                         {name: "workshop"},
+	"/api/appdetails":                                               {name: "appdetails", key: "appids"},
+	"/api/packagedetails":                                           {name: "packages", key: "packageids"},
+	"/actions/ajaxresolvebundles":                                   {name: "bundles", key: "bundleids"},
+	"/api.php":                                                      {name: "spy", key: "appid"},
+	"/games/lookup/v1":                                              {name: "itadlookup", key: "appid"},
+	"/games/overview/v2":                                            {name: "itadoverview"},
+}
+
+const (
+	reviewsPrefix = "/appreviews/"
+	curatorPrefix = "/curator/"
+	curatorSuffix = "/ajaxgetfilteredrecommendations/"
+)
+
+var faults = map[Fault]bool{
+	RateLimited: true,
+	Truncated:   true,
+	Private:     true,
+	ServerError: true,
+	Empty:       true,
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	faults map[string]Fault
+}
+
+func newServer() *Server {
+	return &Server{faults: make(map[string]Fault)}
+}
+
+func New() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+func Listen(addr string) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newServer()
+	s.Server = httptest.NewUnstartedServer(s)
+	s.Server.Listener.Close()
+	s.Server.Listener = l
+	s.Server.Start()
+
+	return s, nil
+}
+
+func ParseFault(in string) (string, Fault, error) {
+	name, f, ok := strings.Cut(in, "=")
+	if !ok || name == "" {
+		return "", NoFault, fmt.Errorf("fault %q should look like endpoint=fault", in)
+	}
+
+	if !faults[Fault(f)] {
+		return "", NoFault, fmt.Errorf("unknown fault %q", f)
+	}
+
+	return name, Fault(f), nil
+}
+
+func (s *Server) Inject(name string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f == NoFault {
+		delete(s.faults, name)
+		return
+	}
+
+	s.faults[name] = f
+}
+
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = make(map[string]Fault)
+}
+
+func (s *Server) fault(name string) Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f, ok := s.faults[name]; ok {
+		return f
+	}
+
+	return s.faults[AllEndpoints]
+}
+
+func route(r *http.Request) (name, key string, ok bool) {
+	if strings.HasPrefix(r.URL.Path, reviewsPrefix) {
+		return "reviews", strings.TrimPrefix(r.URL.Path, reviewsPrefix), true
+	}
+
+	if strings.HasPrefix(r.URL.Path, curatorPrefix) && strings.HasSuffix(r.URL.Path, curatorSuffix) {
+		return "curator", strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, curatorPrefix), curatorSuffix), true
+	}
+
+	e, ok := endpoints[r.URL.Path]
+	if !ok {
+		return "", "", false
+	}
+
+	if e.key == "" {
+		return e.name, "", true
+	}
+
+	return e.name, strings.ToLower(r.URL.Query().Get(e.key)), true
+}
+
+func fixture(name, key string) ([]byte, error) {
+	if key != "" {
+		if b, err := fixtures.ReadFile(fmt.Sprintf("fixtures/%s_%s.json", name, key)); err == nil {
+			return b, nil
+		}
+	}
+
+	return fixtures.ReadFile(fmt.Sprintf("fixtures/%s.json", name))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, key, ok := route(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch s.fault(name) {
+	case RateLimited:
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	case ServerError:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	case Empty:
+		return
+	case Private:
+		writePrivate(w, name)
+		return
+	}
+
+	b, err := fixture(name, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if s.fault(name) == Truncated {
+		b = b[:len(b)/2]
+	}
+
+	w.WriteHeader(fixtureStatus(name, b))
+	w.Write(b)
+}
+
+func fixtureStatus(name string, b []byte) int {
+	if name != "achievements" {
+		return http.StatusOK
+	}
+
+	var res struct {
+		PlayerStats struct {
+			Error string
+		}
+	}
+
+	if json.Unmarshal(b, &res) == nil && res.PlayerStats.Error != "" {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusOK
+}
+
+func writePrivate(w http.ResponseWriter, name string) {
+	switch name {
+	case "achievements":
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"playerstats":{"error":"Profile is not public","success":false}}`)
+	case "recent", "owned":
+		fmt.Fprint(w, `{"response":{}}`)
+	default:
+		w.WriteHeader(http.StatusForbidden)
+	}
+}
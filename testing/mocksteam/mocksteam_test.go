@@ -0,0 +1,233 @@
+package mocksteam
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	res, err := http.Get(url)
+	assert.Nil(t, err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.Nil(t, err)
+
+	return res.StatusCode, string(b)
+}
+
+func TestServerFixtures(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		status   int
+		contains string
+	}{
+		{
+			name:     "Known vanity",
+			path:     "/ISteamUser/ResolveVanityURL/v1/?key=k&vanityurl=GabeN",
+			status:   200,
+			contains: `"success":1`,
+		},
+		{
+			name:     "Unknown vanity",
+			path:     "/ISteamUser/ResolveVanityURL/v1/?key=k&vanityurl=nobody",
+			status:   200,
+			contains: `"success":42`,
+		},
+		{
+			name:     "Achievements for a known app",
+			path:     "/ISteamUserStats/GetPlayerAchievements/v0001/?key=k&steamid=1&appid=620&format=json&l=en",
+			status:   200,
+			contains: "ACH_WAKE_UP",
+		},
+		{
+			name:     "Achievements for an unknown app",
+			path:     "/ISteamUserStats/GetPlayerAchievements/v0001/?key=k&steamid=1&appid=1&format=json&l=en",
+			status:   400,
+			contains: "no stats",
+		},
+		{
+			name:     "Reviews",
+			path:     "/appreviews/620?json=1",
+			status:   200,
+			contains: "Overwhelmingly Positive",
+		},
+		{
+			name:     "Curator recommendations",
+			path:     "/curator/33075774/ajaxgetfilteredrecommendations/?query=&start=0&count=50&sort=recent",
+			status:   200,
+			contains: "co-op puzzler",
+		},
+		{
+			name:   "Unknown endpoint",
+			path:   "/ISteamNews/GetNewsForApp/v2/",
+			status: 404,
+		},
+	}
+
+	s := New()
+	defer s.Close()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := get(t, s.URL+tc.path)
+
+			assert.Equal(t, tc.status, status)
+			assert.Contains(t, body, tc.contains)
+		})
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	recent := "/IPlayerService/GetRecentlyPlayedGames/v1/?key=k&steamid=1&count=0"
+	achievements := "/ISteamUserStats/GetPlayerAchievements/v0001/?key=k&steamid=1&appid=620&format=json&l=en"
+
+	cases := []struct {
+		name     string
+		endpoint string
+		fault    Fault
+		path     string
+		status   int
+		body     string
+	}{
+		{
+			name:     "Rate limited",
+			endpoint: "recent",
+			fault:    RateLimited,
+			path:     recent,
+			status:   429,
+		},
+		{
+			name:     "Server error everywhere",
+			endpoint: AllEndpoints,
+			fault:    ServerError,
+			path:     recent,
+			status:   500,
+		},
+		{
+			name:     "Empty",
+			endpoint: "recent",
+			fault:    Empty,
+			path:     recent,
+			status:   200,
+		},
+		{
+			name:     "Private achievements",
+			endpoint: "achievements",
+			fault:    Private,
+			path:     achievements,
+			status:   403,
+			body:     `{"playerstats":{"error":"Profile is not public","success":false}}`,
+		},
+		{
+			name:     "Private recent games",
+			endpoint: "recent",
+			fault:    Private,
+			path:     recent,
+			status:   200,
+			body:     `{"response":{}}`,
+		},
+		{
+			name:     "Fault on another endpoint",
+			endpoint: "achievements",
+			fault:    RateLimited,
+			path:     "/IPlayerService/GetSteamLevel/v1/?key=k&steamid=1",
+			status:   200,
+			body:     `{"response":{"player_level":42}}` + "\n",
+		},
+	}
+
+	s := New()
+	defer s.Close()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s.Reset()
+			s.Inject(tc.endpoint, tc.fault)
+
+			status, body := get(t, s.URL+tc.path)
+
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.body, body)
+		})
+	}
+}
+
+func TestServerTruncated(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	path := "/IPlayerService/GetSteamLevel/v1/?key=k&steamid=1"
+	_, full := get(t, s.URL+path)
+
+	s.Inject("level", Truncated)
+	_, body := get(t, s.URL+path)
+
+	assert.Equal(t, full[:len(full)/2], body)
+
+	s.Inject("level", NoFault)
+	_, body = get(t, s.URL+path)
+
+	assert.Equal(t, full, body)
+}
+
+func TestListen(t *testing.T) {
+	s, err := Listen("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer s.Close()
+
+	status, _ := get(t, s.URL+"/IPlayerService/GetSteamLevel/v1/")
+	assert.Equal(t, 200, status)
+}
+
+func TestParseFault(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		endpoint string
+		fault    Fault
+		errMsg   string
+	}{
+		{
+			name:     "Valid",
+			in:       "achievements=private",
+			endpoint: "achievements",
+			fault:    Private,
+		},
+		{
+			name:     "All endpoints",
+			in:       "*=ratelimit",
+			endpoint: "*",
+			fault:    RateLimited,
+		},
+		{
+			name:   "Missing fault",
+			in:     "achievements",
+			errMsg: "should look like endpoint=fault",
+		},
+		{
+			name:   "Unknown fault",
+			in:     "achievements=slow",
+			errMsg: `unknown fault "slow"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, fault, err := ParseFault(tc.in)
+
+			assert.Equal(t, tc.endpoint, endpoint)
+			assert.Equal(t, tc.fault, fault)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (ut *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return ut.next.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set("User-Agent", ut.userAgent)
+
+	return ut.next.RoundTrip(r)
+}
+
+func redactUrl(u *url.URL) string {
+	q := u.Query()
+	if q.Get("key") == "" {
+		return u.String()
+	}
+
+	q.Set("key", "REDACTED")
+
+	r := *u
+	r.RawQuery = q.Encode()
+
+	return r.String()
+}
+
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (dt *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := wallClock.Now()
+	res, err := dt.next.RoundTrip(req)
+	took := wallClock.Now().Sub(start).Round(time.Millisecond)
+
+	if err != nil {
+		log.Printf("http %s %s failed after %s: %s\n", req.Method, redactUrl(req.URL), took, redactError(err, req.URL))
+		return res, err
+	}
+
+	log.Printf("http %s %s %d %s\n", req.Method, redactUrl(req.URL), res.StatusCode, took)
+
+	return res, err
+}
+
+func redactError(err error, u *url.URL) string {
+	key := u.Query().Get("key")
+	if key == "" {
+		return err.Error()
+	}
+
+	return strings.ReplaceAll(err.Error(), key, "REDACTED")
+}
+
+var responseTooLargeErr = errors.New("response too large")
+
+type limitedBody struct {
+	io.ReadCloser
+	r    io.Reader
+	read int64
+	max  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+
+	if b.read > b.max {
+		return n, responseTooLargeErr
+	}
+
+	return n, err
+}
+
+type limitTransport struct {
+	next    http.RoundTripper
+	maxBody int64
+}
+
+func (lt *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := lt.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	res.Body = &limitedBody{
+		ReadCloser: res.Body,
+		r:          io.LimitReader(res.Body, lt.maxBody+1),
+		max:        lt.maxBody,
+	}
+
+	return res, nil
+}
+
+type retryBudgetKey struct{}
+
+type retryBudget struct {
+	remaining int32
+}
+
+func withRetryBudget(ctx context.Context, retries int) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: int32(retries)})
+}
+
+func takeRetry(ctx context.Context) bool {
+	b, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return true
+	}
+
+	return atomic.AddInt32(&b.remaining, -1) >= 0
+}
+
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+func isTransient(res *http.Response, err error) bool {
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return true
+		}
+
+		_, ok := err.(*net.OpError)
+		return ok
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+func (rt *retryTransport) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			if d := time.Duration(s) * time.Second; d <= rt.maxDelay {
+				return d
+			}
+		}
+	}
+
+	d := rt.baseDelay << uint(attempt)
+	if d <= 0 || d > rt.maxDelay {
+		d = rt.maxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		res, err := rt.next.RoundTrip(req)
+
+		if req.Method != http.MethodGet || attempt+1 >= rt.maxAttempts || !isTransient(res, err) || !takeRetry(ctx) {
+			return res, err
+		}
+
+		wait := rt.backoff(attempt, res)
+
+		if res != nil {
+			res.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func newBaseTransport(opts Options) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   opts.TLSTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		DisableCompression:    opts.NoCompression,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+func newHTTPClient(opts Options, limiter *tokenBucket) *http.Client {
+	var transport http.RoundTripper = newBaseTransport(opts)
+	host := urlHost(opts.APIURL)
+
+	if opts.DebugHTTP {
+		transport = &debugTransport{next: transport}
+	}
+
+	if opts.MaxBodySize > 0 {
+		transport = &limitTransport{next: transport, maxBody: opts.MaxBodySize}
+	}
+
+	rlt := &rateLimitTransport{
+		next:   transport,
+		hosts:  map[string]bool{host: true},
+		bucket: limiter,
+	}
+
+	keys := parseAPIKeys(opts.APIKey)
+
+	rlt.budget = &dailyBudget{name: "steam api budget", limit: opts.DailyBudget * len(keys)}
+	apiBudget = rlt.budget
+
+	transport = rlt
+
+	apiKeyRing = nil
+
+	if len(keys) > 1 {
+		apiKeyRing = newKeyRing(keys, opts.DailyBudget, opts.KeyCooldown)
+		transport = &keyTransport{
+			next:  transport,
+			hosts: map[string]bool{host: true},
+			ring:  apiKeyRing,
+		}
+	}
+
+	transport = &retryTransport{
+		next:        transport,
+		maxAttempts: opts.RetryAttempts,
+		baseDelay:   opts.RetryDelay,
+		maxDelay:    opts.RetryMaxDelay,
+	}
+
+	if opts.BreakerThreshold > 0 {
+		transport = &breakerTransport{
+			next:    transport,
+			hosts:   map[string]bool{host: true},
+			breaker: &circuitBreaker{threshold: opts.BreakerThreshold, cooldown: opts.BreakerCooldown},
+		}
+	}
+
+	transport = &dedupeTransport{next: transport, timeout: opts.Timeout}
+
+	transport = &userAgentTransport{
+		next:      transport,
+		userAgent: userAgent(),
+	}
+
+	return &http.Client{
+		Transport: transport,
+	}
+}
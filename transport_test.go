@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sequenceTransport struct {
+	statuses []int
+	calls    int
+}
+
+func (st *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := st.statuses[len(st.statuses)-1]
+	if st.calls < len(st.statuses) {
+		status = st.statuses[st.calls]
+	}
+	st.calls++
+
+	if status == 0 {
+		return nil, errors.New("connection reset")
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestNewBaseTransport(t *testing.T) {
+	opts := Options{
+		TLSTimeout:            5 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+		MaxIdleConns:          20,
+		MaxIdleConnsPerHost:   4,
+		MaxConnsPerHost:       8,
+		IdleConnTimeout:       time.Minute,
+	}
+
+	tr := newBaseTransport(opts)
+
+	assert.Equal(t, 5*time.Second, tr.TLSHandshakeTimeout)
+	assert.Equal(t, 15*time.Second, tr.ResponseHeaderTimeout)
+	assert.Equal(t, 20, tr.MaxIdleConns)
+	assert.Equal(t, 4, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 8, tr.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, tr.IdleConnTimeout)
+	assert.NotNil(t, tr.DialContext)
+}
+
+func TestNewHTTPClientCompression(t *testing.T) {
+	cases := []struct {
+		name           string
+		noCompression  bool
+		acceptEncoding string
+	}{
+		{
+			name:           "Compression enabled",
+			noCompression:  false,
+			acceptEncoding: "gzip",
+		},
+		{
+			name:           "Compression disabled",
+			noCompression:  true,
+			acceptEncoding: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			acceptEncoding := ""
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+
+				if acceptEncoding != "gzip" {
+					w.Write([]byte("body"))
+					return
+				}
+
+				w.Header().Set("Content-Encoding", "gzip")
+				gz := gzip.NewWriter(w)
+				gz.Write([]byte("body"))
+				gz.Close()
+			}))
+			defer ts.Close()
+
+			client := newHTTPClient(Options{RetryAttempts: 1, NoCompression: tc.noCompression}, nil)
+
+			res, err := client.Get(ts.URL)
+			assert.Nil(t, err)
+			defer res.Body.Close()
+
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tc.acceptEncoding, acceptEncoding)
+			assert.Equal(t, "body", string(body))
+		})
+	}
+}
+
+func TestUserAgentTransport(t *testing.T) {
+	cases := []struct {
+		name      string
+		userAgent string
+		out       string
+	}{
+		{
+			name:      "Unset",
+			userAgent: "",
+			out:       "gowon-steam/v1.0.0",
+		},
+		{
+			name:      "Already set",
+			userAgent: "other/1.0",
+			out:       "other/1.0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := ""
+			f := func(req *http.Request) *http.Response {
+				out = req.Header.Get("User-Agent")
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+					Header:     make(http.Header),
+				}
+			}
+			ut := &userAgentTransport{next: RoundTripFunc(f), userAgent: "gowon-steam/v1.0.0"}
+
+			req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/", nil)
+			if tc.userAgent != "" {
+				req.Header.Set("User-Agent", tc.userAgent)
+			}
+
+			_, err := ut.RoundTrip(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestRedactUrl(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "No key",
+			in:   "https://store.steampowered.com/app/1",
+			out:  "https://store.steampowered.com/app/1",
+		},
+		{
+			name: "Key",
+			in:   "https://api.steampowered.com/ISteamUser/ResolveVanityURL/v1/?key=secret&vanityurl=bob",
+			out:  "https://api.steampowered.com/ISteamUser/ResolveVanityURL/v1/?key=REDACTED&vanityurl=bob",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.in)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tc.out, redactUrl(u))
+		})
+	}
+}
+
+func TestDebugTransport(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	dt := &debugTransport{next: &sequenceTransport{statuses: []int{200, 0}}}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/?key=secret", nil)
+
+	_, err := dt.RoundTrip(req)
+	assert.Nil(t, err)
+
+	_, err = dt.RoundTrip(req)
+	assert.NotNil(t, err)
+
+	assert.Contains(t, buf.String(), "http GET https://api.steampowered.com/?key=REDACTED 200")
+	assert.Contains(t, buf.String(), "failed after")
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestLimitTransport(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		maxBody int64
+		err     error
+	}{
+		{
+			name:    "Under limit",
+			body:    "1234",
+			maxBody: 5,
+			err:     nil,
+		},
+		{
+			name:    "At limit",
+			body:    "12345",
+			maxBody: 5,
+			err:     nil,
+		},
+		{
+			name:    "Over limit",
+			body:    "123456",
+			maxBody: 5,
+			err:     responseTooLargeErr,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lt := &limitTransport{next: NewTestClient(200, tc.body).httpClient.Transport, maxBody: tc.maxBody}
+
+			req, _ := http.NewRequest(http.MethodGet, "https://api.steampowered.com/", nil)
+
+			res, err := lt.RoundTrip(req)
+			assert.Nil(t, err)
+
+			_, err = ioutil.ReadAll(res.Body)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestLimitTransportDecode(t *testing.T) {
+	lt := &limitTransport{next: NewTestClient(200, `{"response":{"games":[]}}`).httpClient.Transport, maxBody: 5}
+	client := newTestSteamClient(&http.Client{Transport: lt})
+
+	_, err := getRecentlyPlayed(context.Background(), "key", 999, 0, client)
+	assert.ErrorIs(t, err, responseTooLargeErr)
+
+	_, err = getAchievements(context.Background(), "key", 999, 1, "en", client)
+	assert.ErrorIs(t, err, responseTooLargeErr)
+}
+
+func TestRetryTransport(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []int
+		method   string
+		status   int
+		calls    int
+	}{
+		{
+			name:     "Success first time",
+			statuses: []int{200},
+			method:   http.MethodGet,
+			status:   200,
+			calls:    1,
+		},
+		{
+			name:     "Not found is not retried",
+			statuses: []int{404},
+			method:   http.MethodGet,
+			status:   404,
+			calls:    1,
+		},
+		{
+			name:     "Server error then success",
+			statuses: []int{500, 503, 200},
+			method:   http.MethodGet,
+			status:   200,
+			calls:    3,
+		},
+		{
+			name:     "Rate limited until attempts run out",
+			statuses: []int{429},
+			method:   http.MethodGet,
+			status:   429,
+			calls:    3,
+		},
+		{
+			name:     "Post is not retried",
+			statuses: []int{500, 200},
+			method:   http.MethodPost,
+			status:   500,
+			calls:    1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := &sequenceTransport{statuses: tc.statuses}
+			rt := &retryTransport{next: st, maxAttempts: 3, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+			req, _ := http.NewRequest(tc.method, "https://example.com", nil)
+			res, err := rt.RoundTrip(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, tc.calls, st.calls)
+		})
+	}
+}
+
+func TestRetryTransportBudget(t *testing.T) {
+	st := &sequenceTransport{statuses: []int{500}}
+	rt := &retryTransport{next: st, maxAttempts: 5, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+	ctx := withRetryBudget(context.Background(), 3)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+		_, _ = rt.RoundTrip(req)
+	}
+
+	assert.Equal(t, 5, st.calls)
+}
+
+func TestRetryTransportNonTransientError(t *testing.T) {
+	st := &sequenceTransport{statuses: []int{0}}
+	rt := &retryTransport{next: st, maxAttempts: 3, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	_, err := rt.RoundTrip(req)
+
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, 1, st.calls)
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	rt := &retryTransport{baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		d := rt.backoff(attempt, nil)
+		assert.True(t, d >= 0 && d <= time.Second)
+	}
+
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+	assert.Equal(t, time.Second, rt.backoff(0, res))
+}
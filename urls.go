@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	apiBaseUrl       = "https://api.steampowered.com"
+	storeBaseUrl     = "https://store.steampowered.com"
+	communityBaseUrl = "https://steamcommunity.com"
+	steamSpyBaseUrl  = "https://steamspy.com"
+	itadBaseUrl      = "https://api.isthereanydeal.com"
+)
+
+func parseBaseUrl(name, in string) (string, error) {
+	u, err := url.Parse(in)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid %s url %s", name, in)
+	}
+
+	return strings.TrimSuffix(in, "/"), nil
+}
+
+func urlHost(in string) string {
+	u, err := url.Parse(in)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
+
+func (c *steamClient) apiUrl(path string, args ...interface{}) string {
+	return c.baseUrls["api"] + fmt.Sprintf(path, args...)
+}
+
+func (c *steamClient) storeUrl(path string, args ...interface{}) string {
+	return c.baseUrls["store"] + fmt.Sprintf(path, args...)
+}
+
+func (c *steamClient) communityUrl(path string, args ...interface{}) string {
+	return c.baseUrls["community"] + fmt.Sprintf(path, args...)
+}
+
+func (c *steamClient) steamSpyUrl(path string, args ...interface{}) string {
+	return c.baseUrls["steamspy"] + fmt.Sprintf(path, args...)
+}
+
+func (c *steamClient) itadUrl(path string, args ...interface{}) string {
+	return c.baseUrls["itad"] + fmt.Sprintf(path, args...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBaseUrl(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		out    string
+		errMsg string
+	}{
+		{
+			name: "Default",
+			in:   "https://api.steampowered.com",
+			out:  "https://api.steampowered.com",
+		},
+		{
+			name: "Trailing slash",
+			in:   "http://localhost:8080/steam/",
+			out:  "http://localhost:8080/steam",
+		},
+		{
+			name:   "No scheme",
+			in:     "api.steampowered.com",
+			errMsg: "invalid api url api.steampowered.com",
+		},
+		{
+			name:   "Unsupported scheme",
+			in:     "ftp://api.steampowered.com",
+			errMsg: "invalid api url ftp://api.steampowered.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := parseBaseUrl("api", tc.in)
+
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tc.errMsg)
+			}
+
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const topUsageEntries = 5
+
+var usageBucket = []byte("usage")
+
+type nickUsage struct {
+	Nick  string `json:"nick"`
+	Total int    `json:"total"`
+	Day   string `json:"day"`
+	Today int    `json:"today"`
+}
+
+func usageDay(now time.Time) string {
+	return now.UTC().Format("2006-01-02")
+}
+
+func recordUsage(kv Store, nick string, now time.Time) (nickUsage, error) {
+	u := nickUsage{Nick: nick}
+	day := usageDay(now)
+
+	err := kv.Update(usageBucket, func(b Bucket) error {
+		k := []byte(strings.ToLower(nick))
+		if v := b.Get(k); v != nil {
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			}
+		}
+
+		if u.Day != day {
+			u.Day = day
+			u.Today = 0
+		}
+		u.Nick = nick
+		u.Total++
+		u.Today++
+
+		v, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(k, v)
+	})
+
+	return u, err
+}
+
+func allUsage(kv Store, now time.Time) ([]nickUsage, error) {
+	out := []nickUsage{}
+	day := usageDay(now)
+
+	err := kv.View(usageBucket, func(b Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			u := nickUsage{}
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			}
+
+			if u.Day != day {
+				u.Today = 0
+			}
+
+			out = append(out, u)
+			return nil
+		})
+	})
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Total > out[j].Total
+	})
+
+	return out, err
+}
+
+func (s settings) overDailyLimit(u nickUsage) bool {
+	return s.userDailyLimit > 0 && u.Today > s.userDailyLimit && !s.isAdmin(u.Nick)
+}
+
+func usageHandler(kv Store, s settings, nick string, now time.Time) (string, error) {
+	usage, err := allUsage(kv, now)
+	if err != nil {
+		return "", err
+	}
+
+	if nick != "" {
+		for _, u := range usage {
+			if strings.EqualFold(u.Nick, nick) {
+				return s.msg("usage_nick", u.Nick, u.Total, u.Today), nil
+			}
+		}
+
+		return s.msg("usage_none", nick), nil
+	}
+
+	if len(usage) == 0 {
+		return s.msg("usage_empty"), nil
+	}
+
+	if len(usage) > topUsageEntries {
+		usage = usage[:topUsageEntries]
+	}
+
+	out := []string{}
+	for _, u := range usage {
+		out = append(out, fmt.Sprintf("%s (%d)", u.Nick, u.Total))
+	}
+
+	return s.msg("usage_top", strings.Join(out, ", ")), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordUsage(t *testing.T) {
+	kv := openTestDB(t)
+	day := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	u, err := recordUsage(kv, "Nick1", day)
+	assert.Nil(t, err)
+	assert.Equal(t, nickUsage{Nick: "Nick1", Total: 1, Day: "2022-01-01", Today: 1}, u)
+
+	u, err = recordUsage(kv, "nick1", day)
+	assert.Nil(t, err)
+	assert.Equal(t, nickUsage{Nick: "nick1", Total: 2, Day: "2022-01-01", Today: 2}, u)
+
+	u, err = recordUsage(kv, "nick1", day.Add(24*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, nickUsage{Nick: "nick1", Total: 3, Day: "2022-01-02", Today: 1}, u)
+}
+
+func TestOverDailyLimit(t *testing.T) {
+	cases := []struct {
+		name     string
+		limit    int
+		usage    nickUsage
+		expected bool
+	}{
+		{name: "Disabled", usage: nickUsage{Nick: "nick1", Today: 100}},
+		{name: "Under", limit: 5, usage: nickUsage{Nick: "nick1", Today: 5}},
+		{name: "Over", limit: 5, usage: nickUsage{Nick: "nick1", Today: 6}, expected: true},
+		{name: "Admin", limit: 5, usage: nickUsage{Nick: "boss", Today: 6}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testSettings
+			s.userDailyLimit = tc.limit
+			s.admins = adminSet([]string{"Boss"})
+
+			assert.Equal(t, tc.expected, s.overDailyLimit(tc.usage))
+		})
+	}
+}
+
+func TestUsageHandler(t *testing.T) {
+	day := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		nicks    []string
+		nick     string
+		expected string
+	}{
+		{
+			name:     "Empty",
+			expected: "no commands recorded yet",
+		},
+		{
+			name:     "Top users",
+			nicks:    []string{"a", "b", "b", "c", "c", "c", "d", "e", "f"},
+			expected: "top users: c (3), b (2), a (1), d (1), e (1)",
+		},
+		{
+			name:     "Nick",
+			nicks:    []string{"a", "B", "b"},
+			nick:     "b",
+			expected: "b has run 2 commands, 2 today",
+		},
+		{
+			name:     "Unknown nick",
+			nicks:    []string{"a"},
+			nick:     "z",
+			expected: "z hasn't run any commands",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kv := openTestDB(t)
+			for _, n := range tc.nicks {
+				_, err := recordUsage(kv, n, day)
+				assert.Nil(t, err)
+			}
+
+			out, err := usageHandler(kv, testSettings, tc.nick, day)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowon-irc/gowon-steam/testing/mocksteam"
+)
+
+const maxClientIDLength = 64
+
+var apiKeyFormat = regexp.MustCompile(`^[0-9A-Fa-f]{32}$`)
+
+type configProblems []string
+
+func (cp *configProblems) add(format string, a ...interface{}) {
+	*cp = append(*cp, fmt.Sprintf(format, a...))
+}
+
+func (cp *configProblems) check(err error) {
+	if err != nil {
+		cp.add("%s", err)
+	}
+}
+
+func checkHostPort(name, addr string, needHost bool) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid %s address %s, must be host:port", name, addr)
+	}
+
+	if needHost && host == "" {
+		return fmt.Errorf("invalid %s address %s, missing host", name, addr)
+	}
+
+	if p, err := strconv.Atoi(port); err != nil || p < 0 || p > 65535 {
+		return fmt.Errorf("invalid %s address %s, bad port %s", name, addr, port)
+	}
+
+	return nil
+}
+
+func checkTopic(name, topic string) error {
+	if strings.ContainsAny(topic, "+#") {
+		return fmt.Errorf("%s topic %s can't contain wildcards", name, topic)
+	}
+
+	return nil
+}
+
+func checkAPIKeyFormat(key string) []string {
+	problems := []string{}
+
+	for n, k := range parseAPIKeys(key) {
+		if !apiKeyFormat.MatchString(k) {
+			problems = append(problems, fmt.Sprintf("api key %d doesn't look like a steam api key, which is 32 hex characters", n+1))
+		}
+	}
+
+	return problems
+}
+
+func validateOptions(opts Options) []string {
+	cp := configProblems{}
+
+	cp.check(checkHostPort("broker", opts.Broker, true))
+
+	if key, err := resolveAPIKey(opts); err != nil {
+		cp.check(err)
+	} else {
+		cp = append(cp, checkAPIKeyFormat(key)...)
+	}
+
+	cp.check(checkStoreDriver(opts.KVDriver))
+
+	if dir := filepath.Dir(opts.KVPath); (opts.KVDriver == "bolt" || opts.KVDriver == "sqlite") && dir != "" {
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			cp.add("kv path directory %s doesn't exist", dir)
+		}
+	}
+
+	if opts.MessagesFile != "" {
+		if _, err := os.Stat(opts.MessagesFile); err != nil {
+			cp.add("messages file %s can't be read", opts.MessagesFile)
+		}
+	} else {
+		_, err := parseCatalog(opts.Messages)
+		cp.check(err)
+		_, err = parseDestCatalogs(opts.DestMessages)
+		cp.check(err)
+	}
+
+	for name, u := range map[string]string{"api": opts.APIURL, "store": opts.StoreURL, "community": opts.CommunityURL, "steamspy": opts.SteamSpyURL, "itad": opts.ITADURL} {
+		_, err := parseBaseUrl(name, u)
+		cp.check(err)
+	}
+
+	_, err := time.LoadLocation(opts.Timezone)
+	cp.check(err)
+	_, err = parseDateFormat(opts.DateFormat)
+	cp.check(err)
+	_, err = parseLanguage(opts.Language)
+	cp.check(err)
+	_, err = parseFormatter(opts.Format)
+	cp.check(err)
+	_, err = parseDestFormatters(opts.DestFormats)
+	cp.check(err)
+	_, err = parseDisabledCommands(opts.DisabledCommands)
+	cp.check(err)
+
+	for _, f := range opts.MockSteamFaults {
+		_, _, err := mocksteam.ParseFault(f)
+		cp.check(err)
+	}
+
+	if opts.ErrorDSN != "" {
+		_, err := parseSentryDSN(opts.ErrorDSN)
+		cp.check(err)
+	}
+
+	if _, err := parseWatchedGames(opts.WatchGames); err != nil {
+		cp.check(err)
+	} else if len(opts.WatchGames) > 0 && opts.PlayerSampleInterval <= 0 {
+		cp.add("player sample interval must be positive when games are watched")
+	}
+
+	if len(opts.ITADCountry) != 2 {
+		cp.add("itad country %s must be a two letter country code", opts.ITADCountry)
+	}
+
+	if opts.Shortener != "" && !strings.Contains(opts.Shortener, "%s") {
+		cp.add("shortener %s must contain %%s", opts.Shortener)
+	}
+
+	if strings.ContainsAny(opts.ShareGroup, "/+#") {
+		cp.add("share group %s can't contain /, + or #", opts.ShareGroup)
+	}
+
+	if len(customClientID(opts.ClientID, opts.ClientIDSuffix, opts.ShareGroup, "host")) > maxClientIDLength {
+		cp.add("mqtt client id is longer than %d characters, which some brokers reject", maxClientIDLength)
+	}
+
+	cp.check(checkTopic("status", opts.StatusTopic))
+	cp.check(checkTopic("admin", opts.AdminTopic))
+	cp.check(checkTopic("error", opts.ErrorTopic))
+
+	if opts.HTTPListen != "" {
+		cp.check(checkHostPort("http listen", opts.HTTPListen, false))
+	} else if opts.WebhookToken != "" {
+		cp.add("webhook token is set but http listen isn't, so the webhook won't be served")
+	}
+
+	if opts.GRPCListen != "" {
+		cp.check(checkHostPort("grpc listen", opts.GRPCListen, false))
+
+		if opts.AdminToken == "" {
+			cp.add("grpc listen needs an admin token to authenticate user changes")
+		}
+	}
+
+	if opts.AlertDest != "" && (opts.AlertThreshold <= 0 || opts.AlertWindow <= 0) {
+		cp.add("alert threshold and window must be greater than 0 when alert dest is set")
+	}
+
+	if opts.DebugAddr != "" {
+		cp.check(checkHostPort("debug", opts.DebugAddr, false))
+	}
+
+	if opts.ClaimDir != "" && opts.ClaimWindow <= 0 {
+		cp.add("claim window must be greater than 0 when claim dir is set")
+	}
+
+	if opts.Timeout <= 0 {
+		cp.add("timeout must be greater than 0")
+	}
+
+	for name, d := range map[string]time.Duration{
+		"user cooldown":     opts.UserCooldown,
+		"drain timeout":     opts.DrainTimeout,
+		"retry delay":       opts.RetryDelay,
+		"cache ttl":         opts.CacheTTL,
+		"cache stale for":   opts.CacheStaleFor,
+		"app index refresh": opts.AppIndexRefresh,
+	} {
+		if d < 0 {
+			cp.add("%s can't be negative", name)
+		}
+	}
+
+	for name, n := range map[string]int{
+		"channel limit": opts.ChannelLimit,
+		"cache size":    opts.CacheSize,
+		"daily budget":  opts.DailyBudget,
+		"max list":      opts.MaxList,
+	} {
+		if n < 0 {
+			cp.add("%s can't be negative", name)
+		}
+	}
+
+	sort.Strings(cp)
+	return cp
+}
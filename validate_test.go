@@ -0,0 +1,152 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validTestOptions(t *testing.T) Options {
+	return Options{
+		Broker:       "localhost:1883",
+		APIKey:       "0123456789ABCDEF0123456789abcdef",
+		KVDriver:     "bolt",
+		KVPath:       filepath.Join(t.TempDir(), "kv.db"),
+		Messages:     "en",
+		APIURL:       "https://api.steampowered.com",
+		StoreURL:     "https://store.steampowered.com",
+		CommunityURL: "https://steamcommunity.com",
+		SteamSpyURL:  "https://steamspy.com",
+		ITADURL:      "https://api.isthereanydeal.com",
+		ITADCountry:  "US",
+		Timezone:     "UTC",
+		DateFormat:   "iso",
+		Language:     "en",
+		Format:       "irc",
+		StatusTopic:  "/gowon/status/steam",
+		Timeout:      10 * time.Second,
+	}
+}
+
+func TestValidateOptionsValid(t *testing.T) {
+	assert.Empty(t, validateOptions(validTestOptions(t)))
+}
+
+func TestValidateOptions(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(o *Options)
+		expected []string
+	}{
+		{
+			name:     "Bad broker",
+			modify:   func(o *Options) { o.Broker = "localhost" },
+			expected: []string{"invalid broker address localhost, must be host:port"},
+		},
+		{
+			name:     "Missing api key",
+			modify:   func(o *Options) { o.APIKey = "" },
+			expected: []string{"an api key is required, set api-key or api-key-file"},
+		},
+		{
+			name:     "Malformed api key",
+			modify:   func(o *Options) { o.APIKey = "0123456789ABCDEF0123456789abcdef,nope" },
+			expected: []string{"api key 2 doesn't look like a steam api key, which is 32 hex characters"},
+		},
+		{
+			name:     "Missing kv directory",
+			modify:   func(o *Options) { o.KVPath = "/does/not/exist/kv.db" },
+			expected: []string{"kv path directory /does/not/exist doesn't exist"},
+		},
+		{
+			name:     "Unknown kv driver",
+			modify:   func(o *Options) { o.KVDriver = "mongo" },
+			expected: []string{"unknown kv driver mongo, must be one of bolt, memory, redis, sqlite"},
+		},
+		{
+			name:     "Bad watched game",
+			modify:   func(o *Options) { o.WatchGames = []string{"620", "portal"} },
+			expected: []string{"watched game portal must be a steam app id"},
+		},
+		{
+			name: "Watched games without an interval",
+			modify: func(o *Options) {
+				o.WatchGames = []string{"620"}
+				o.PlayerSampleInterval = 0
+			},
+			expected: []string{"player sample interval must be positive when games are watched"},
+		},
+		{
+			name:     "Bad itad country",
+			modify:   func(o *Options) { o.ITADCountry = "USA" },
+			expected: []string{"itad country USA must be a two letter country code"},
+		},
+		{
+			name:     "Bad mock steam fault",
+			modify:   func(o *Options) { o.MockSteamFaults = []string{"recent=slow"} },
+			expected: []string{`unknown fault "slow"`},
+		},
+		{
+			name: "Kv path ignored for other drivers",
+			modify: func(o *Options) {
+				o.KVDriver = "memory"
+				o.KVPath = "/does/not/exist/kv.db"
+			},
+			expected: []string{},
+		},
+		{
+			name: "Several problems",
+			modify: func(o *Options) {
+				o.Timezone = "Nowhere/Land"
+				o.StatusTopic = "/gowon/#"
+				o.Timeout = 0
+				o.WebhookToken = "secret"
+				o.ChannelLimit = -1
+			},
+			expected: []string{
+				"channel limit can't be negative",
+				"status topic /gowon/# can't contain wildcards",
+				"timeout must be greater than 0",
+				"unknown time zone Nowhere/Land",
+				"webhook token is set but http listen isn't, so the webhook won't be served",
+			},
+		},
+		{
+			name:     "Bad http listen",
+			modify:   func(o *Options) { o.HTTPListen = ":http-port" },
+			expected: []string{"invalid http listen address :http-port, bad port http-port"},
+		},
+		{
+			name:     "Grpc without admin token",
+			modify:   func(o *Options) { o.GRPCListen = ":9090" },
+			expected: []string{"grpc listen needs an admin token to authenticate user changes"},
+		},
+		{
+			name:     "Long client id",
+			modify:   func(o *Options) { o.ClientIDSuffix = strings.Repeat("x", 64) },
+			expected: []string{"mqtt client id is longer than 64 characters, which some brokers reject"},
+		},
+		{
+			name:     "Bad error dsn",
+			modify:   func(o *Options) { o.ErrorDSN = "https://sentry.example/1" },
+			expected: []string{"invalid error reporting dsn https://sentry.example/1, must look like https://key@host/project"},
+		},
+		{
+			name:     "Bad shortener",
+			modify:   func(o *Options) { o.Shortener = "https://short.example" },
+			expected: []string{"shortener https://short.example must contain %s"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := validTestOptions(t)
+			tc.modify(&o)
+
+			assert.Equal(t, tc.expected, validateOptions(o))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+var (
+	version   = ""
+	commit    = ""
+	buildDate = ""
+)
+
+func moduleVersion() string {
+	v := version
+
+	if v == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "(devel)" {
+			v = bi.Main.Version
+		}
+	}
+
+	if v == "" {
+		return "dev"
+	}
+
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+
+	return v
+}
+
+func userAgent() string {
+	return fmt.Sprintf("gowon-%s/%s", moduleName, moduleVersion())
+}
+
+func shortCommit() string {
+	if commit == "" {
+		return "unknown"
+	}
+
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+
+	return commit
+}
+
+func buildInfo() string {
+	date := buildDate
+	if date == "" {
+		date = "unknown"
+	}
+
+	return fmt.Sprintf("%s %s (commit %s, built %s, %s)", moduleName, moduleVersion(), shortCommit(), date, runtime.Version())
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleVersion(t *testing.T) {
+	cases := []struct {
+		name    string
+		version string
+		out     string
+	}{
+		{
+			name:    "Unset",
+			version: "",
+			out:     "dev",
+		},
+		{
+			name:    "Without prefix",
+			version: "1.2.3",
+			out:     "v1.2.3",
+		},
+		{
+			name:    "With prefix",
+			version: "v1.2.3",
+			out:     "v1.2.3",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			old := version
+			defer func() { version = old }()
+			version = tc.version
+
+			assert.Equal(t, tc.out, moduleVersion())
+		})
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	cases := []struct {
+		name      string
+		commit    string
+		buildDate string
+		out       string
+	}{
+		{
+			name: "Unset",
+			out:  fmt.Sprintf("steam v1.2.3 (commit unknown, built unknown, %s)", runtime.Version()),
+		},
+		{
+			name:      "Set",
+			commit:    "0123456789abcdef",
+			buildDate: "2022-07-01T12:00:00Z",
+			out:       fmt.Sprintf("steam v1.2.3 (commit 0123456, built 2022-07-01T12:00:00Z, %s)", runtime.Version()),
+		},
+		{
+			name:   "Short commit",
+			commit: "abc",
+			out:    fmt.Sprintf("steam v1.2.3 (commit abc, built unknown, %s)", runtime.Version()),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldVersion, oldCommit, oldBuildDate := version, commit, buildDate
+			defer func() { version, commit, buildDate = oldVersion, oldCommit, oldBuildDate }()
+			version, commit, buildDate = "1.2.3", tc.commit, tc.buildDate
+
+			assert.Equal(t, tc.out, buildInfo())
+		})
+	}
+}
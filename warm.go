@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+func linkedUsers(kv Store) (users []string, err error) {
+	seen := make(map[string]bool)
+
+	err = kv.View(usersBucket, func(b Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			u := strings.ToLower(string(v))
+			if u != "" && !seen[u] {
+				seen[u] = true
+				users = append(users, string(v))
+			}
+			return nil
+		})
+	})
+
+	return users, err
+}
+
+func warmCache(ctx context.Context, kv Store, apiKey string, client *steamClient) (int, error) {
+	users, err := linkedUsers(kv)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []string{}
+	for _, u := range users {
+		id, err := resolveSteamID(ctx, apiKey, u, client)
+		if ctx.Err() != nil {
+			return len(ids), ctx.Err()
+		}
+
+		if err == nil {
+			ids = append(ids, id.String())
+		}
+	}
+
+	for i := 0; i < len(ids); i += maxSummaryIds {
+		end := i + maxSummaryIds
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		res, err := getPlayerSummaries(ctx, apiKey, strings.Join(ids[i:end], ","), client)
+		if err != nil {
+			return len(ids), err
+		}
+
+		for _, ps := range res.Response.Players {
+			client.cache.SetWithTTL(summaryCacheKey(ps.SteamId), ps, summaryCacheTTL)
+		}
+	}
+
+	return len(ids), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDB(t *testing.T) Store {
+	kv, err := openStore("bolt", filepath.Join(t.TempDir(), "test.db"))
+	assert.Nil(t, err)
+	t.Cleanup(func() { kv.Close() })
+
+	return kv
+}
+
+func TestLinkedUsers(t *testing.T) {
+	kv := openTestDB(t)
+
+	assert.Nil(t, setUser(kv, []byte("nick1"), []byte("bob")))
+	assert.Nil(t, setUser(kv, []byte("nick2"), []byte("Bob")))
+	assert.Nil(t, setUser(kv, []byte("nick3"), []byte("alice")))
+
+	users, err := linkedUsers(kv)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bob", "alice"}, users)
+}
+
+func TestWarmCache(t *testing.T) {
+	kv := openTestDB(t)
+
+	assert.Nil(t, setUser(kv, []byte("nick1"), []byte("alice")))
+	assert.Nil(t, setUser(kv, []byte("nick2"), []byte("bob")))
+	assert.Nil(t, setUser(kv, []byte("nick3"), []byte("carol")))
+
+	client := NewConditionalTestClient(map[string]string{
+		testClient.apiUrl(resolveVanityPath, "key", "alice"): `{"response":{"steamid":"1","success":1}}`,
+		testClient.apiUrl(resolveVanityPath, "key", "bob"):   `{"response":{"steamid":"2","success":1}}`,
+		testClient.apiUrl(resolveVanityPath, "key", "carol"): `{"response":{"success":42}}`,
+		testClient.apiUrl(playerSummariesPath, "key", "1,2"): `{"response":{"players":[{"steamid":"1","personaname":"Alice"},{"steamid":"2","personaname":"Bob"}]}}`,
+	})
+	client.cache = newLRUCache(10, time.Hour)
+
+	n, err := warmCache(context.Background(), kv, "key", client)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+
+	id, ok := client.cache.Get("vanity:bob")
+	assert.True(t, ok)
+	assert.Equal(t, SteamID(2), id)
+
+	ps, ok := client.cache.Get(summaryCacheKey("1"))
+	assert.True(t, ok)
+	assert.Equal(t, "Alice", ps.(playerSummary).PersonaName)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gowon-irc/go-gowon"
+)
+
+const (
+	webhookPath         = "/v1/webhook"
+	maxWebhookBodyBytes = 64 << 10
+)
+
+type webhookRequest struct {
+	Dest    string   `json:"dest"`
+	Message string   `json:"message"`
+	Lines   []string `json:"lines"`
+}
+
+func (wr webhookRequest) lines() []string {
+	lines := []string{}
+	if wr.Message != "" {
+		lines = append(lines, wr.Message)
+	}
+
+	for _, l := range wr.Lines {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return lines
+}
+
+func webhookAuthorised(r *http.Request, token string) bool {
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func newWebhookHandler(token string, defaults settings, publish func(payload []byte)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeAPIResponse(w, http.StatusMethodNotAllowed, apiResponse{Error: "method not allowed"})
+			return
+		}
+
+		if !webhookAuthorised(r, token) {
+			writeAPIResponse(w, http.StatusUnauthorized, apiResponse{Error: "unauthorised"})
+			return
+		}
+
+		req := webhookRequest{}
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxWebhookBodyBytes)).Decode(&req); err != nil {
+			writeAPIResponse(w, http.StatusBadRequest, apiResponse{Error: "invalid json"})
+			return
+		}
+
+		lines := req.lines()
+		if req.Dest == "" || len(lines) == 0 {
+			writeAPIResponse(w, http.StatusBadRequest, apiResponse{Error: "dest and message or lines are required"})
+			return
+		}
+
+		s := defaults
+		if f, ok := s.formatters[req.Dest]; ok {
+			s.formatter = f
+		}
+
+		b, err := json.Marshal(gowon.Message{Module: moduleName, Msg: s.formatter.Render(s.formatter.Lines(lines)), Dest: req.Dest})
+		if err != nil {
+			writeAPIResponse(w, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+			return
+		}
+
+		publish(b)
+		writeAPIResponse(w, http.StatusAccepted, apiResponse{Reply: "queued"})
+	})
+}
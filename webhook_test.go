@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gowon-irc/go-gowon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		auth   string
+		body   string
+		status int
+		msg    string
+		dest   string
+	}{
+		{
+			name:   "Message",
+			method: http.MethodPost,
+			auth:   "Bearer secret",
+			body:   `{"dest":"#chan","message":"server restarted"}`,
+			status: http.StatusAccepted,
+			msg:    "server restarted",
+			dest:   "#chan",
+		},
+		{
+			name:   "Lines",
+			method: http.MethodPost,
+			auth:   "Bearer secret",
+			body:   `{"dest":"#chan","message":"server","lines":["up","","ok"]}`,
+			status: http.StatusAccepted,
+			msg:    "server | up | ok",
+			dest:   "#chan",
+		},
+		{
+			name:   "Discord destination",
+			method: http.MethodPost,
+			auth:   "Bearer secret",
+			body:   `{"dest":"#bridged","lines":["a","b"]}`,
+			status: http.StatusAccepted,
+			msg:    "a\nb",
+			dest:   "#bridged",
+		},
+		{
+			name:   "Wrong token",
+			method: http.MethodPost,
+			auth:   "Bearer nope",
+			body:   `{"dest":"#chan","message":"hi"}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "No token",
+			method: http.MethodPost,
+			body:   `{"dest":"#chan","message":"hi"}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Invalid json",
+			method: http.MethodPost,
+			auth:   "Bearer secret",
+			body:   `{`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Missing dest",
+			method: http.MethodPost,
+			auth:   "Bearer secret",
+			body:   `{"message":"hi"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Missing message",
+			method: http.MethodPost,
+			auth:   "Bearer secret",
+			body:   `{"dest":"#chan"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Wrong method",
+			method: http.MethodGet,
+			auth:   "Bearer secret",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+
+	s := testSettings
+	s.formatters = map[string]formatter{"#bridged": discordFormatter{}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var published []byte
+			h := newWebhookHandler("secret", s, func(payload []byte) {
+				published = payload
+			})
+
+			req := httptest.NewRequest(tc.method, webhookPath, strings.NewReader(tc.body))
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			if tc.msg == "" {
+				assert.Nil(t, published)
+				return
+			}
+
+			m := gowon.Message{}
+			assert.Nil(t, json.Unmarshal(published, &m))
+			assert.Equal(t, "steam", m.Module)
+			assert.Equal(t, tc.msg, m.Msg)
+			assert.Equal(t, tc.dest, m.Dest)
+		})
+	}
+}
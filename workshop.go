@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	workshopDetailsPath = "/IPublishedFileService/GetDetails/v1/?key=%s&publishedfileids[0]=%s"
+	workshopLinkRegex   = `steamcommunity\.com/(?:sharedfiles|workshop)/filedetails/?\?(?:[^\s]*&)?id=(\d+)`
+	workshopCacheTTL    = time.Hour
+)
+
+var workshopLinkRe = regexp.MustCompile(workshopLinkRegex)
+
+type workshopDetailsRes struct {
+	Response struct {
+		PublishedFileDetails []workshopItem `json:"publishedfiledetails"`
+	}
+}
+
+type workshopItem struct {
+	Result        int
+	Title         string
+	ConsumerAppId int `json:"consumer_appid"`
+	Subscriptions int
+}
+
+func getWorkshopItem(ctx context.Context, apiKey, id string, client *steamClient) (workshopItem, bool, error) {
+	j, err := getJSON[workshopDetailsRes](ctx, client.apiUrl(workshopDetailsPath, apiKey, id), "", client)
+	if err != nil {
+		return workshopItem{}, false, err
+	}
+
+	if len(j.Response.PublishedFileDetails) == 0 || j.Response.PublishedFileDetails[0].Result != 1 {
+		return workshopItem{}, false, nil
+	}
+
+	return j.Response.PublishedFileDetails[0], true, nil
+}
+
+func cachedWorkshopItem(ctx context.Context, apiKey, id string, client *steamClient) (workshopItem, bool, error) {
+	w, err := client.cache.GetOrFetch(ctx, fmt.Sprintf("workshop:%s", id), workshopCacheTTL, func(ctx context.Context) (interface{}, error) {
+		item, ok, err := getWorkshopItem(ctx, apiKey, id, client)
+		if err != nil || !ok {
+			return (*workshopItem)(nil), err
+		}
+
+		return &item, nil
+	})
+	if err != nil {
+		return workshopItem{}, false, err
+	}
+
+	item := w.(*workshopItem)
+	if item == nil {
+		return workshopItem{}, false, nil
+	}
+
+	return *item, true, nil
+}
+
+func workshopLinkIds(msg string) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+
+	for _, m := range workshopLinkRe.FindAllStringSubmatch(msg, -1) {
+		if seen[m[1]] {
+			continue
+		}
+
+		seen[m[1]] = true
+		ids = append(ids, m[1])
+
+		if len(ids) == maxLinkPreviews {
+			break
+		}
+	}
+
+	return ids
+}
+
+func appName(ctx context.Context, appId int, client *steamClient) string {
+	if name, ok := apps.Name(appId); ok {
+		return name
+	}
+
+	if app, ok, err := cachedStoreApp(ctx, appId, client); err == nil && ok {
+		return app.Name
+	}
+
+	return fmt.Sprintf("app %d", appId)
+}
+
+func workshopLinkPreviews(ctx context.Context, apiKey, msg string, client *steamClient, s settings) ([]string, error) {
+	lines := []string{}
+
+	for _, id := range workshopLinkIds(msg) {
+		item, ok, err := cachedWorkshopItem(ctx, apiKey, id, client)
+		if err != nil {
+			return lines, err
+		}
+
+		if ok {
+			game := appName(ctx, item.ConsumerAppId, client)
+			lines = append(lines, s.msg("workshop_item", s.formatter.Colour("green", item.Title), game, item.Subscriptions))
+		}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkshopLinkIds(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  string
+		ids  []string
+	}{
+		{
+			name: "No links",
+			msg:  "hello",
+			ids:  []string{},
+		},
+		{
+			name: "Sharedfiles link",
+			msg:  "https://steamcommunity.com/sharedfiles/filedetails/?id=123456",
+			ids:  []string{"123456"},
+		},
+		{
+			name: "Workshop link with other params",
+			msg:  "https://steamcommunity.com/workshop/filedetails/?searchtext=&id=42",
+			ids:  []string{"42"},
+		},
+		{
+			name: "Duplicate links",
+			msg:  "steamcommunity.com/sharedfiles/filedetails/?id=1 steamcommunity.com/sharedfiles/filedetails?id=1",
+			ids:  []string{"1"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.ids, workshopLinkIds(tc.msg))
+		})
+	}
+}
+
+func TestWorkshopLinkPreviews(t *testing.T) {
+	cases := []struct {
+		name     string
+		details  string
+		store    string
+		expected []string
+	}{
+		{
+			name:     "Known game",
+			details:  `{"response":{"publishedfiledetails":[{"publishedfileid":"42","result":1,"title":"Cool Map","consumer_appid":620,"subscriptions":1500}]}}`,
+			store:    testAppDetails,
+			expected: []string{"{green}Cool Map{clear} - Portal 2 workshop item - 1500 subscribers"},
+		},
+		{
+			name:     "Unknown game",
+			details:  `{"response":{"publishedfiledetails":[{"publishedfileid":"42","result":1,"title":"Cool Map","consumer_appid":620,"subscriptions":1500}]}}`,
+			store:    `{"620":{"success":false}}`,
+			expected: []string{"{green}Cool Map{clear} - app 620 workshop item - 1500 subscribers"},
+		},
+		{
+			name:     "Missing item",
+			details:  `{"response":{"publishedfiledetails":[{"publishedfileid":"42","result":9}]}}`,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewConditionalTestClient(map[string]string{
+				testClient.apiUrl(workshopDetailsPath, "key", "42"): tc.details,
+				testClient.storeUrl(appDetailsPath, 620):            tc.store,
+			})
+
+			out, err := workshopLinkPreviews(context.Background(), "key", "https://steamcommunity.com/sharedfiles/filedetails/?id=42", client, testSettings)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}